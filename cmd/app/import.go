@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"iot-golang/internal/importer/services"
+	"iot-golang/internal/sensors"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// runImport menjalankan subcommand import, contoh:
+//
+//	app import -sensor thigrow -file riwayat.csv -map "soil_th=kelembaban_tanah_th"
+func runImport(db *gorm.DB, args []string) int {
+	command := flag.NewFlagSet("import", flag.ContinueOnError)
+	sensor := command.String("sensor", "", "jenis sensor: "+strings.Join(sensors.Names(), ", "))
+	path := command.String("file", "", "path file CSV")
+	mapping := command.String("map", "", "mapping kolom CSV ke field, contoh: kolom_csv=field,kolom_lain=field")
+	if err := command.Parse(args); err != nil {
+		return 2
+	}
+
+	if *sensor == "" || *path == "" {
+		command.Usage()
+		return 2
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Gagal membuka file %s, error: %v\n", *path, err)
+		return 1
+	}
+	defer file.Close()
+
+	result, err := services.NewImportService(db, slog.Default()).Import(context.Background(), *sensor, file, services.ParseMapping(*mapping))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+
+	return 0
+}
@@ -1,82 +1,327 @@
 package main
 
 import (
+	"context"
 	"iot-golang/config"
+	anomalyController "iot-golang/internal/anomaly/controllers"
+	anomalyServices "iot-golang/internal/anomaly/services"
 	beitianController "iot-golang/internal/beitian/controllers"
 	bmpController "iot-golang/internal/bmp/controllers"
+	"iot-golang/internal/broadcast"
+	calibrationController "iot-golang/internal/calibration/controllers"
+	"iot-golang/internal/coap"
+	completenessController "iot-golang/internal/completeness/controllers"
+	completenessServices "iot-golang/internal/completeness/services"
+	deviceController "iot-golang/internal/device/controllers"
+	geofenceController "iot-golang/internal/geofence/controllers"
+	"iot-golang/internal/graph"
+	graphController "iot-golang/internal/graph/controllers"
+	healthController "iot-golang/internal/health/controllers"
+	"iot-golang/internal/helpers"
+	importController "iot-golang/internal/importer/controllers"
 	inaController "iot-golang/internal/ina/controllers"
+	"iot-golang/internal/lifecycle"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"iot-golang/internal/middleware"
+	"iot-golang/internal/migrations"
+	"iot-golang/internal/openapi"
+	openapiController "iot-golang/internal/openapi/controllers"
 	pzemController "iot-golang/internal/pzem/controllers"
+	readingsController "iot-golang/internal/readings/controllers"
+	retentionController "iot-golang/internal/retention/controllers"
+	retentionServices "iot-golang/internal/retention/services"
+	"iot-golang/internal/rpc"
 	thigrowController "iot-golang/internal/thigrow/controllers"
 	thmController "iot-golang/internal/thm/controllers"
+	"iot-golang/internal/tracing"
+	"log/slog"
 	"os"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
-func init() {
+const serviceName = "iot-golang"
+
+// configure membaca .env lalu mengisi parameter package dari environment.
+// Dipanggil dari main, bukan init, agar test package ini tidak membutuhkan .env.
+func configure() {
 	config.LoadEnv()
+	logging.SlowQueryThreshold = config.GetDuration("LOG_SLOW_QUERY", logging.SlowQueryThreshold)
+	helpers.RejectBadReadings = os.Getenv("QUALITY_BAD_READINGS") != "store"
+	anomalyServices.Threshold = config.GetFloat("ANOMALY_THRESHOLD", anomalyServices.Threshold)
+	anomalyServices.WindowSize = config.GetInt("ANOMALY_WINDOW", anomalyServices.WindowSize)
+	anomalyServices.MinSamples = config.GetInt("ANOMALY_MIN_SAMPLES", anomalyServices.MinSamples)
+	completenessServices.DefaultInterval = config.GetDuration("COMPLETENESS_DEFAULT_INTERVAL", completenessServices.DefaultInterval)
+	completenessServices.GapTolerance = config.GetFloat("COMPLETENESS_GAP_TOLERANCE", completenessServices.GapTolerance)
+	graph.MaxLimit = int32(config.GetInt("GRAPHQL_MAX_LIMIT", int(graph.MaxLimit)))
+	broadcast.BufferSize = config.GetInt("GRPC_SUBSCRIBE_BUFFER", broadcast.BufferSize)
+	slog.SetDefault(logging.FromEnv())
 }
 
 func main() {
+	configure()
+	logger := slog.Default()
 	db := config.InitDB()
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			os.Exit(runImport(db, os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrate(db, os.Args[2:]))
+		case "openapi":
+			os.Exit(runOpenAPI(db, os.Args[2:]))
+		}
+	}
+
+	if os.Getenv("DB_AUTO_MIGRATE") == "true" {
+		if _, err := migrations.NewMigrator(db, logger).Up(); err != nil {
+			logger.Error("Gagal menjalankan migration", logging.Error(err))
+			os.Exit(1)
+		}
+		if err := migrations.CreateHypertables(db, logger); err != nil {
+			logger.Error("Gagal membuat hypertable TimescaleDB", logging.Error(err))
+			os.Exit(1)
+		}
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), serviceName, config.Version)
+	if err != nil {
+		logger.Error("Gagal memasang tracing", logging.Error(err))
+		os.Exit(1)
+	}
+	if err := db.Use(tracing.NewPlugin()); err != nil {
+		logger.Error("Gagal memasang tracing database", logging.Error(err))
+		os.Exit(1)
+	}
+
+	if err := metrics.RegisterDB(db, os.Getenv("DB_NAME")); err != nil {
+		logger.Error("Gagal memasang metrics database", logging.Error(err))
+		os.Exit(1)
+	}
+
+	manager := lifecycle.NewManager(config.GetDuration("SHUTDOWN_TIMEOUT", 15*time.Second), logger)
+
+	retentionJob := retentionServices.NewJob(db, logger)
+	manager.AddWorker(retentionJob)
+
+	// server gRPC berjalan bersama server HTTP bila GRPC_PORT diisi
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		manager.AddWorker(rpc.NewServer(db, ":"+port, logger))
+	}
+
+	// server CoAP di atas UDP untuk perangkat dengan sumber daya terbatas
+	if port := os.Getenv("COAP_PORT"); port != "" {
+		manager.AddWorker(coap.NewServer(db, ":"+port, logger))
+	}
+
 	route := echo.New()
+	route.HideBanner = true
+	route.HTTPErrorHandler = middleware.ErrorHandler(logger, "/api/v2/")
+	route.HidePort = true
+	registerRoutes(route, db, logger, manager, retentionJob)
+
+	// route baru yang belum ditulis di spesifikasi OpenAPI dilaporkan saat start
+	if divergence := openapi.Check(route.Routes()); !divergence.Empty() {
+		logger.Warn("Route tidak sesuai dengan spesifikasi OpenAPI", "detail", divergence.Error())
+		if os.Getenv("OPENAPI_STRICT") == "true" {
+			os.Exit(1)
+		}
+	}
+
+	// tulisan yang masih berjalan sudah selesai setelah request dan worker
+	// berhenti, database di-flush lalu pool ditutup
+	manager.OnClose("flush", func(ctx context.Context) error {
+		return config.FlushDB(ctx, db)
+	})
+	manager.OnClose("database", func(ctx context.Context) error {
+		return config.CloseDB(db)
+	})
+	manager.OnShutdown("tracing", shutdownTracing)
+
+	if err := manager.Run(route, ":"+os.Getenv("PORT")); err != nil {
+		logger.Error("Server berhenti dengan error", logging.Error(err))
+		os.Exit(1)
+	}
+}
+
+func registerRoutes(route *echo.Echo, db *gorm.DB, logger *slog.Logger, manager *lifecycle.Manager, retentionJob *retentionServices.Job) {
+	// request id, response language, tracing and access log for every route
+	route.Use(middleware.RequestID())
+	route.Use(middleware.Language())
+	route.Use(middleware.Tracing(serviceName)...)
+	route.Use(middleware.AccessLog(logger.With("component", "http")))
+
+	// metrics for every route, exposed to Prometheus on /metrics
+	route.Use(middleware.Metrics())
+	route.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	// route for orchestrator probes and diagnostics
+	healthController := healthController.NewHealthController(db, manager, logger)
+	healthTimeout := middleware.RouteTimeout(config.GetDuration("HEALTH_TIMEOUT", 5*time.Second), nil)
+	route.GET("/healthz", healthController.Healthz)
+	route.GET("/readyz", healthController.Readyz, healthTimeout)
+	route.GET("/debug/info", healthController.Info, healthTimeout)
+
+	// route for OpenAPI specification and Swagger UI
+	openapiController := openapiController.NewOpenAPIController(logger)
+	route.GET("/openapi.json", openapiController.Spec)
+	route.GET("/docs", openapiController.Docs)
+
 	apiIoTSf := route.Group("api/iot-sf/")
 
+	// batas waktu query per route, route ekspor dan import memakai batas yang lebih panjang
+	longQueryTimeout := config.GetDuration("LONG_QUERY_TIMEOUT", 10*time.Minute)
+	apiIoTSf.Use(middleware.RouteTimeout(config.GetDuration("QUERY_TIMEOUT", 10*time.Second), map[string]time.Duration{
+		"/api/iot-sf/beitian/export":        longQueryTimeout,
+		"/api/iot-sf/bmp/export":            longQueryTimeout,
+		"/api/iot-sf/ina/export":            longQueryTimeout,
+		"/api/iot-sf/pzem/export":           longQueryTimeout,
+		"/api/iot-sf/thigrow/export":        longQueryTimeout,
+		"/api/iot-sf/thm/export":            longQueryTimeout,
+		"/api/iot-sf/import/:sensor":        longQueryTimeout,
+		"/api/iot-sf/calibration/recompute": longQueryTimeout,
+		"/api/iot-sf/history":               config.GetDuration("HISTORY_QUERY_TIMEOUT", time.Minute),
+		"/api/iot-sf/map/devices":           config.GetDuration("HISTORY_QUERY_TIMEOUT", time.Minute),
+		"/api/iot-sf/beitian/track":         config.GetDuration("HISTORY_QUERY_TIMEOUT", time.Minute),
+		"/api/iot-sf/completeness":          config.GetDuration("HISTORY_QUERY_TIMEOUT", time.Minute),
+		"/api/iot-sf/graphql":               config.GetDuration("HISTORY_QUERY_TIMEOUT", time.Minute),
+	}))
+
 	// route for sensor beitian220
-	beitianController := beitianController.NewBeitianController(db)
+	beitianController := beitianController.NewBeitianController(db, logger)
 	apiIoTSf.POST("beitian/create", beitianController.Create)
 	apiIoTSf.GET("beitian/get_all", beitianController.GetAll)
 	apiIoTSf.GET("beitian/detail", beitianController.GetById)
 	apiIoTSf.GET("beitian/detail/token", beitianController.GetByToken)
+	apiIoTSf.GET("beitian/export", beitianController.Export)
 	apiIoTSf.GET("beitian/detail/new/token", beitianController.GetNewByToken)
+	apiIoTSf.GET("beitian/track", beitianController.GetTrack)
 	apiIoTSf.PUT("beitian/update/:id", beitianController.Update)
 	apiIoTSf.DELETE("beitian/delete/:id", beitianController.Delete)
 
+	// route for geofence beitian220
+	geofenceController := geofenceController.NewGeofenceController(db, logger)
+	apiIoTSf.POST("geofence/create", geofenceController.Create)
+	apiIoTSf.GET("geofence/get_all", geofenceController.GetAll)
+	apiIoTSf.GET("geofence/detail", geofenceController.GetById)
+	apiIoTSf.GET("geofence/events", geofenceController.GetEventsByToken)
+	apiIoTSf.PUT("geofence/update/:id", geofenceController.Update)
+	apiIoTSf.DELETE("geofence/delete/:id", geofenceController.Delete)
+
+	// route for device registry and map layer
+	deviceController := deviceController.NewDeviceController(db, logger)
+	apiIoTSf.POST("device/create", deviceController.Create)
+	apiIoTSf.GET("device/get_all", deviceController.GetAll)
+	apiIoTSf.GET("device/detail", deviceController.GetById)
+	apiIoTSf.PUT("device/update/:id", deviceController.Update)
+	apiIoTSf.DELETE("device/delete/:id", deviceController.Delete)
+	apiIoTSf.GET("map/devices", deviceController.GetMap)
+
 	// route for sensor bmp180
-	bmpController := bmpController.NewBmpController(db)
+	bmpController := bmpController.NewBmpController(db, logger)
 	apiIoTSf.POST("bmp/create", bmpController.Create)
 	apiIoTSf.GET("bmp/get_all", bmpController.GetAll)
 	apiIoTSf.GET("bmp/detail", bmpController.GetById)
 	apiIoTSf.GET("bmp/detail/token", bmpController.GetByToken)
+	apiIoTSf.GET("bmp/export", bmpController.Export)
 	apiIoTSf.PUT("bmp/update/:id", bmpController.Update)
 	apiIoTSf.DELETE("bmp/delete/:id", bmpController.Delete)
 
 	// route for sensor ina219
-	inaController := inaController.NewInaController(db)
+	inaController := inaController.NewInaController(db, logger)
 	apiIoTSf.POST("ina/create", inaController.Create)
 	apiIoTSf.GET("ina/get_all", inaController.GetAll)
 	apiIoTSf.GET("ina/detail", inaController.GetById)
 	apiIoTSf.GET("ina/detail/token", inaController.GetByToken)
+	apiIoTSf.GET("ina/export", inaController.Export)
 	apiIoTSf.PUT("ina/update/:id", inaController.Update)
 	apiIoTSf.DELETE("ina/delete/:id", inaController.Delete)
 
 	// route for sensor pzem
-	pzemController := pzemController.NewPzemController(db)
+	pzemController := pzemController.NewPzemController(db, logger)
 	apiIoTSf.POST("pzem/create", pzemController.Create)
 	apiIoTSf.GET("pzem/get_all", pzemController.GetAll)
 	apiIoTSf.GET("pzem/detail", pzemController.GetById)
 	apiIoTSf.GET("pzem/detail/token", pzemController.GetByToken)
+	apiIoTSf.GET("pzem/export", pzemController.Export)
 	apiIoTSf.PUT("pzem/update/:id", pzemController.Update)
 	apiIoTSf.DELETE("pzem/delete/:id", pzemController.Delete)
 
 	// route for sensor thigrow
-	thigrowController := thigrowController.NewThigrowController(db)
+	thigrowController := thigrowController.NewThigrowController(db, logger)
 	apiIoTSf.POST("thigrow/create", thigrowController.Create)
 	apiIoTSf.GET("thigrow/get_all", thigrowController.GetAll)
 	apiIoTSf.GET("thigrow/detail", thigrowController.GetById)
 	apiIoTSf.GET("thigrow/detail/token", thigrowController.GetByToken)
+	apiIoTSf.GET("thigrow/export", thigrowController.Export)
 	apiIoTSf.PUT("thigrow/update/:id", thigrowController.Update)
 	apiIoTSf.DELETE("thigrow/delete/:id", thigrowController.Delete)
 
 	// route for sensor thm30d
-	thmController := thmController.NewThmController(db)
+	thmController := thmController.NewThmController(db, logger)
 	apiIoTSf.POST("thm/create", thmController.Create)
 	apiIoTSf.GET("thm/get_all", thmController.GetAll)
 	apiIoTSf.GET("thm/detail", thmController.GetById)
 	apiIoTSf.GET("thm/detail/token", thmController.GetByToken)
+	apiIoTSf.GET("thm/export", thmController.Export)
 	apiIoTSf.PUT("thm/update/:id", thmController.Update)
 	apiIoTSf.DELETE("thm/delete/:id", thmController.Delete)
 
-	route.Start(":" + os.Getenv("PORT"))
+	// route for anomaly events
+	anomalyController := anomalyController.NewAnomalyController(db, logger)
+	apiIoTSf.GET("anomaly/events", anomalyController.GetEvents)
+
+	// route for device calibration
+	calibrationController := calibrationController.NewCalibrationController(db, logger)
+	apiIoTSf.POST("calibration/create", calibrationController.Create)
+	apiIoTSf.GET("calibration/get_all", calibrationController.GetAll)
+	apiIoTSf.GET("calibration/detail", calibrationController.GetById)
+	apiIoTSf.PUT("calibration/update/:id", calibrationController.Update)
+	apiIoTSf.DELETE("calibration/delete/:id", calibrationController.Delete)
+	apiIoTSf.POST("calibration/recompute", calibrationController.Recompute)
+
+	// route for data completeness and gap report
+	completenessController := completenessController.NewCompletenessController(db, logger)
+	apiIoTSf.GET("completeness", completenessController.GetReport)
+
+	// route for GraphQL query over devices and sensor readings
+	graphController := graphController.NewGraphController(db, logger)
+	apiIoTSf.POST("graphql", graphController.Query)
+
+	// route for bulk import historical readings
+	importController := importController.NewImportController(db, logger)
+	apiIoTSf.POST("import/:sensor", importController.Import)
+
+	// route for data retention and rollup
+	retentionController := retentionController.NewRetentionController(db, retentionJob, logger)
+	apiIoTSf.GET("retention/policy", retentionController.GetPolicies)
+	apiIoTSf.PUT("retention/policy/:sensor", retentionController.SavePolicy)
+	apiIoTSf.GET("retention/status", retentionController.GetStatus)
+	apiIoTSf.POST("retention/run", retentionController.Run)
+	apiIoTSf.GET("history", retentionController.GetHistory)
+
+	// route for REST API v2, error dikirim sebagai objek error oleh ErrorHandler
+	apiV2 := route.Group("api/v2/")
+	apiV2.Use(middleware.RouteTimeout(config.GetDuration("QUERY_TIMEOUT", 10*time.Second), nil))
+
+	readingsController := readingsController.NewReadingsController(db, map[string]readingsController.SensorController{
+		"beitian": beitianController,
+		"bmp":     bmpController,
+		"ina":     inaController,
+		"pzem":    pzemController,
+		"thigrow": thigrowController,
+		"thm":     thmController,
+	}, logger)
+	apiV2.GET("sensors/:type/readings", readingsController.List)
+	apiV2.POST("sensors/:type/readings", readingsController.Create)
+	apiV2.GET("sensors/:type/readings/:id", readingsController.Get)
+	apiV2.PATCH("sensors/:type/readings/:id", readingsController.Update)
+	apiV2.DELETE("sensors/:type/readings/:id", readingsController.Delete)
+	apiV2.GET("readings/:id", readingsController.GetReading)
+	apiV2.PATCH("readings/:id", readingsController.UpdateReading)
+	apiV2.DELETE("readings/:id", readingsController.DeleteReading)
 }
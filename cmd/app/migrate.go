@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iot-golang/internal/migrations"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// runMigrate menjalankan subcommand migrate, contoh:
+//
+//	app migrate up
+//	app migrate down 1
+//	app migrate status
+func runMigrate(db *gorm.DB, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: app migrate up|down [steps]|status")
+		return 2
+	}
+
+	logger := slog.Default()
+	migrator := migrations.NewMigrator(db, logger)
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return 1
+		}
+		if err := migrations.CreateHypertables(db, logger); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return 1
+		}
+		fmt.Printf("%d migration berhasil dijalankan\n", len(done))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				fmt.Fprintf(os.Stderr, "ERROR: jumlah steps %q tidak valid\n", args[1])
+				return 2
+			}
+			steps = n
+		}
+		done, err := migrator.Down(steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return 1
+		}
+		fmt.Printf("%d migration berhasil di-rollback\n", len(done))
+	case "status":
+		status, err := migrator.Status(context.Background())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return 1
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(status)
+	default:
+		fmt.Fprintf(os.Stderr, "ERROR: perintah migrate %q tidak dikenali\n", args[0])
+		return 2
+	}
+
+	return 0
+}
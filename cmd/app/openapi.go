@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"iot-golang/config"
+	"iot-golang/internal/lifecycle"
+	"iot-golang/internal/openapi"
+	retentionServices "iot-golang/internal/retention/services"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// runOpenAPI menjalankan subcommand openapi, contoh:
+//
+//	app openapi spec > openapi.json
+//	app openapi check
+//
+// check keluar dengan status 1 bila route di registerRoutes dan spesifikasi
+// berbeda, sehingga bisa dipasang di pipeline CI.
+func runOpenAPI(db *gorm.DB, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: app openapi spec|check")
+		return 2
+	}
+
+	switch args[0] {
+	case "spec":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(openapi.Build(config.Version)); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return 1
+		}
+	case "check":
+		logger := slog.Default()
+		route := echo.New()
+		registerRoutes(route, db, logger, lifecycle.NewManager(time.Second, logger), retentionServices.NewJob(db, logger))
+
+		divergence := openapi.Check(route.Routes())
+		if !divergence.Empty() {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", divergence)
+			return 1
+		}
+		fmt.Printf("%d route sesuai dengan spesifikasi OpenAPI\n", len(openapi.Operations))
+	default:
+		fmt.Fprintf(os.Stderr, "ERROR: perintah openapi %q tidak dikenali\n", args[0])
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"iot-golang/internal/lifecycle"
+	"iot-golang/internal/openapi"
+	retentionServices "iot-golang/internal/retention/services"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestRoutesMatchOpenAPI memastikan setiap route terdokumentasi di spesifikasi
+// OpenAPI dan setiap operasi di spesifikasi memiliki route.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gagal membuka database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("gagal mengambil pool database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	route := echo.New()
+	registerRoutes(route, db, discard, lifecycle.NewManager(time.Second, discard), retentionServices.NewJob(db, discard))
+
+	if divergence := openapi.Check(route.Routes()); !divergence.Empty() {
+		t.Fatal(divergence.Error())
+	}
+}
@@ -1,35 +1,81 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"iot-golang/internal/logging"
 	"log"
+	"log/slog"
 	"os"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 type CredentialDB struct {
+	driver   string
 	username string
 	password string
 	host     string
 	port     string
 	name     string
+	sslMode  string
+}
+
+// dialector memilih driver database berdasarkan DB_DRIVER. MySQL tetap menjadi
+// default, PostgreSQL dipakai untuk TimescaleDB dan SQLite untuk gateway edge
+// maupun database sementara.
+func (dbUrl CredentialDB) dialector() (gorm.Dialector, error) {
+	switch dbUrl.driver {
+	case "", DriverMySQL:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", dbUrl.username, dbUrl.password, dbUrl.host, dbUrl.port, dbUrl.name)
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		sslMode := dbUrl.sslMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s", dbUrl.host, dbUrl.username, dbUrl.password, dbUrl.name, dbUrl.port, sslMode)
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		dsn := dbUrl.name + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+		if dbUrl.name == ":memory:" {
+			dsn = dbUrl.name
+		}
+		return sqlite.Open(dsn), nil
+	}
+	return nil, fmt.Errorf("driver database %q tidak didukung", dbUrl.driver)
 }
 
 func InitDB() *gorm.DB {
 
 	var dbUrl = CredentialDB{
+		driver:   os.Getenv("DB_DRIVER"),
 		username: os.Getenv("DB_USERNAME"),
 		password: os.Getenv("DB_PASSWORD"),
 		host:     os.Getenv("DB_HOSTNAME"),
 		port:     os.Getenv("DB_PORT"),
 		name:     os.Getenv("DB_NAME"),
+		sslMode:  os.Getenv("DB_SSLMODE"),
 	}
-	var err error
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", dbUrl.username, dbUrl.password, dbUrl.host, dbUrl.port, dbUrl.name)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	dialector, err := dbUrl.dialector()
+	if err != nil {
+		log.Fatalf("Error to connect database: %v", err)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         logging.NewGormLogger(slog.Default(), logging.SlowQueryThreshold),
+		TranslateError: true,
+	})
 
 	if err != nil {
 		log.Fatal("Error to connect database")
@@ -37,3 +83,27 @@ func InitDB() *gorm.DB {
 
 	return db
 }
+
+// TimescaleEnabled bernilai true jika DB_TIMESCALE aktif pada driver PostgreSQL.
+func TimescaleEnabled(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverPostgres && os.Getenv("DB_TIMESCALE") == "true"
+}
+
+// FlushDB memindahkan isi WAL SQLite ke file database agar seluruh tulisan
+// tersimpan di file utama sebelum proses berhenti. Driver lain menulis
+// langsung saat commit sehingga tidak perlu di-flush.
+func FlushDB(ctx context.Context, db *gorm.DB) error {
+	if db.Dialector.Name() != DriverSQLite {
+		return nil
+	}
+	return db.WithContext(ctx).Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error
+}
+
+// CloseDB menutup pool koneksi database.
+func CloseDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
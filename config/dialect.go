@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TimeBucket mengembalikan ekspresi SQL awal bucket waktu dalam detik epoch
+// untuk kolom yang diberikan. Nilai epoch dipakai agar hasilnya dapat dibaca
+// dengan cara yang sama di MySQL, PostgreSQL/TimescaleDB dan SQLite.
+func TimeBucket(db *gorm.DB, column string, interval time.Duration) string {
+	seconds := int64(interval.Seconds())
+
+	switch db.Dialector.Name() {
+	case DriverPostgres:
+		if TimescaleEnabled(db) {
+			return fmt.Sprintf("CAST(EXTRACT(EPOCH FROM time_bucket('%d seconds'::interval, %s)) AS BIGINT)", seconds, column)
+		}
+		return fmt.Sprintf("CAST(FLOOR(EXTRACT(EPOCH FROM %s) / %d) * %d AS BIGINT)", column, seconds, seconds)
+	case DriverSQLite:
+		return fmt.Sprintf("(CAST(strftime('%%s', %s) AS INTEGER) / %d) * %d", column, seconds, seconds)
+	default:
+		return fmt.Sprintf("CAST(FLOOR(UNIX_TIMESTAMP(%s) / %d) * %d AS SIGNED)", column, seconds, seconds)
+	}
+}
+
+// CastNumeric mengembalikan ekspresi SQL untuk membaca kolom sensor yang
+// disimpan sebagai string maupun integer menjadi angka. Nilai kosong dibaca sebagai NULL.
+func CastNumeric(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case DriverPostgres:
+		return fmt.Sprintf("CAST(NULLIF(CAST(%s AS TEXT), '') AS DOUBLE PRECISION)", column)
+	case DriverSQLite:
+		return fmt.Sprintf("CAST(NULLIF(CAST(%s AS TEXT), '') AS REAL)", column)
+	default:
+		return fmt.Sprintf("CAST(NULLIF(CAST(%s AS CHAR), '') AS DECIMAL(20,6))", column)
+	}
+}
+
+// Upsert menyimpan data atau memperbarui kolom update jika terjadi konflik
+// pada kolom unik. GORM menerjemahkannya menjadi ON DUPLICATE KEY UPDATE
+// untuk MySQL dan ON CONFLICT untuk PostgreSQL maupun SQLite.
+func Upsert(db *gorm.DB, value interface{}, conflict []string, update []string) error {
+	columns := make([]clause.Column, len(conflict))
+	for i, name := range conflict {
+		columns[i] = clause.Column{Name: name}
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   columns,
+		DoUpdates: clause.AssignmentColumns(update),
+	}).Create(value).Error
+}
+
+// UpsertExpr sama seperti Upsert, tetapi kolom diperbarui dengan ekspresi SQL
+// sehingga nilai lama dapat digabung dengan nilai baru. MySQL mengevaluasi
+// assignment secara berurutan, kolom yang dipakai ekspresi lain harus
+// diletakkan paling akhir.
+func UpsertExpr(db *gorm.DB, value interface{}, conflict []string, update []clause.Assignment) error {
+	columns := make([]clause.Column, len(conflict))
+	for i, name := range conflict {
+		columns[i] = clause.Column{Name: name}
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   columns,
+		DoUpdates: clause.Set(update),
+	}).Create(value).Error
+}
+
+// Excluded mengembalikan ekspresi SQL nilai baru kolom yang ditolak karena
+// konflik pada upsert.
+func Excluded(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == DriverMySQL {
+		return fmt.Sprintf("VALUES(%s)", column)
+	}
+	return "excluded." + column
+}
+
+// Least mengembalikan ekspresi SQL nilai terkecil dari dua ekspresi.
+// SQLite tidak memiliki LEAST, MIN dengan dua argumen berfungsi sama.
+func Least(db *gorm.DB, a string, b string) string {
+	if db.Dialector.Name() == DriverSQLite {
+		return fmt.Sprintf("MIN(%s, %s)", a, b)
+	}
+	return fmt.Sprintf("LEAST(%s, %s)", a, b)
+}
+
+// Greatest mengembalikan ekspresi SQL nilai terbesar dari dua ekspresi.
+func Greatest(db *gorm.DB, a string, b string) string {
+	if db.Dialector.Name() == DriverSQLite {
+		return fmt.Sprintf("MAX(%s, %s)", a, b)
+	}
+	return fmt.Sprintf("GREATEST(%s, %s)", a, b)
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// GetDuration membaca durasi dari environment, contoh "10s" atau "5m".
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
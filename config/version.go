@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// Version diisi saat build, contoh:
+// go build -ldflags "-X iot-golang/config.Version=1.4.0" ./cmd/app
+var Version = "dev"
+
+// StartedAt adalah waktu proses dijalankan.
+var StartedAt = time.Now()
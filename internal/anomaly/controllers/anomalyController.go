@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"iot-golang/internal/anomaly/models"
+	"iot-golang/internal/anomaly/services"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/sensors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type AnomalyController struct {
+	anomalyService services.AnomalyService
+}
+
+func (controller AnomalyController) GetEvents(c echo.Context) error {
+	idToken := c.QueryParam("device_token")
+	if idToken == "" {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"device_token": i18n.T(c.Request().Context(), i18n.FieldRequired, "device_token")})
+	}
+
+	sensor := c.QueryParam("sensor")
+	if _, ok := sensors.Find(sensor); sensor != "" && !ok {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"sensor": i18n.T(c.Request().Context(), i18n.SensorUnknown, sensor)})
+	}
+
+	// Satuan diperiksa lebih dulu, konversi dilakukan per sensor setiap event
+	if _, err := helpers.ParseUnits(nil, c.QueryParam("units")); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	result, err := controller.anomalyService.GetEvents(c.Request().Context(), idToken, sensor, start, end)
+	if err != nil {
+		return err
+	}
+
+	events, _ := result.Data.([]models.AnomalyEvent)
+	convertEvents(events, c.QueryParam("units"))
+	return result.JSON(c, http.StatusOK)
+}
+
+// convertEvents mengonversi nilai event ke satuan yang diminta dan mengisi
+// satuannya. Satuan disimpan per event karena field dengan nama sama dapat
+// memiliki satuan berbeda, misalnya arus pada ina (mA) dan pzem (A).
+func convertEvents(events []models.AnomalyEvent, value string) {
+	units := map[string]helpers.Units{}
+	for i, event := range events {
+		sensorUnits, ok := units[event.Sensor]
+		if !ok {
+			sensor, _ := sensors.Find(event.Sensor)
+			// value sudah diperiksa sehingga error tidak mungkin terjadi
+			sensorUnits, _ = helpers.ParseUnits(sensor.Fields, value)
+			units[event.Sensor] = sensorUnits
+		}
+		events[i].Value = sensorUnits.Value(event.Field, event.Value)
+		events[i].Unit = sensorUnits.Map()[event.Field]
+	}
+}
+
+func NewAnomalyController(db *gorm.DB, logger *slog.Logger) AnomalyController {
+	controller := AnomalyController{
+		anomalyService: services.NewAnomalyService(db, logger),
+	}
+
+	return controller
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+type AnomalyEvent struct {
+	Id          int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor      string    `json:"sensor" gorm:"column:sensor"`
+	DeviceToken string    `json:"device_token" gorm:"column:device_token"`
+	Field       string    `json:"field" gorm:"column:field"`
+	Value       float64   `json:"value" gorm:"column:value"`
+	Unit        string    `json:"unit,omitempty" gorm:"-"`
+	Score       float64   `json:"score" gorm:"column:score"`
+	Method      string    `json:"method" gorm:"column:method"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (AnomalyEvent) TableName() string {
+	return "db_anomaly_event"
+}
+
+const (
+	// MethodMAD adalah skor robust berdasarkan median absolute deviation.
+	MethodMAD = "mad"
+	// MethodZScore dipakai ketika MAD bernilai 0 sehingga skor dihitung dari simpangan baku.
+	MethodZScore = "zscore"
+	// MethodRate dipakai ketika perubahan per menit melebihi MaxRate field.
+	MethodRate = "rate"
+)
+
+// Reading adalah satu data sensor yang akan diperiksa. At adalah waktu data,
+// waktu sekarang dipakai bila kosong.
+type Reading struct {
+	DeviceToken string
+	Quality     string
+	Values      map[string]float64
+	At          time.Time
+}
+
+// Sample adalah nilai satu field yang dimasukkan ke window detektor setelah
+// data berhasil disimpan.
+type Sample struct {
+	Sensor      string
+	DeviceToken string
+	Field       string
+	Value       float64
+	At          time.Time
+}
+
+// Inspection adalah hasil pemeriksaan satu data sensor. Score adalah skor
+// tertinggi dari seluruh field, Events berisi field yang melewati threshold
+// dan Samples berisi nilai yang masuk ke window saat Record dipanggil. Events
+// memakai waktu data sebagai created_at.
+type Inspection struct {
+	Score   float64
+	Events  []AnomalyEvent
+	Samples []Sample
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"iot-golang/internal/anomaly/models"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type dbAnomaly struct {
+	Conn *gorm.DB
+}
+
+// CreateEvents implements AnomalyRepository.
+func (db *dbAnomaly) CreateEvents(ctx context.Context, events []models.AnomalyEvent) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "anomaly", "CreateEvents")).Create(&events).Error
+}
+
+// GetEvents implements AnomalyRepository.
+func (db *dbAnomaly) GetEvents(ctx context.Context, DeviceToken string, Sensor string, Start time.Time, End time.Time) ([]models.AnomalyEvent, error) {
+	var data []models.AnomalyEvent
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "anomaly", "GetEvents")).Where("device_token = ? AND created_at >= ? AND created_at < ?", DeviceToken, Start, End)
+	if Sensor != "" {
+		query = query.Where("sensor = ?", Sensor)
+	}
+	result := query.Order("created_at desc").Find(&data)
+	return data, result.Error
+}
+
+// GetRecent implements AnomalyRepository.
+// Data bad tidak diambil agar tidak ikut mengisi window detektor.
+func (db *dbAnomaly) GetRecent(ctx context.Context, Table string, Columns []string, DeviceToken string, Limit int) ([]map[string]interface{}, error) {
+	var data []map[string]interface{}
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "anomaly", "GetRecent")).Table(Table).
+		Select(append(Columns, "created_at")).
+		Where("device_token = ? AND quality <> ?", DeviceToken, helpers.QualityBad).
+		Order("created_at desc").
+		Limit(Limit).
+		Find(&data)
+	return data, result.Error
+}
+
+// GetBefore implements AnomalyRepository.
+// Sama dengan GetRecent tetapi hanya mengambil data sebelum waktu Before.
+func (db *dbAnomaly) GetBefore(ctx context.Context, Table string, Columns []string, DeviceToken string, Before time.Time, Limit int) ([]map[string]interface{}, error) {
+	var data []map[string]interface{}
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "anomaly", "GetBefore")).Table(Table).
+		Select(append(Columns, "created_at")).
+		Where("device_token = ? AND quality <> ? AND created_at < ?", DeviceToken, helpers.QualityBad, Before).
+		Order("created_at desc").
+		Limit(Limit).
+		Find(&data)
+	return data, result.Error
+}
+
+type AnomalyRepository interface {
+	CreateEvents(ctx context.Context, events []models.AnomalyEvent) error
+	GetEvents(ctx context.Context, DeviceToken string, Sensor string, Start time.Time, End time.Time) ([]models.AnomalyEvent, error)
+	GetRecent(ctx context.Context, Table string, Columns []string, DeviceToken string, Limit int) ([]map[string]interface{}, error)
+	GetBefore(ctx context.Context, Table string, Columns []string, DeviceToken string, Before time.Time, Limit int) ([]map[string]interface{}, error)
+}
+
+func NewAnomalyRepository(Conn *gorm.DB, logger *slog.Logger) AnomalyRepository {
+	return &dbAnomaly{Conn: logging.Session(Conn, logger.With("repository", "anomaly"))}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"iot-golang/internal/anomaly/models"
+	"iot-golang/internal/anomaly/repositories"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"iot-golang/internal/sensors"
+	"iot-golang/internal/tracing"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type anomalyService struct {
+	logger      *slog.Logger
+	anomalyRepo repositories.AnomalyRepository
+	detector    *detector
+}
+
+// Inspect implements AnomalyService.
+// Nilai setiap field dinilai terhadap window device tersebut. Window belum
+// diubah sampai Record dipanggil, sehingga data yang gagal disimpan tidak ikut
+// menjadi acuan. Data bad tetap diberi skor tetapi tidak mengisi window dan
+// tidak menghasilkan event karena sudah ditandai oleh pemeriksaan rentang.
+func (service *anomalyService) Inspect(ctx context.Context, Sensor string, DeviceToken string, Quality string, Values map[string]float64) models.Inspection {
+	ctx, span := tracing.Start(ctx, "AnomalyService.Inspect")
+	defer span.End()
+
+	sensor, ok := sensors.Find(Sensor)
+	if !ok {
+		return models.Inspection{}
+	}
+
+	if !service.detector.isSeeded(Sensor, DeviceToken) {
+		service.seed(ctx, sensor, DeviceToken)
+	}
+
+	service.detector.mu.Lock()
+	defer service.detector.mu.Unlock()
+
+	reading := models.Reading{DeviceToken: DeviceToken, Quality: Quality, Values: Values}
+	return inspect(sensor, reading, func(field string) *window {
+		return service.detector.window(Sensor, DeviceToken, field)
+	})
+}
+
+// InspectBatch implements AnomalyService.
+// Batch dan import CSV bisa berisi data lama, sehingga data setiap device
+// dinilai urut waktu terhadap window yang diisi dari data sebelum data
+// terlama di batch, bukan terhadap window bersama. Window bersama tidak diubah
+// oleh batch sehingga Samples pada hasilnya selalu kosong.
+func (service *anomalyService) InspectBatch(ctx context.Context, Sensor string, Readings []models.Reading) []models.Inspection {
+	ctx, span := tracing.Start(ctx, "AnomalyService.InspectBatch")
+	defer span.End()
+
+	inspections := make([]models.Inspection, len(Readings))
+	sensor, ok := sensors.Find(Sensor)
+	if !ok {
+		return inspections
+	}
+
+	Readings = slices.Clone(Readings)
+	now := time.Now()
+	devices := map[string][]int{}
+	var tokens []string
+	for i := range Readings {
+		if Readings[i].At.IsZero() {
+			Readings[i].At = now
+		}
+		token := Readings[i].DeviceToken
+		if _, ok := devices[token]; !ok {
+			tokens = append(tokens, token)
+		}
+		devices[token] = append(devices[token], i)
+	}
+
+	for _, token := range tokens {
+		indices := devices[token]
+		sort.SliceStable(indices, func(a, b int) bool {
+			return Readings[indices[a]].At.Before(Readings[indices[b]].At)
+		})
+
+		windows := service.windowsBefore(ctx, sensor, token, Readings[indices[0]].At)
+		for _, i := range indices {
+			inspections[i] = inspect(sensor, Readings[i], windows.window)
+			for _, sample := range inspections[i].Samples {
+				windows.window(sample.Field).push(sample.Value, sample.At)
+			}
+			inspections[i].Samples = nil
+		}
+	}
+
+	return inspections
+}
+
+// windowsBefore membuat window setiap field dari data device yang tersimpan
+// sebelum waktu before. Window kosong dipakai bila data gagal diambil.
+func (service *anomalyService) windowsBefore(ctx context.Context, sensor sensors.Sensor, DeviceToken string, before time.Time) windowSet {
+	columns, selected := fieldColumns(sensor)
+	windows := windowSet{}
+
+	rows, err := service.anomalyRepo.GetBefore(ctx, sensor.Table, selected, DeviceToken, before, WindowSize)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sebelum batch untuk detektor anomali", "sensor", sensor.Name, "device_token", DeviceToken, logging.Error(err))
+		return windows
+	}
+
+	fill(columns, rows, windows.window)
+	return windows
+}
+
+// inspect menilai satu data terhadap window yang dikembalikan windowOf.
+func inspect(sensor sensors.Sensor, reading models.Reading, windowOf func(field string) *window) models.Inspection {
+	var inspection models.Inspection
+	at := reading.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	for _, field := range sensor.Fields {
+		value, ok := reading.Values[field.Name]
+		if !ok {
+			continue
+		}
+
+		score, method := windowOf(field.Name).score(value, at, field.MaxRate)
+		if score > inspection.Score {
+			inspection.Score = score
+		}
+		if reading.Quality == helpers.QualityBad {
+			continue
+		}
+
+		inspection.Samples = append(inspection.Samples, models.Sample{Sensor: sensor.Name, DeviceToken: reading.DeviceToken, Field: field.Name, Value: value, At: at})
+		if score >= Threshold {
+			inspection.Events = append(inspection.Events, models.AnomalyEvent{
+				Sensor:      sensor.Name,
+				DeviceToken: reading.DeviceToken,
+				Field:       field.Name,
+				Value:       value,
+				Score:       score,
+				Method:      method,
+				CreatedAt:   at,
+			})
+		}
+	}
+
+	return inspection
+}
+
+// seed mengisi window device dari data terakhir di database, sehingga deteksi
+// tetap berjalan setelah service di-restart.
+func (service *anomalyService) seed(ctx context.Context, sensor sensors.Sensor, DeviceToken string) {
+	columns, selected := fieldColumns(sensor)
+
+	rows, err := service.anomalyRepo.GetRecent(ctx, sensor.Table, selected, DeviceToken, WindowSize)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data terakhir untuk detektor anomali", "sensor", sensor.Name, "device_token", DeviceToken, logging.Error(err))
+		return
+	}
+
+	service.detector.seed(sensor.Name, DeviceToken, columns, rows)
+}
+
+// fieldColumns mengembalikan kolom setiap field sensor beserta daftar kolom
+// yang diambil dari database.
+func fieldColumns(sensor sensors.Sensor) (map[string]string, []string) {
+	columns := make(map[string]string, len(sensor.Fields))
+	selected := make([]string, 0, len(sensor.Fields))
+	for _, field := range sensor.Fields {
+		columns[field.Name] = field.Column
+		selected = append(selected, field.Column)
+	}
+	return columns, selected
+}
+
+// Record implements AnomalyService.
+// Dipanggil setelah data tersimpan. Nilai data dimasukkan ke window lalu event
+// anomali disimpan. Nilai yang lebih lama dari nilai terakhir window dilewati
+// agar window tetap urut waktu.
+func (service *anomalyService) Record(ctx context.Context, inspections ...models.Inspection) {
+	ctx, span := tracing.Start(ctx, "AnomalyService.Record")
+	defer span.End()
+
+	var events []models.AnomalyEvent
+	service.detector.mu.Lock()
+	for _, inspection := range inspections {
+		for _, sample := range inspection.Samples {
+			w := service.detector.window(sample.Sensor, sample.DeviceToken, sample.Field)
+			if sample.At.Before(w.lastTime) {
+				continue
+			}
+			w.push(sample.Value, sample.At)
+		}
+		events = append(events, inspection.Events...)
+	}
+	service.detector.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	if err := service.anomalyRepo.CreateEvents(ctx, events); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal menyimpan event anomali", logging.Error(err))
+		return
+	}
+
+	for _, event := range events {
+		metrics.RecordAnomaly(event.Sensor, event.Field)
+		service.logger.WarnContext(ctx, "Anomali terdeteksi", "sensor", event.Sensor, "device_token", event.DeviceToken, "field", event.Field, "value", event.Value, "score", event.Score, "method", event.Method)
+	}
+}
+
+// GetEvents implements AnomalyService.
+func (service *anomalyService) GetEvents(ctx context.Context, DeviceToken string, Sensor string, Start time.Time, End time.Time) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "AnomalyService.GetEvents")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.anomalyRepo.GetEvents(ctx, DeviceToken, Sensor, Start, End)
+
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil event anomali", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.AnomalyEventsFailed, DeviceToken), err)
+	} else if len(data) == 0 {
+		service.logger.InfoContext(ctx, "Tidak menemukan event anomali", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.AnomalyEventsMissing, DeviceToken))
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil event anomali", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.AnomalyEventsFound, DeviceToken)
+	response.Data = data
+
+	return response, nil
+}
+
+type AnomalyService interface {
+	Inspect(ctx context.Context, Sensor string, DeviceToken string, Quality string, Values map[string]float64) models.Inspection
+	InspectBatch(ctx context.Context, Sensor string, Readings []models.Reading) []models.Inspection
+	Record(ctx context.Context, inspections ...models.Inspection)
+	GetEvents(ctx context.Context, DeviceToken string, Sensor string, Start time.Time, End time.Time) (helpers.Response, error)
+}
+
+func NewAnomalyService(db *gorm.DB, logger *slog.Logger) AnomalyService {
+	return &anomalyService{
+		logger:      logger.With("component", "anomaly"),
+		anomalyRepo: repositories.NewAnomalyRepository(db, logger),
+		detector:    sharedDetector,
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"io"
+	"iot-golang/internal/anomaly/models"
+	bmpModels "iot-golang/internal/bmp/models"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/migrations"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB membuka database SQLite di memori yang sudah dimigrasi. Pool
+// dibatasi satu koneksi karena setiap koneksi :memory: memiliki database sendiri.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard, TranslateError: true})
+	if err != nil {
+		t.Fatalf("gagal membuka database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("gagal mengambil pool database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if _, err := migrations.NewMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil))).Up(); err != nil {
+		t.Fatalf("gagal menjalankan migration: %v", err)
+	}
+
+	return db
+}
+
+// TestInspectBatchBackfill memastikan data lama pada batch dinilai terhadap
+// data sebelumnya di database dan tidak mengubah window data real-time.
+func TestInspectBatchBackfill(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	token := "dev-backfill"
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 30; i++ {
+		reading := bmpModels.Bmp{
+			DeviceToken:     token,
+			TekananUdara:    strconv.Itoa(1000 + (i%5)*2),
+			TinggiPermukaan: "10",
+			Battery:         "4",
+			CreatedAt:       base.Add(time.Duration(i) * 10 * time.Minute),
+		}
+		if err := db.Create(&reading).Error; err != nil {
+			t.Fatalf("gagal menyimpan data sensor: %v", err)
+		}
+	}
+
+	service := NewAnomalyService(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.Record(ctx, service.Inspect(ctx, "bmp", token, helpers.QualityGood, map[string]float64{"tekanan_udara": 1000}))
+
+	live := func() (int, time.Time) {
+		sharedDetector.mu.Lock()
+		defer sharedDetector.mu.Unlock()
+		w := sharedDetector.window("bmp", token, "tekanan_udara")
+		return len(w.values), w.lastTime
+	}
+	liveSize, liveTime := live()
+
+	spikeAt := base.Add(215 * time.Minute)
+	readings := []models.Reading{
+		{DeviceToken: token, Quality: helpers.QualityGood, Values: map[string]float64{"tekanan_udara": 1010}, At: base.Add(205 * time.Minute)},
+		{DeviceToken: token, Quality: helpers.QualityGood, Values: map[string]float64{"tekanan_udara": 1010}, At: base.Add(195 * time.Minute)},
+		{DeviceToken: token, Quality: helpers.QualityGood, Values: map[string]float64{"tekanan_udara": 1090}, At: spikeAt},
+	}
+	inspections := service.InspectBatch(ctx, "bmp", readings)
+
+	for i, inspection := range inspections[:2] {
+		if inspection.Score >= Threshold || len(inspection.Events) > 0 {
+			t.Errorf("data %d dianggap anomali dengan skor %v", i, inspection.Score)
+		}
+	}
+	if events := inspections[2].Events; len(events) != 1 || !events[0].CreatedAt.Equal(spikeAt) {
+		t.Errorf("event lonjakan = %+v, seharusnya satu event dengan created_at %v", events, spikeAt)
+	}
+
+	service.Record(ctx, inspections...)
+	if size, lastTime := live(); size != liveSize || !lastTime.Equal(liveTime) {
+		t.Errorf("window real-time berubah oleh batch: %d nilai sampai %v, sebelumnya %d nilai sampai %v", size, lastTime, liveSize, liveTime)
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"iot-golang/internal/anomaly/models"
+	"iot-golang/internal/helpers"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Parameter detektor, diatur dari ANOMALY_THRESHOLD, ANOMALY_WINDOW dan
+// ANOMALY_MIN_SAMPLES di main.go.
+var (
+	// Threshold adalah skor minimal sebuah nilai dianggap anomali.
+	Threshold = 3.5
+	// WindowSize adalah jumlah nilai terakhir per device dan field yang disimpan.
+	WindowSize = 50
+	// MinSamples adalah jumlah nilai minimal sebelum skor statistik dihitung.
+	MinSamples = 10
+)
+
+// madScale membuat skor MAD sebanding dengan z-score pada data berdistribusi normal.
+const madScale = 0.6745
+
+// window menyimpan nilai terakhir satu field dari satu device, urut dari yang terlama.
+type window struct {
+	values   []float64
+	last     float64
+	lastTime time.Time
+}
+
+func (w *window) push(value float64, at time.Time) {
+	w.values = append(w.values, value)
+	if len(w.values) > WindowSize {
+		w.values = w.values[len(w.values)-WindowSize:]
+	}
+	w.last = value
+	w.lastTime = at
+}
+
+// score menghitung skor anomali value terhadap isi window. Skor statistik memakai
+// MAD, atau simpangan baku jika MAD bernilai 0. Jika maxRate diisi, perubahan per
+// menit dibandingkan dengan maxRate dan diskalakan sehingga melewati batas sama
+// dengan melewati Threshold. Skor dan metode yang dikembalikan adalah yang tertinggi.
+func (w *window) score(value float64, at time.Time, maxRate float64) (float64, string) {
+	var score float64
+	method := models.MethodMAD
+
+	if len(w.values) >= MinSamples {
+		med := median(w.values)
+		deviations := make([]float64, len(w.values))
+		for i, v := range w.values {
+			deviations[i] = math.Abs(v - med)
+		}
+
+		if mad := median(deviations); mad > 0 {
+			score = madScale * math.Abs(value-med) / mad
+		} else if mean, std := meanStd(w.values); std > 0 {
+			score = math.Abs(value-mean) / std
+			method = models.MethodZScore
+		}
+	}
+
+	// Data yang lebih lama dari nilai terakhir tidak dinilai perubahannya
+	if maxRate > 0 && !w.lastTime.IsZero() && !at.Before(w.lastTime) {
+		// selisih waktu di bawah satu menit dihitung satu menit agar pengiriman beruntun tidak dianggap lonjakan
+		minutes := math.Max(at.Sub(w.lastTime).Minutes(), 1)
+		if rateScore := math.Abs(value-w.last) / minutes / maxRate * Threshold; rateScore > score {
+			score = rateScore
+			method = models.MethodRate
+		}
+	}
+
+	return math.Round(score*100) / 100, method
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func meanStd(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// windowSet menyimpan window per field satu device di luar detector, contoh
+// untuk menilai batch tanpa mengubah window bersama.
+type windowSet map[string]*window
+
+func (set windowSet) window(field string) *window {
+	w, ok := set[field]
+	if !ok {
+		w = &window{}
+		set[field] = w
+	}
+	return w
+}
+
+// detector menyimpan window seluruh device di memori. Satu detector dipakai
+// bersama oleh seluruh service agar state tidak terpecah antar instance.
+type detector struct {
+	mu      sync.Mutex
+	windows map[string]*window
+	seeded  map[string]bool
+}
+
+var sharedDetector = &detector{windows: map[string]*window{}, seeded: map[string]bool{}}
+
+func deviceKey(sensor string, deviceToken string) string {
+	return sensor + "/" + deviceToken
+}
+
+func (d *detector) window(sensor string, deviceToken string, field string) *window {
+	key := deviceKey(sensor, deviceToken) + "/" + field
+	w, ok := d.windows[key]
+	if !ok {
+		w = &window{}
+		d.windows[key] = w
+	}
+	return w
+}
+
+func (d *detector) isSeeded(sensor string, deviceToken string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.seeded[deviceKey(sensor, deviceToken)]
+}
+
+// seed mengisi window dari data tersimpan, rows urut dari yang terbaru. Window
+// yang sudah diisi oleh data baru di antara pengecekan dan seed tidak ditimpa.
+func (d *detector) seed(sensor string, deviceToken string, columns map[string]string, rows []map[string]interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := deviceKey(sensor, deviceToken)
+	if d.seeded[key] {
+		return
+	}
+	d.seeded[key] = true
+
+	fill(columns, rows, func(field string) *window {
+		return d.window(sensor, deviceToken, field)
+	})
+}
+
+// fill memasukkan nilai rows ke window yang dikembalikan windowOf. rows urut
+// dari yang terbaru sehingga dibaca dari belakang.
+func fill(columns map[string]string, rows []map[string]interface{}, windowOf func(field string) *window) {
+	for i := len(rows) - 1; i >= 0; i-- {
+		at, _ := helpers.ToTime(rows[i]["created_at"])
+		for field, column := range columns {
+			if value, ok := helpers.ToFloat(rows[i][column]); ok {
+				windowOf(field).push(value, at)
+			}
+		}
+	}
+}
@@ -1,12 +1,14 @@
 package controllers
 
 import (
-	"fmt"
 	"iot-golang/internal/beitian/models"
 	"iot-golang/internal/beitian/services"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
 	"net/http"
-	"reflect"
 	"strconv"
 
 	v1 "github.com/go-playground/validator/v10"
@@ -15,197 +17,226 @@ import (
 )
 
 type BeitianController struct {
+	logger         *slog.Logger
 	beitianService services.BeitianService
 	validate       v1.Validate
 }
 
-var httpStatus int
-
 func (controller BeitianController) Create(c echo.Context) error {
-	type payload struct {
-		DeviceToken string `json:"device_token" validate:"required"`
-		Latitude    string `json:"latitude" validate:"required,numeric"`
-		Longitude   string `json:"longitude" validate:"required,numeric"`
-		Battery     string `json:"battery" validate:"required,numeric"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.CreatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   err,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), err.Error())
 	}
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
 
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		metrics.RecordValidationRejections("beitian", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			} else if e.Tag() == "numeric" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh huruf", fieldName)
-			}
-			errorList[fieldName] = errMsg
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
 
-		}
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("beitian", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
-	result := controller.beitianService.Create(models.Beitian{DeviceToken: payloadValidator.DeviceToken, Latitude: payloadValidator.Latitude, Longitude: payloadValidator.Longitude, Battery: payloadValidator.Battery})
-
-	if result.Status == 201 {
-		httpStatus = http.StatusCreated
-	} else if result.Status == 400 {
-		httpStatus = http.StatusBadRequest
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	reading := payloadValidator.ToModel()
+	reading.Quality = report.Quality
+	result, err := controller.beitianService.Create(c.Request().Context(), reading)
+	if err != nil {
+		return err
 	}
-	return c.JSON(httpStatus, result)
+
+	return result.JSON(c, http.StatusCreated)
 }
 
 func (controller BeitianController) Update(c echo.Context) error {
-	type payload struct {
-		Latitude  string `json:"latitude" validate:"required"`
-		Longitude string `json:"longitude" validate:"required"`
-		Battery   string `json:"battery" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.UpdatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   err,
-			Messages: "Invalid request body",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
 	}
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
+
+		metrics.RecordValidationRejections("beitian", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("beitian", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
 	idBeitian, _ := strconv.Atoi(c.Param("id"))
-	result := controller.beitianService.Update(int64(idBeitian), models.Beitian{Latitude: payloadValidator.Latitude, Longitude: payloadValidator.Longitude, Battery: payloadValidator.Battery})
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.beitianService.Update(c.Request().Context(), int64(idBeitian), models.Beitian{Latitude: payloadValidator.Latitude, Longitude: payloadValidator.Longitude, Battery: payloadValidator.Battery, Quality: report.Quality})
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller BeitianController) Delete(c echo.Context) error {
 	idBeitian, _ := strconv.Atoi(c.Param("id"))
-	result := controller.beitianService.Delete(int64(idBeitian))
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.beitianService.Delete(c.Request().Context(), int64(idBeitian))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller BeitianController) GetAll(c echo.Context) error {
-	result := controller.beitianService.GetAll()
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.beitianService.GetAll(c.Request().Context(), quality)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller BeitianController) GetById(c echo.Context) error {
-	idBeitian, _ := strconv.Atoi(c.QueryParam("id"))
-	result := controller.beitianService.GetById(int64(idBeitian))
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	idBeitian, _ := strconv.Atoi(c.QueryParam("id"))
+	result, err := controller.beitianService.GetById(c.Request().Context(), int64(idBeitian))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller BeitianController) GetByToken(c echo.Context) error {
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
 	idTokenBeitian := c.QueryParam("device_token")
-	result := controller.beitianService.GetByToken(idTokenBeitian)
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.beitianService.GetByToken(c.Request().Context(), idTokenBeitian, quality)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller BeitianController) GetNewByToken(c echo.Context) error {
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	idTokenBeitian := c.QueryParam("device_token")
+	result, err := controller.beitianService.GetNewByToken(c.Request().Context(), idTokenBeitian)
+	if err != nil {
+		return err
+	}
+
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller BeitianController) GetTrack(c echo.Context) error {
 	idTokenBeitian := c.QueryParam("device_token")
-	result := controller.beitianService.GetNewByToken(idTokenBeitian)
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	result, err := controller.beitianService.GetTrack(c.Request().Context(), idTokenBeitian, start, end, quality)
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller BeitianController) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = helpers.ExportCSV
+	}
+
+	contentType, err := helpers.ExportContentType(format)
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"format": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
 	}
 
-	return c.JSON(httpStatus, result)
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	response := helpers.NewExportResponse(c.Response(), contentType, helpers.ExportFilename("beitian", start, end, format))
+	err = controller.beitianService.Export(c.Request().Context(), c.QueryParam("device_token"), start, end, quality, units, format, response)
+	if err != nil && c.Response().Committed {
+		// Status 200 sudah terkirim, koneksi diputus agar client tidak
+		// menganggap file yang terpotong sebagai file lengkap.
+		controller.logger.ErrorContext(c.Request().Context(), "Ekspor terhenti setelah response dikirim", logging.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+	return err
 }
 
-func NewBeitianController(db *gorm.DB) BeitianController {
-	service := services.NewBeitianService(db)
+func NewBeitianController(db *gorm.DB, logger *slog.Logger) BeitianController {
+	service := services.NewBeitianService(db, logger)
 	controller := BeitianController{
+		logger:         logger.With("sensor", "beitian"),
 		beitianService: service,
 		validate:       *v1.New(),
 	}
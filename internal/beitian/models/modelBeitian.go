@@ -1,13 +1,30 @@
 package models
 
+import (
+	"iot-golang/internal/helpers"
+	"time"
+)
+
 type Beitian struct {
-	Id          int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	DeviceToken string `json:"device_token" gorm:"column:device_token"`
-	Latitude    string `json:"latitude" gorm:"column:latitude"`
-	Longitude   string `json:"longitude" gorm:"column:longitude"`
-	Battery     string `json:"battery" gorm:"column:battery"`
+	Id           int64             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken  string            `json:"device_token" gorm:"column:device_token"`
+	Latitude     string            `json:"latitude" gorm:"column:latitude"`
+	Longitude    string            `json:"longitude" gorm:"column:longitude"`
+	Battery      string            `json:"battery" gorm:"column:battery"`
+	Quality      string            `json:"quality" gorm:"column:quality;default:good"`
+	AnomalyScore float64           `json:"anomaly_score" gorm:"column:anomaly_score;default:0"`
+	RawValues    map[string]string `json:"raw_values,omitempty" gorm:"column:raw_values;serializer:json"`
+	CreatedAt    time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 }
 
 func (Beitian) TableName() string {
 	return "db_sensor_beitian220"
 }
+
+// Fields berisi metadata field sensor beitian beserta satuan, rentang nilai dan
+// batas perubahan per menitnya.
+var Fields = []helpers.Field{
+	{Name: "latitude", Column: "latitude", Unit: "deg", Range: helpers.Between(-90, 90), MaxRate: 0.1},
+	{Name: "longitude", Column: "longitude", Unit: "deg", Range: helpers.Between(-180, 180), MaxRate: 0.1},
+	{Name: "battery", Column: "battery", Unit: "V", Range: helpers.Between(0, 12).Suspect(3, 4.5), MaxRate: 0.5},
+}
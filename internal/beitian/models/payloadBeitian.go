@@ -0,0 +1,26 @@
+package models
+
+// CreatePayload adalah payload untuk membuat data sensor beitian baru.
+// Aturan validasi yang sama dipakai oleh endpoint create dan import CSV.
+type CreatePayload struct {
+	DeviceToken string `json:"device_token" validate:"required"`
+	Latitude    string `json:"latitude" validate:"required,numeric"`
+	Longitude   string `json:"longitude" validate:"required,numeric"`
+	Battery     string `json:"battery" validate:"required,numeric"`
+}
+
+func (payload CreatePayload) ToModel() Beitian {
+	return Beitian{
+		DeviceToken: payload.DeviceToken,
+		Latitude:    payload.Latitude,
+		Longitude:   payload.Longitude,
+		Battery:     payload.Battery,
+	}
+}
+
+// UpdatePayload adalah payload untuk mengubah data sensor beitian.
+type UpdatePayload struct {
+	Latitude  string `json:"latitude" validate:"required,numeric"`
+	Longitude string `json:"longitude" validate:"required,numeric"`
+	Battery   string `json:"battery" validate:"required,numeric"`
+}
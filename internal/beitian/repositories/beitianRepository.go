@@ -1,7 +1,13 @@
 package repositories
 
 import (
+	"context"
 	"iot-golang/internal/beitian/models"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,58 +17,137 @@ type dbBeitian struct {
 }
 
 // GetNewByToken implements BeitianRepository.
-func (db *dbBeitian) GetNewByToken(DeviceToken string) ([]models.Beitian, error) {
+func (db *dbBeitian) GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Beitian, error) {
 	var data []models.Beitian
-	result := db.Conn.Where("device_token = ?", DeviceToken).Order("created_at desc").Limit(1).Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "GetNewByToken")).Where("device_token = ?", DeviceToken).Order("created_at desc").Limit(1).Find(&data)
+	return data, result.Error
+}
+
+// GetNewByTokens implements BeitianRepository.
+// Data terbaru setiap perangkat diambil dalam satu query dengan subquery
+// created_at terbaru per device_token.
+func (db *dbBeitian) GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Beitian, error) {
+	var data []models.Beitian
+	latest := db.Conn.Model(&models.Beitian{}).Select("device_token, MAX(created_at) AS created_at").Where("device_token IN ?", DeviceTokens).Group("device_token")
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "GetNewByTokens")).
+		Joins("JOIN (?) AS latest ON latest.device_token = db_sensor_beitian220.device_token AND latest.created_at = db_sensor_beitian220.created_at", latest).
+		Order("db_sensor_beitian220.id desc").
+		Find(&data)
 	return data, result.Error
 }
 
 // Create implements BeitianRepository.
-func (db *dbBeitian) Create(beitian models.Beitian) error {
-	return db.Conn.Create(&beitian).Error
+func (db *dbBeitian) Create(ctx context.Context, beitian models.Beitian) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "Create")).Create(&beitian).Error
+}
+
+// CreateBatch implements BeitianRepository.
+func (db *dbBeitian) CreateBatch(ctx context.Context, beitian []models.Beitian, BatchSize int) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "CreateBatch")).CreateInBatches(&beitian, BatchSize).Error
 }
 
 // Delete implements BeitianRepository.
-func (db *dbBeitian) Delete(Id int64) error {
-	return db.Conn.Delete(&models.Beitian{Id: Id}).Error
+func (db *dbBeitian) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "Delete")).Delete(&models.Beitian{Id: Id}).Error
 }
 
 // GetAll implements BeitianRepository.
-func (db *dbBeitian) GetAll() ([]models.Beitian, error) {
+func (db *dbBeitian) GetAll(ctx context.Context, Quality []string) ([]models.Beitian, error) {
 	var data []models.Beitian
-	result := db.Conn.Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "GetAll")).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// Query implements BeitianRepository.
+func (db *dbBeitian) Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Beitian, error) {
+	var data []models.Beitian
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "Query")).Scopes(Query.Scope).Find(&data)
 	return data, result.Error
 }
 
 // GetById implements BeitianRepository.
-func (db *dbBeitian) GetById(Id int64) (models.Beitian, error) {
+func (db *dbBeitian) GetById(ctx context.Context, Id int64) (models.Beitian, error) {
 	var data models.Beitian
-	result := db.Conn.Where("id", Id).First(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "GetById")).Where("id", Id).First(&data)
 	return data, result.Error
 }
 
 // GetByToken implements BeitianRepository.
-func (db *dbBeitian) GetByToken(DeviceToken string) ([]models.Beitian, error) {
+func (db *dbBeitian) GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Beitian, error) {
+	var data []models.Beitian
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "GetByToken")).Where("device_token", DeviceToken).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// GetTrackByToken implements BeitianRepository.
+func (db *dbBeitian) GetTrackByToken(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string) ([]models.Beitian, error) {
 	var data []models.Beitian
-	result := db.Conn.Where("device_token", DeviceToken).Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "GetTrackByToken")).Where("device_token = ? AND created_at >= ? AND created_at < ?", DeviceToken, Start, End).Scopes(helpers.QualityScope(Quality)).Order("created_at asc").Find(&data)
 	return data, result.Error
 }
 
+// GetDeviceTokens implements BeitianRepository.
+func (db *dbBeitian) GetDeviceTokens(ctx context.Context) ([]string, error) {
+	var tokens []string
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "GetDeviceTokens")).Model(&models.Beitian{}).Distinct("device_token").Pluck("device_token", &tokens)
+	return tokens, result.Error
+}
+
+// Export implements BeitianRepository.
+// Data dibaca memakai cursor Rows sehingga tidak dimuat seluruhnya ke memori.
+func (db *dbBeitian) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(beitian models.Beitian) error) error {
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "Export")).Model(&models.Beitian{}).Where("created_at >= ? AND created_at < ?", Start, End).Scopes(helpers.QualityScope(Quality))
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+
+	rows, err := query.Order("created_at asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data models.Beitian
+		if err := db.Conn.ScanRows(rows, &data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 // Update implements BeitianRepository.
-func (db *dbBeitian) Update(Id int64, beitian models.Beitian) error {
-	return db.Conn.Where("id", Id).Updates(beitian).Error
+// Kolom nilai, quality dan raw_values selalu ditulis, termasuk raw_values yang
+// kosong, agar nilai mentah lama tidak tertinggal setelah data diubah.
+func (db *dbBeitian) Update(ctx context.Context, Id int64, beitian models.Beitian) error {
+	columns := []string{"quality", "raw_values"}
+	for _, field := range models.Fields {
+		columns = append(columns, field.Column)
+	}
+
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "beitian", "Update")).Model(&models.Beitian{}).Where("id", Id).Select(columns).Updates(beitian).Error
 }
 
 type BeitianRepository interface {
-	Create(beitian models.Beitian) error
-	Update(Id int64, beitian models.Beitian) error
-	Delete(Id int64) error
-	GetById(Id int64) (models.Beitian, error)
-	GetByToken(DeviceToken string) ([]models.Beitian, error)
-	GetAll() ([]models.Beitian, error)
-	GetNewByToken(DeviceToken string) ([]models.Beitian, error)
+	Create(ctx context.Context, beitian models.Beitian) error
+	CreateBatch(ctx context.Context, beitian []models.Beitian, BatchSize int) error
+	Update(ctx context.Context, Id int64, beitian models.Beitian) error
+	Delete(ctx context.Context, Id int64) error
+	GetById(ctx context.Context, Id int64) (models.Beitian, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Beitian, error)
+	GetAll(ctx context.Context, Quality []string) ([]models.Beitian, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Beitian, error)
+	GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Beitian, error)
+	GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Beitian, error)
+	GetTrackByToken(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string) ([]models.Beitian, error)
+	GetDeviceTokens(ctx context.Context) ([]string, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(beitian models.Beitian) error) error
 }
 
-func NewBeitianRepository(Conn *gorm.DB) BeitianRepository {
-	return &dbBeitian{Conn: Conn}
+func NewBeitianRepository(Conn *gorm.DB, logger *slog.Logger) BeitianRepository {
+	return &dbBeitian{Conn: logging.Session(Conn, logger.With("repository", "beitian"))}
 }
@@ -1,148 +1,351 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	anomalyModels "iot-golang/internal/anomaly/models"
+	anomalyServices "iot-golang/internal/anomaly/services"
 	"iot-golang/internal/beitian/models"
 	"iot-golang/internal/beitian/repositories"
+	"iot-golang/internal/broadcast"
+	calibrationServices "iot-golang/internal/calibration/services"
+	geofenceServices "iot-golang/internal/geofence/services"
 	"iot-golang/internal/helpers"
-	"log"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"iot-golang/internal/tracing"
+	"log/slog"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type beitianService struct {
-	beitianRepo repositories.BeitianRepository
+	logger             *slog.Logger
+	beitianRepo        repositories.BeitianRepository
+	geofenceService    geofenceServices.GeofenceService
+	anomalyService     anomalyServices.AnomalyService
+	calibrationService calibrationServices.CalibrationService
 }
 
 // GetNewByToken implements BeitianService.
-func (service *beitianService) GetNewByToken(DeviceToken string) helpers.Response {
+func (service *beitianService) GetNewByToken(ctx context.Context, DeviceToken string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.GetNewByToken")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.beitianRepo.GetNewByToken(DeviceToken)
+	data, err := service.beitianRepo.GetNewByToken(ctx, DeviceToken)
 
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil data sensor dengan token : %s, error: %v", DeviceToken, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan token : %s", DeviceToken)
-	} else {
-		log.Printf("INFO: Tidak menemukan data sensor dengan token : %s", DeviceToken)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan token : %s", DeviceToken)
-	}
-
-	log.Printf("SUCCESS: Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-	response.Status = 200
-	response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan token : %s", DeviceToken)
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByTokenFail, DeviceToken), err)
+	}
+
+	if len(data) == 0 {
+		service.logger.InfoContext(ctx, "Tidak menemukan data sensor", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByToken, DeviceToken))
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorFoundByToken, DeviceToken)
 	response.Data = data
 
-	return response
+	return response, nil
 }
 
 // Create implements BeitianService.
-func (service *beitianService) Create(beitian models.Beitian) helpers.Response {
+func (service *beitianService) Create(ctx context.Context, beitian models.Beitian) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.Create")
+	defer span.End()
+
+	var response helpers.Response
+
+	// Ambil posisi terakhir sebelum data baru disimpan untuk evaluasi geofence
+	var previous *models.Beitian
+	if latest, err := service.beitianRepo.GetNewByToken(ctx, beitian.DeviceToken); err == nil && len(latest) > 0 {
+		previous = &latest[0]
+	}
+
+	raw, err := service.calibrationService.Calibrator(ctx, "beitian").Apply(beitian.DeviceToken, time.Now(), &beitian)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", beitian.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+	if raw != nil {
+		beitian.RawValues = raw
+		beitian.Quality = helpers.AssessQuality(models.Fields, &beitian).Quality
+	}
+
+	inspection := service.anomalyService.Inspect(ctx, "beitian", beitian.DeviceToken, beitian.Quality, helpers.FieldValues(models.Fields, beitian))
+	beitian.AnomalyScore = inspection.Score
+
+	if err := service.beitianRepo.Create(ctx, beitian); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "device_token", beitian.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspection)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "device_token", beitian.DeviceToken)
+	metrics.RecordIngested("beitian", beitian.DeviceToken, 1)
+	broadcast.Publish("beitian", beitian.DeviceToken, beitian)
+	response.SetMessage(ctx, i18n.SensorCreated)
+
+	// Posisi dengan kualitas bad tidak dipakai untuk evaluasi geofence
+	if beitian.Quality == helpers.QualityBad {
+		return response, nil
+	}
+
+	if events := service.geofenceService.Evaluate(ctx, previous, beitian); len(events) > 0 {
+		response.Data = events
+	}
+
+	return response, nil
+}
+
+// GetTrack implements BeitianService.
+// Jarak dan kecepatan dihitung antara dua posisi yang berurutan.
+func (service *beitianService) GetTrack(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.GetTrack")
+	defer span.End()
+
 	var response helpers.Response
-	if err := service.beitianRepo.Create(beitian); err != nil {
-		log.Printf("ERROR: Gagal membuat data sensor baru, error: %v", err)
-		response.Status = 500
-		response.Messages = "Gagal membuat data sensor baru"
-	} else {
-		log.Println("SUCCESS: Berhasil membuat data sensor baru")
-		response.Status = 201
-		response.Messages = "Berhasil membuat data sensor baru"
+	data, err := service.beitianRepo.GetTrackByToken(ctx, DeviceToken, Start, End, Quality)
+
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data track sensor", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorTrackFailed, DeviceToken), err)
+	}
+
+	collection := helpers.NewFeatureCollection()
+	var coordinates [][]float64
+	var totalDistance float64
+	var prevLat, prevLng float64
+	var prevTime time.Time
+
+	for _, deviceData := range data {
+		lat, lng, err := helpers.ParseCoordinate(deviceData.Latitude, deviceData.Longitude)
+		if err != nil {
+			continue
+		}
+
+		var distance, speed float64
+		if len(coordinates) > 0 {
+			distance = helpers.Haversine(prevLat, prevLng, lat, lng)
+			if elapsed := deviceData.CreatedAt.Sub(prevTime).Seconds(); elapsed > 0 {
+				speed = distance / elapsed
+			}
+			totalDistance += distance
+		}
+
+		collection.Features = append(collection.Features, helpers.NewPointFeature(lat, lng, map[string]interface{}{
+			"id":           deviceData.Id,
+			"created_at":   deviceData.CreatedAt,
+			"battery":      deviceData.Battery,
+			"distance_m":   distance,
+			"speed_mps":    speed,
+			"speed_kmh":    speed * 3.6,
+			"device_token": deviceData.DeviceToken,
+		}))
+
+		coordinates = append(coordinates, []float64{lng, lat})
+		prevLat, prevLng, prevTime = lat, lng, deviceData.CreatedAt
+	}
+
+	if len(coordinates) == 0 {
+		service.logger.InfoContext(ctx, "Tidak menemukan data track sensor", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorTrackNotFound, DeviceToken))
+	}
+
+	var averageSpeed float64
+	duration := prevTime.Sub(data[0].CreatedAt).Seconds()
+	if duration > 0 {
+		averageSpeed = totalDistance / duration
+	}
+
+	if len(coordinates) > 1 {
+		collection.Features = append([]helpers.GeoJSONFeature{helpers.NewLineStringFeature(coordinates, map[string]interface{}{
+			"device_token":      DeviceToken,
+			"start":             Start,
+			"end":               End,
+			"points":            len(coordinates),
+			"distance_m":        totalDistance,
+			"duration_s":        duration,
+			"average_speed_mps": averageSpeed,
+			"average_speed_kmh": averageSpeed * 3.6,
+		})}, collection.Features...)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data track sensor", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorTrackFound, DeviceToken)
+	response.Data = collection
+
+	return response, nil
+}
+
+// CreateBatch implements BeitianService.
+func (service *beitianService) CreateBatch(ctx context.Context, beitian []models.Beitian) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.CreateBatch")
+	defer span.End()
+
+	var response helpers.Response
+	calibrator := service.calibrationService.Calibrator(ctx, "beitian")
+	now := time.Now()
+	tokens := make([]string, len(beitian))
+	readings := make([]anomalyModels.Reading, len(beitian))
+	for i := range beitian {
+		tokens[i] = beitian[i].DeviceToken
+		// Data tanpa created_at akan diberi waktu sekarang oleh database
+		at := beitian[i].CreatedAt
+		if at.IsZero() {
+			at = now
+		}
+		raw, err := calibrator.Apply(beitian[i].DeviceToken, at, &beitian[i])
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", beitian[i].DeviceToken, logging.Error(err))
+			return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+		}
+		if raw != nil {
+			beitian[i].RawValues = raw
+			beitian[i].Quality = helpers.AssessQuality(models.Fields, &beitian[i]).Quality
+		}
+		readings[i] = anomalyModels.Reading{DeviceToken: beitian[i].DeviceToken, Quality: beitian[i].Quality, Values: helpers.FieldValues(models.Fields, beitian[i]), At: beitian[i].CreatedAt}
+	}
+
+	inspections := service.anomalyService.InspectBatch(ctx, "beitian", readings)
+	for i := range inspections {
+		beitian[i].AnomalyScore = inspections[i].Score
 	}
 
-	return response
+	if err := service.beitianRepo.CreateBatch(ctx, beitian, len(beitian)); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "count", len(beitian), "device_tokens", helpers.DistinctTokens(tokens), logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspections...)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "count", len(beitian), "device_tokens", helpers.DistinctTokens(tokens))
+	for _, reading := range beitian {
+		metrics.RecordIngested("beitian", reading.DeviceToken, 1)
+		broadcast.Publish("beitian", reading.DeviceToken, reading)
+	}
+	response.SetMessage(ctx, i18n.SensorCreated)
+
+	return response, nil
 }
 
 // Delete implements BeitianService.
-func (service *beitianService) Delete(Id int64) helpers.Response {
+func (service *beitianService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.Delete")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum dihapus
-	_, findErr := service.beitianRepo.GetById(Id)
+	_, findErr := service.beitianRepo.GetById(ctx, Id)
 	if findErr != nil {
-		if findErr == gorm.ErrRecordNotFound {
-			log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d", Id)
-			response.Status = 404
-			response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		} else {
-			log.Printf("ERROR: Gagal mengambil data sensor dengan id : %d, error: %v", Id, findErr)
-			response.Status = 500
-			response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan id : %d", Id)
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
 		}
-		return response
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
 	}
 
 	// Data ditemukan, lakukan penghapusan
-	err := service.beitianRepo.Delete(Id)
+	err := service.beitianRepo.Delete(ctx, Id)
 	if err != nil {
-		log.Printf("ERROR: Gagal menghapus data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal menghapus data sensor dengan id : %d", Id)
-	} else {
-		// Jika penghapusan berhasil
-		log.Println("SUCCESS: Data sensor berhasil dihapus")
-		response.Status = 200
-		response.Messages = "Data sensor berhasil dihapus"
+		service.logger.ErrorContext(ctx, "Gagal menghapus data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorDeleteFailed, Id), err)
 	}
 
-	return response
+	// Jika penghapusan berhasil
+	service.logger.InfoContext(ctx, "Data sensor berhasil dihapus")
+	response.SetMessage(ctx, i18n.SensorDeleted)
+
+	return response, nil
 }
 
 // GetAll implements BeitianService.
-func (service *beitianService) GetAll() helpers.Response {
+func (service *beitianService) GetAll(ctx context.Context, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.GetAll")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.beitianRepo.GetAll()
+	data, err := service.beitianRepo.GetAll(ctx, Quality)
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil seluruh data sensor : error %v", err)
-		response.Status = 500
-		response.Messages = "Gagal mengambil seluruh data sensor"
-	} else {
-		log.Println("SUCCESS: Berhasil mengambil semua data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengambil semua data sensor"
-		response.Data = data
-	}
-	return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data sensor", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil semua data sensor")
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
+}
+
+// Query implements BeitianService.
+// Data yang kosong tetap dikembalikan sebagai daftar kosong.
+func (service *beitianService) Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.Query")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.beitianRepo.Query(ctx, Query)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", Query.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+	if data == nil {
+		data = []models.Beitian{}
+	}
+
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
 }
 
 // GetById implements BeitianService.
-func (service *beitianService) GetById(Id int64) helpers.Response {
+func (service *beitianService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.GetById")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.beitianRepo.GetById(Id)
+	data, err := service.beitianRepo.GetById(ctx, Id)
 
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d", Id)
-			response.Status = 404
-			response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		} else {
-			log.Printf("ERROR: Gagal mengambil data sensor dengan id : %d, error: %v", Id, err)
-			response.Status = 500
-			response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan id : %d", Id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
 		}
-	} else {
-		battery, _ := strconv.ParseFloat(data.Battery, 64)
-		data.Battery = fmt.Sprintf("%.2f", battery)
 
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Data = data
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
 	}
 
-	return response
+	battery, _ := strconv.ParseFloat(data.Battery, 64)
+	data.Battery = fmt.Sprintf("%.2f", battery)
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "id", Id)
+	response.SetMessage(ctx, i18n.SensorFoundByID, Id)
+	response.Data = data
+
+	return response, nil
 }
 
-func (service *beitianService) GetByToken(DeviceToken string) helpers.Response {
+func (service *beitianService) GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.GetByToken")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.beitianRepo.GetByToken(DeviceToken)
+	data, err := service.beitianRepo.GetByToken(ctx, DeviceToken, Quality)
 
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil data sensor dengan token : %s, error: %v", DeviceToken, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan token : %s", DeviceToken)
-		return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByTokenFail, DeviceToken), err)
 	}
 
 	var foundData []models.Beitian
@@ -156,63 +359,121 @@ func (service *beitianService) GetByToken(DeviceToken string) helpers.Response {
 	}
 
 	if len(foundData) == 0 {
-		log.Printf("INFO: Tidak menemukan data sensor dengan token : %s", DeviceToken)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan token : %s", DeviceToken)
-	} else {
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Data = foundData
+		service.logger.InfoContext(ctx, "Tidak menemukan data sensor", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByToken, DeviceToken))
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorFoundByToken, DeviceToken)
+	response.Data = foundData
+
+	return response, nil
 }
 
 // Update implements BeitianService.
-func (service *beitianService) Update(Id int64, beitian models.Beitian) helpers.Response {
+func (service *beitianService) Update(ctx context.Context, Id int64, beitian models.Beitian) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BeitianService.Update")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum di update
-	_, findErr := service.beitianRepo.GetById(Id)
+	current, findErr := service.beitianRepo.GetById(ctx, Id)
 	if findErr != nil {
-		if findErr == gorm.ErrRecordNotFound {
-			log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d", Id)
-			response.Status = 404
-			response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		} else {
-			log.Printf("ERROR: Gagal mengambil data sensor dengan id : %d, error: %v", Id, findErr)
-			response.Status = 500
-			response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan id : %d", Id)
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
 		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
+	}
+
+	// Nilai baru dikalibrasi dengan kalibrasi yang berlaku pada waktu data
+	// tersebut dibuat, sama seperti saat data disimpan pertama kali
+	raw, err := service.calibrationService.Calibrator(ctx, "beitian").Apply(current.DeviceToken, current.CreatedAt, &beitian)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", current.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+	beitian.RawValues = raw
+	if raw != nil {
+		beitian.Quality = helpers.AssessQuality(models.Fields, &beitian).Quality
 	}
 
 	// Data ditemukan, lakukan update
-	err := service.beitianRepo.Update(Id, beitian)
+	err = service.beitianRepo.Update(ctx, Id, beitian)
 	if err != nil {
-		log.Printf("ERROR: Gagal mengubah data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengubah data sensor dengan id : %d", Id)
-	} else {
-		// Jika update data berhasil
-		log.Println("SUCCESS: Berhasil mengubah data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengubah data sensor"
+		service.logger.ErrorContext(ctx, "Gagal mengubah data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
 	}
 
-	return response
+	// Jika update data berhasil
+	service.logger.InfoContext(ctx, "Berhasil mengubah data sensor")
+	response.SetMessage(ctx, i18n.SensorUpdated)
+
+	return response, nil
+}
+
+// Export implements BeitianService.
+func (service *beitianService) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "BeitianService.Export")
+	defer span.End()
+
+	// Writer baru dibuat setelah baris pertama terbaca sehingga kegagalan
+	// query tidak membuat header response terkirim lebih dulu.
+	var writer helpers.ExportWriter
+	open := func() (err error) {
+		if writer != nil {
+			return nil
+		}
+		if writer, err = helpers.NewExportWriter(Format, w, "beitian"); err != nil {
+			return err
+		}
+		return writer.WriteRow(helpers.ExportHeaders(Units.Fields()))
+	}
+
+	count := 0
+	err := service.beitianRepo.Export(ctx, DeviceToken, Start, End, Quality, func(data models.Beitian) error {
+		if err := open(); err != nil {
+			return err
+		}
+		count++
+		Units.Apply(&data)
+		return writer.WriteRow([]string{strconv.FormatInt(data.Id, 10), data.DeviceToken, data.CreatedAt.Format(time.RFC3339), data.Latitude, data.Longitude, data.Battery, data.Quality})
+	})
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengekspor data sensor", "device_token", DeviceToken, logging.Error(err))
+		return err
+	}
+	if err := open(); err != nil {
+		return err
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengekspor data sensor", "count", count, "device_token", DeviceToken)
+	return writer.Close()
 }
 
 type BeitianService interface {
-	Create(beitian models.Beitian) helpers.Response
-	Update(Id int64, beitian models.Beitian) helpers.Response
-	Delete(Id int64) helpers.Response
-	GetById(Id int64) helpers.Response
-	GetByToken(DeviceToken string) helpers.Response
-	GetAll() helpers.Response
-	GetNewByToken(DeviceToken string) helpers.Response
+	Create(ctx context.Context, beitian models.Beitian) (helpers.Response, error)
+	CreateBatch(ctx context.Context, beitian []models.Beitian) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, beitian models.Beitian) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error)
+	GetAll(ctx context.Context, Quality []string) (helpers.Response, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error)
+	GetNewByToken(ctx context.Context, DeviceToken string) (helpers.Response, error)
+	GetTrack(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string) (helpers.Response, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error
 }
 
-func NewBeitianService(db *gorm.DB) BeitianService {
-	return &beitianService{beitianRepo: repositories.NewBeitianRepository(db)}
+func NewBeitianService(db *gorm.DB, logger *slog.Logger) BeitianService {
+	return &beitianService{
+		logger:             logger.With("sensor", "beitian"),
+		beitianRepo:        repositories.NewBeitianRepository(db, logger),
+		geofenceService:    geofenceServices.NewGeofenceService(db, logger),
+		anomalyService:     anomalyServices.NewAnomalyService(db, logger),
+		calibrationService: calibrationServices.NewCalibrationService(db, logger),
+	}
 }
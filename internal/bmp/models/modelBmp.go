@@ -1,13 +1,30 @@
 package models
 
+import (
+	"iot-golang/internal/helpers"
+	"time"
+)
+
 type Bmp struct {
-	Id              int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	DeviceToken     string `json:"device_token" gorm:"column:device_token"`
-	TekananUdara    string `json:"tekanan_udara" gorm:"column:tekanan_udara"`
-	TinggiPermukaan string `json:"tinggi_permukaan" gorm:"column:tinggi_permukaan"`
-	Battery         string `json:"battery" gorm:"column:battery"`
+	Id              int64             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken     string            `json:"device_token" gorm:"column:device_token"`
+	TekananUdara    string            `json:"tekanan_udara" gorm:"column:tekanan_udara"`
+	TinggiPermukaan string            `json:"tinggi_permukaan" gorm:"column:tinggi_permukaan"`
+	Battery         string            `json:"battery" gorm:"column:battery"`
+	Quality         string            `json:"quality" gorm:"column:quality;default:good"`
+	AnomalyScore    float64           `json:"anomaly_score" gorm:"column:anomaly_score;default:0"`
+	RawValues       map[string]string `json:"raw_values,omitempty" gorm:"column:raw_values;serializer:json"`
+	CreatedAt       time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 }
 
 func (Bmp) TableName() string {
 	return "db_sensor_bmp180"
 }
+
+// Fields berisi metadata field sensor bmp beserta satuan, rentang nilai dan
+// batas perubahan per menitnya.
+var Fields = []helpers.Field{
+	{Name: "tekanan_udara", Column: "tekanan_udara", Unit: "hPa", Range: helpers.Between(300, 1100).Suspect(870, 1085), MaxRate: 5},
+	{Name: "tinggi_permukaan", Column: "tinggi_permukaan", Unit: "m", Range: helpers.Between(-500, 9000), MaxRate: 50},
+	{Name: "battery", Column: "battery", Unit: "V", Range: helpers.Between(0, 12).Suspect(3, 4.5), MaxRate: 0.5},
+}
@@ -0,0 +1,26 @@
+package models
+
+// CreatePayload adalah payload untuk membuat data sensor bmp baru.
+// Aturan validasi yang sama dipakai oleh endpoint create dan import CSV.
+type CreatePayload struct {
+	DeviceToken     string `json:"device_token" validate:"required"`
+	TekananUdara    string `json:"tekanan_udara" validate:"required,numeric"`
+	TinggiPermukaan string `json:"tinggi_permukaan" validate:"required,numeric"`
+	Battery         string `json:"battery" validate:"required,numeric"`
+}
+
+func (payload CreatePayload) ToModel() Bmp {
+	return Bmp{
+		DeviceToken:     payload.DeviceToken,
+		TekananUdara:    payload.TekananUdara,
+		TinggiPermukaan: payload.TinggiPermukaan,
+		Battery:         payload.Battery,
+	}
+}
+
+// UpdatePayload adalah payload untuk mengubah data sensor bmp.
+type UpdatePayload struct {
+	TekananUdara    string `json:"tekanan_udara" validate:"required,numeric"`
+	TinggiPermukaan string `json:"tinggi_permukaan" validate:"required,numeric"`
+	Battery         string `json:"battery" validate:"required,numeric"`
+}
@@ -1,7 +1,13 @@
 package repositories
 
 import (
+	"context"
 	"iot-golang/internal/bmp/models"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,50 +17,118 @@ type dbBmp struct {
 }
 
 // Create implements BmpRepository.
-func (db *dbBmp) Create(bmp models.Bmp) error {
-	return db.Conn.Create(&bmp).Error
+func (db *dbBmp) Create(ctx context.Context, bmp models.Bmp) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "Create")).Create(&bmp).Error
+}
+
+// CreateBatch implements BmpRepository.
+func (db *dbBmp) CreateBatch(ctx context.Context, bmp []models.Bmp, BatchSize int) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "CreateBatch")).CreateInBatches(&bmp, BatchSize).Error
 }
 
 // Delete implements BmpRepository.
-func (db *dbBmp) Delete(Id int64) error {
-	return db.Conn.Delete(&models.Bmp{Id: Id}).Error
+func (db *dbBmp) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "Delete")).Delete(&models.Bmp{Id: Id}).Error
 }
 
 // GetAll implements BmpRepository.
-func (db *dbBmp) GetAll() ([]models.Bmp, error) {
+func (db *dbBmp) GetAll(ctx context.Context, Quality []string) ([]models.Bmp, error) {
+	var data []models.Bmp
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "GetAll")).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// Query implements BmpRepository.
+func (db *dbBmp) Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Bmp, error) {
 	var data []models.Bmp
-	result := db.Conn.Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "Query")).Scopes(Query.Scope).Find(&data)
 	return data, result.Error
 }
 
 // GetById implements BmpRepository.
-func (db *dbBmp) GetById(Id int64) (models.Bmp, error) {
+func (db *dbBmp) GetById(ctx context.Context, Id int64) (models.Bmp, error) {
 	var data models.Bmp
-	result := db.Conn.Where("id", Id).First(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "GetById")).Where("id", Id).First(&data)
 	return data, result.Error
 }
 
 // GetByToken implements BmpRepository.
-func (db *dbBmp) GetByToken(DeviceToken string) ([]models.Bmp, error) {
+func (db *dbBmp) GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Bmp, error) {
+	var data []models.Bmp
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "GetByToken")).Where("device_token", DeviceToken).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// GetNewByToken implements BmpRepository.
+func (db *dbBmp) GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Bmp, error) {
 	var data []models.Bmp
-	result := db.Conn.Where("device_token", DeviceToken).Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "GetNewByToken")).Where("device_token = ?", DeviceToken).Order("id desc").Limit(1).Find(&data)
 	return data, result.Error
 }
 
+// GetNewByTokens implements BmpRepository.
+// Data terbaru setiap perangkat diambil dalam satu query dengan subquery id
+// terbesar per device_token.
+func (db *dbBmp) GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Bmp, error) {
+	var data []models.Bmp
+	latest := db.Conn.Model(&models.Bmp{}).Select("MAX(id)").Where("device_token IN ?", DeviceTokens).Group("device_token")
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "GetNewByTokens")).Where("id IN (?)", latest).Find(&data)
+	return data, result.Error
+}
+
+// Export implements BmpRepository.
+// Data dibaca memakai cursor Rows sehingga tidak dimuat seluruhnya ke memori.
+func (db *dbBmp) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(bmp models.Bmp) error) error {
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "Export")).Model(&models.Bmp{}).Where("created_at >= ? AND created_at < ?", Start, End).Scopes(helpers.QualityScope(Quality))
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+
+	rows, err := query.Order("created_at asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data models.Bmp
+		if err := db.Conn.ScanRows(rows, &data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 // Update implements BmpRepository.
-func (db *dbBmp) Update(Id int64, bmp models.Bmp) error {
-	return db.Conn.Where("id", Id).Updates(bmp).Error
+// Kolom nilai, quality dan raw_values selalu ditulis, termasuk raw_values yang
+// kosong, agar nilai mentah lama tidak tertinggal setelah data diubah.
+func (db *dbBmp) Update(ctx context.Context, Id int64, bmp models.Bmp) error {
+	columns := []string{"quality", "raw_values"}
+	for _, field := range models.Fields {
+		columns = append(columns, field.Column)
+	}
+
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "bmp", "Update")).Model(&models.Bmp{}).Where("id", Id).Select(columns).Updates(bmp).Error
 }
 
 type BmpRepository interface {
-	Create(bmp models.Bmp) error
-	Update(Id int64, bmp models.Bmp) error
-	Delete(Id int64) error
-	GetById(Id int64) (models.Bmp, error)
-	GetByToken(DeviceToken string) ([]models.Bmp, error)
-	GetAll() ([]models.Bmp, error)
+	Create(ctx context.Context, bmp models.Bmp) error
+	CreateBatch(ctx context.Context, bmp []models.Bmp, BatchSize int) error
+	Update(ctx context.Context, Id int64, bmp models.Bmp) error
+	Delete(ctx context.Context, Id int64) error
+	GetById(ctx context.Context, Id int64) (models.Bmp, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Bmp, error)
+	GetAll(ctx context.Context, Quality []string) ([]models.Bmp, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Bmp, error)
+	GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Bmp, error)
+	GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Bmp, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(bmp models.Bmp) error) error
 }
 
-func NewBmpRepository(Conn *gorm.DB) BmpRepository {
-	return &dbBmp{Conn: Conn}
+func NewBmpRepository(Conn *gorm.DB, logger *slog.Logger) BmpRepository {
+	return &dbBmp{Conn: logging.Session(Conn, logger.With("repository", "bmp"))}
 }
@@ -1,125 +1,234 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	anomalyModels "iot-golang/internal/anomaly/models"
+	anomalyServices "iot-golang/internal/anomaly/services"
 	"iot-golang/internal/bmp/models"
 	"iot-golang/internal/bmp/repositories"
+	"iot-golang/internal/broadcast"
+	calibrationServices "iot-golang/internal/calibration/services"
 	"iot-golang/internal/helpers"
-	"log"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"iot-golang/internal/tracing"
+	"log/slog"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type bmpService struct {
-	bmpRepo repositories.BmpRepository
+	logger             *slog.Logger
+	bmpRepo            repositories.BmpRepository
+	anomalyService     anomalyServices.AnomalyService
+	calibrationService calibrationServices.CalibrationService
 }
 
 // Create implements BmpService.
-func (service *bmpService) Create(bmp models.Bmp) helpers.Response {
+func (service *bmpService) Create(ctx context.Context, bmp models.Bmp) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BmpService.Create")
+	defer span.End()
+
 	var response helpers.Response
-	if err := service.bmpRepo.Create(bmp); err != nil {
-		log.Println("ERROR: Gagal membuat data sensor baru, error :" + err.Error())
-		response.Status = 500
-		response.Messages = "Gagal membuat data sensor baru"
-	} else {
-		log.Println("SUCCESS: Berhasil membuat data sensor baru")
-		response.Status = 201
-		response.Messages = "Berhasil membuat data sensor baru"
-	}
-	return response
+	raw, err := service.calibrationService.Calibrator(ctx, "bmp").Apply(bmp.DeviceToken, time.Now(), &bmp)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", bmp.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+	if raw != nil {
+		bmp.RawValues = raw
+		bmp.Quality = helpers.AssessQuality(models.Fields, &bmp).Quality
+	}
+
+	inspection := service.anomalyService.Inspect(ctx, "bmp", bmp.DeviceToken, bmp.Quality, helpers.FieldValues(models.Fields, bmp))
+	bmp.AnomalyScore = inspection.Score
+
+	if err := service.bmpRepo.Create(ctx, bmp); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "device_token", bmp.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspection)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "device_token", bmp.DeviceToken)
+	metrics.RecordIngested("bmp", bmp.DeviceToken, 1)
+	broadcast.Publish("bmp", bmp.DeviceToken, bmp)
+	response.SetMessage(ctx, i18n.SensorCreated)
+	return response, nil
+}
+
+// CreateBatch implements BmpService.
+func (service *bmpService) CreateBatch(ctx context.Context, bmp []models.Bmp) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BmpService.CreateBatch")
+	defer span.End()
+
+	var response helpers.Response
+	calibrator := service.calibrationService.Calibrator(ctx, "bmp")
+	now := time.Now()
+	tokens := make([]string, len(bmp))
+	readings := make([]anomalyModels.Reading, len(bmp))
+	for i := range bmp {
+		tokens[i] = bmp[i].DeviceToken
+		// Data tanpa created_at akan diberi waktu sekarang oleh database
+		at := bmp[i].CreatedAt
+		if at.IsZero() {
+			at = now
+		}
+		raw, err := calibrator.Apply(bmp[i].DeviceToken, at, &bmp[i])
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", bmp[i].DeviceToken, logging.Error(err))
+			return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+		}
+		if raw != nil {
+			bmp[i].RawValues = raw
+			bmp[i].Quality = helpers.AssessQuality(models.Fields, &bmp[i]).Quality
+		}
+		readings[i] = anomalyModels.Reading{DeviceToken: bmp[i].DeviceToken, Quality: bmp[i].Quality, Values: helpers.FieldValues(models.Fields, bmp[i]), At: bmp[i].CreatedAt}
+	}
+
+	inspections := service.anomalyService.InspectBatch(ctx, "bmp", readings)
+	for i := range inspections {
+		bmp[i].AnomalyScore = inspections[i].Score
+	}
+
+	if err := service.bmpRepo.CreateBatch(ctx, bmp, len(bmp)); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "count", len(bmp), "device_tokens", helpers.DistinctTokens(tokens), logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspections...)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "count", len(bmp), "device_tokens", helpers.DistinctTokens(tokens))
+	for _, reading := range bmp {
+		metrics.RecordIngested("bmp", reading.DeviceToken, 1)
+		broadcast.Publish("bmp", reading.DeviceToken, reading)
+	}
+	response.SetMessage(ctx, i18n.SensorCreated)
+
+	return response, nil
 }
 
 // Delete implements BmpService.
-func (service *bmpService) Delete(Id int64) helpers.Response {
+func (service *bmpService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BmpService.Delete")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum dihapus
-	_, findErr := service.bmpRepo.GetById(Id)
+	_, findErr := service.bmpRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
 	}
 
 	// Data ditemukan, lakukan penghapusan
-	err := service.bmpRepo.Delete(Id)
+	err := service.bmpRepo.Delete(ctx, Id)
 	if err != nil {
-		log.Printf("ERROR: Gagal menghapus data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal menghapus data sensor dengan id : %d", Id)
-	} else {
-		// Jika penghapusan berhasil
-		log.Println("SUCCESS: Data sensor berhasil dihapus")
-		response.Status = 200
-		response.Messages = "Data sensor berhasil dihapus"
+		service.logger.ErrorContext(ctx, "Gagal menghapus data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorDeleteFailed, Id), err)
 	}
 
-	return response
+	// Jika penghapusan berhasil
+	service.logger.InfoContext(ctx, "Data sensor berhasil dihapus")
+	response.SetMessage(ctx, i18n.SensorDeleted)
+
+	return response, nil
 }
 
 // GetAll implements BmpService.
-func (service *bmpService) GetAll() helpers.Response {
+func (service *bmpService) GetAll(ctx context.Context, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BmpService.GetAll")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.bmpRepo.GetAll()
+	data, err := service.bmpRepo.GetAll(ctx, Quality)
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil seluruh data sensor : error %v", err)
-		response.Status = 500
-		response.Messages = "Gagal mengambil seluruh data sensor"
-	} else {
-		log.Println("SUCCESS: Berhasil mengambil semua data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengambil semua data sensor"
-		response.Data = data
-	}
-	return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data sensor", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil semua data sensor")
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
+}
+
+// Query implements BmpService.
+// Data yang kosong tetap dikembalikan sebagai daftar kosong.
+func (service *bmpService) Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BmpService.Query")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.bmpRepo.Query(ctx, Query)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", Query.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+	if data == nil {
+		data = []models.Bmp{}
+	}
+
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
 }
 
 // GetById implements BmpService.
-func (service *bmpService) GetById(Id int64) helpers.Response {
+func (service *bmpService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BmpService.GetById")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.bmpRepo.GetById(Id)
+	data, err := service.bmpRepo.GetById(ctx, Id)
 
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d", Id)
-			response.Status = 404
-			response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		} else {
-			log.Printf("ERROR: Gagal mengambil data sensor dengan id : %d, error: %v", Id, err)
-			response.Status = 500
-			response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan id : %d", Id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
 		}
-	} else {
-		// Konversi string ke tipe data float64
-		TekananUdara, _ := strconv.ParseFloat(data.TekananUdara, 64)
-		TinggiPermukaan, _ := strconv.ParseFloat(data.TinggiPermukaan, 64)
-		battery, _ := strconv.ParseFloat(data.Battery, 64)
 
-		data.TekananUdara = fmt.Sprintf("%.2f", TekananUdara)
-		data.TinggiPermukaan = fmt.Sprintf("%.2f", TinggiPermukaan)
-		data.Battery = fmt.Sprintf("%.2f", battery)
-
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Data = data
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
 	}
 
-	return response
+	// Konversi string ke tipe data float64
+	TekananUdara, _ := strconv.ParseFloat(data.TekananUdara, 64)
+	TinggiPermukaan, _ := strconv.ParseFloat(data.TinggiPermukaan, 64)
+	battery, _ := strconv.ParseFloat(data.Battery, 64)
+
+	data.TekananUdara = fmt.Sprintf("%.2f", TekananUdara)
+	data.TinggiPermukaan = fmt.Sprintf("%.2f", TinggiPermukaan)
+	data.Battery = fmt.Sprintf("%.2f", battery)
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "id", Id)
+	response.SetMessage(ctx, i18n.SensorFoundByID, Id)
+	response.Data = data
+
+	return response, nil
 }
 
 // GetByToken implements BmpService.
-func (service *bmpService) GetByToken(DeviceToken string) helpers.Response {
+func (service *bmpService) GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BmpService.GetByToken")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.bmpRepo.GetByToken(DeviceToken)
+	data, err := service.bmpRepo.GetByToken(ctx, DeviceToken, Quality)
 
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil data sensor dengan token : %s, error: %v", DeviceToken, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan token : %s", DeviceToken)
-		return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByTokenFail, DeviceToken), err)
 	}
 
 	var foundData []models.Bmp
@@ -138,57 +247,113 @@ func (service *bmpService) GetByToken(DeviceToken string) helpers.Response {
 	}
 
 	if len(foundData) == 0 {
-		log.Printf("INFO: Tidak menemukan data sensor dengan token : %s", DeviceToken)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan token : %s", DeviceToken)
-	} else {
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Data = foundData
+		service.logger.InfoContext(ctx, "Tidak menemukan data sensor", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByToken, DeviceToken))
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorFoundByToken, DeviceToken)
+	response.Data = foundData
+
+	return response, nil
 }
 
 // Update implements BmpService.
-func (service *bmpService) Update(Id int64, bmp models.Bmp) helpers.Response {
+func (service *bmpService) Update(ctx context.Context, Id int64, bmp models.Bmp) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "BmpService.Update")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum di update
-	_, findErr := service.bmpRepo.GetById(Id)
+	current, findErr := service.bmpRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
+	}
+
+	// Nilai baru dikalibrasi dengan kalibrasi yang berlaku pada waktu data
+	// tersebut dibuat, sama seperti saat data disimpan pertama kali
+	raw, err := service.calibrationService.Calibrator(ctx, "bmp").Apply(current.DeviceToken, current.CreatedAt, &bmp)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", current.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+	bmp.RawValues = raw
+	if raw != nil {
+		bmp.Quality = helpers.AssessQuality(models.Fields, &bmp).Quality
 	}
 
 	// Data ditemukan, lakukan update
-	err := service.bmpRepo.Update(Id, bmp)
+	err = service.bmpRepo.Update(ctx, Id, bmp)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengubah data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+
+	// Jika update data berhasil
+	service.logger.InfoContext(ctx, "Berhasil mengubah data sensor")
+	response.SetMessage(ctx, i18n.SensorUpdated)
+
+	return response, nil
+}
+
+// Export implements BmpService.
+func (service *bmpService) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "BmpService.Export")
+	defer span.End()
+
+	// Writer baru dibuat setelah baris pertama terbaca sehingga kegagalan
+	// query tidak membuat header response terkirim lebih dulu.
+	var writer helpers.ExportWriter
+	open := func() (err error) {
+		if writer != nil {
+			return nil
+		}
+		if writer, err = helpers.NewExportWriter(Format, w, "bmp"); err != nil {
+			return err
+		}
+		return writer.WriteRow(helpers.ExportHeaders(Units.Fields()))
+	}
+
+	count := 0
+	err := service.bmpRepo.Export(ctx, DeviceToken, Start, End, Quality, func(data models.Bmp) error {
+		if err := open(); err != nil {
+			return err
+		}
+		count++
+		Units.Apply(&data)
+		return writer.WriteRow([]string{strconv.FormatInt(data.Id, 10), data.DeviceToken, data.CreatedAt.Format(time.RFC3339), data.TekananUdara, data.TinggiPermukaan, data.Battery, data.Quality})
+	})
 	if err != nil {
-		log.Printf("ERROR: Gagal mengubah data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengubah data sensor dengan id : %d", Id)
-	} else {
-		// Jika update data berhasil
-		log.Println("SUCCESS: Berhasil mengubah data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengubah data sensor"
+		service.logger.ErrorContext(ctx, "Gagal mengekspor data sensor", "device_token", DeviceToken, logging.Error(err))
+		return err
+	}
+	if err := open(); err != nil {
+		return err
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengekspor data sensor", "count", count, "device_token", DeviceToken)
+	return writer.Close()
 }
 
 type BmpService interface {
-	Create(bmp models.Bmp) helpers.Response
-	Update(Id int64, bmp models.Bmp) helpers.Response
-	Delete(Id int64) helpers.Response
-	GetById(Id int64) helpers.Response
-	GetByToken(DeviceToken string) helpers.Response
-	GetAll() helpers.Response
+	Create(ctx context.Context, bmp models.Bmp) (helpers.Response, error)
+	CreateBatch(ctx context.Context, bmp []models.Bmp) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, bmp models.Bmp) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error)
+	GetAll(ctx context.Context, Quality []string) (helpers.Response, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error
 }
 
-func NewBmpService(db *gorm.DB) BmpService {
-	return &bmpService{bmpRepo: repositories.NewBmpRepository(db)}
+func NewBmpService(db *gorm.DB, logger *slog.Logger) BmpService {
+	return &bmpService{logger: logger.With("sensor", "bmp"), bmpRepo: repositories.NewBmpRepository(db, logger), anomalyService: anomalyServices.NewAnomalyService(db, logger), calibrationService: calibrationServices.NewCalibrationService(db, logger)}
 }
@@ -0,0 +1,125 @@
+package broadcast
+
+import (
+	"sync"
+	"time"
+)
+
+// BufferSize adalah jumlah event yang ditampung per subscriber. Event untuk
+// subscriber yang lambat dibuang agar penyimpanan data sensor tidak tertahan.
+var BufferSize = 256
+
+// Event adalah satu data sensor yang baru disimpan.
+type Event struct {
+	Sensor      string
+	DeviceToken string
+	Reading     interface{}
+	Time        time.Time
+}
+
+// Filter menyaring event per jenis sensor dan token perangkat. Daftar kosong
+// berarti semua.
+type Filter struct {
+	Sensors      []string
+	DeviceTokens []string
+}
+
+func (filter Filter) match(event Event) bool {
+	return contains(filter.Sensors, event.Sensor) && contains(filter.DeviceTokens, event.DeviceToken)
+}
+
+func contains(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription menerima event yang cocok dengan filter lewat channel C sampai
+// Close dipanggil.
+type Subscription struct {
+	C <-chan Event
+
+	hub     *Hub
+	events  chan Event
+	filter  Filter
+	dropped int
+}
+
+// Dropped mengembalikan jumlah event yang dibuang sejak pemanggilan terakhir.
+func (subscription *Subscription) Dropped() int {
+	subscription.hub.mutex.Lock()
+	defer subscription.hub.mutex.Unlock()
+
+	dropped := subscription.dropped
+	subscription.dropped = 0
+	return dropped
+}
+
+// Close berhenti menerima event dan menutup channel C.
+func (subscription *Subscription) Close() {
+	subscription.hub.mutex.Lock()
+	defer subscription.hub.mutex.Unlock()
+
+	if _, ok := subscription.hub.subscriptions[subscription]; ok {
+		delete(subscription.hub.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+// Hub meneruskan event ke setiap subscription di dalam proses yang sama.
+type Hub struct {
+	mutex         sync.Mutex
+	subscriptions map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscriptions: map[*Subscription]struct{}{}}
+}
+
+// Subscribe mendaftarkan subscription baru dengan filter.
+func (hub *Hub) Subscribe(filter Filter) *Subscription {
+	events := make(chan Event, BufferSize)
+	subscription := &Subscription{C: events, hub: hub, events: events, filter: filter}
+
+	hub.mutex.Lock()
+	hub.subscriptions[subscription] = struct{}{}
+	hub.mutex.Unlock()
+
+	return subscription
+}
+
+// Publish mengirim event ke setiap subscription yang cocok tanpa menunggu.
+func (hub *Hub) Publish(event Event) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for subscription := range hub.subscriptions {
+		if !subscription.filter.match(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.dropped++
+		}
+	}
+}
+
+// Default adalah hub yang dipakai service sensor.
+var Default = NewHub()
+
+// Publish mengirim data sensor yang baru disimpan ke subscriber hub Default.
+func Publish(sensor string, deviceToken string, reading interface{}) {
+	Default.Publish(Event{Sensor: sensor, DeviceToken: deviceToken, Reading: reading, Time: time.Now()})
+}
+
+// Subscribe mendaftarkan subscription pada hub Default.
+func Subscribe(filter Filter) *Subscription {
+	return Default.Subscribe(filter)
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"iot-golang/internal/calibration/models"
+	"iot-golang/internal/calibration/services"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/sensors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	v1 "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type CalibrationController struct {
+	calibrationService services.CalibrationService
+	validate           v1.Validate
+}
+
+func (controller CalibrationController) Create(c echo.Context) error {
+	payloadValidator := new(models.CreatePayload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	if errorList := controller.validationErrors(c, payloadValidator); len(errorList) > 0 {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	sensor, ok := sensors.Find(payloadValidator.Sensor)
+	if !ok {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"sensor": i18n.T(c.Request().Context(), i18n.SensorUnknown, payloadValidator.Sensor)})
+	}
+	if !hasField(sensor, payloadValidator.Field) {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"field": i18n.T(c.Request().Context(), i18n.CalibrationFieldUnknown, sensor.Name, payloadValidator.Field)})
+	}
+
+	validFrom, err := parseValidFrom(payloadValidator.ValidFrom)
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"valid_from": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	result, err := controller.calibrationService.Create(c.Request().Context(), models.Calibration{Sensor: sensor.Name, DeviceToken: payloadValidator.DeviceToken, Field: payloadValidator.Field, Offset: payloadValidator.Offset, Gain: gain(payloadValidator.Gain), ValidFrom: validFrom})
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusCreated)
+}
+
+func (controller CalibrationController) Update(c echo.Context) error {
+	payloadValidator := new(models.UpdatePayload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	if errorList := controller.validationErrors(c, payloadValidator); len(errorList) > 0 {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	validFrom, err := parseValidFrom(payloadValidator.ValidFrom)
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"valid_from": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	idCalibration, _ := strconv.Atoi(c.Param("id"))
+	result, err := controller.calibrationService.Update(c.Request().Context(), int64(idCalibration), models.Calibration{Offset: payloadValidator.Offset, Gain: gain(payloadValidator.Gain), ValidFrom: validFrom})
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller CalibrationController) Delete(c echo.Context) error {
+	idCalibration, _ := strconv.Atoi(c.Param("id"))
+	result, err := controller.calibrationService.Delete(c.Request().Context(), int64(idCalibration))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller CalibrationController) GetAll(c echo.Context) error {
+	result, err := controller.calibrationService.GetAll(c.Request().Context(), c.QueryParam("sensor"), c.QueryParam("device_token"))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller CalibrationController) GetById(c echo.Context) error {
+	idCalibration, _ := strconv.Atoi(c.QueryParam("id"))
+	result, err := controller.calibrationService.GetById(c.Request().Context(), int64(idCalibration))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+// Recompute menghitung ulang data lama satu perangkat, dipakai setelah
+// kalibrasi ditambah, diubah atau dihapus dengan valid_from di masa lalu.
+func (controller CalibrationController) Recompute(c echo.Context) error {
+	payloadValidator := new(models.RecomputePayload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	if errorList := controller.validationErrors(c, payloadValidator); len(errorList) > 0 {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	if _, ok := sensors.Find(payloadValidator.Sensor); !ok {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"sensor": i18n.T(c.Request().Context(), i18n.SensorUnknown, payloadValidator.Sensor)})
+	}
+
+	// Tanpa start, seluruh riwayat perangkat dihitung ulang
+	var start time.Time
+	if payloadValidator.Start != "" {
+		t, err := helpers.ParseTime(payloadValidator.Start)
+		if err != nil {
+			return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"start": helpers.ErrorMessage(c.Request().Context(), err)})
+		}
+		start = t
+	}
+
+	result, err := controller.calibrationService.Recompute(c.Request().Context(), payloadValidator.Sensor, payloadValidator.DeviceToken, start)
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller CalibrationController) validationErrors(c echo.Context, payload interface{}) map[string]string {
+	err := controller.validate.Struct(payload)
+	if err == nil {
+		return map[string]string{}
+	}
+
+	return helpers.ValidationErrorList(c.Request().Context(), err, payload)
+}
+
+func hasField(sensor sensors.Sensor, name string) bool {
+	for _, field := range sensor.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValidFrom membaca valid_from, jika kosong kalibrasi berlaku mulai sekarang.
+func parseValidFrom(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	return helpers.ParseTime(value)
+}
+
+// gain bernilai 1 jika tidak diisi sehingga kalibrasi hanya berupa offset.
+func gain(value *float64) float64 {
+	if value == nil {
+		return 1
+	}
+	return *value
+}
+
+func NewCalibrationController(db *gorm.DB, logger *slog.Logger) CalibrationController {
+	controller := CalibrationController{
+		calibrationService: services.NewCalibrationService(db, logger),
+		validate:           *v1.New(),
+	}
+
+	return controller
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Calibration adalah koreksi linear satu field sensor pada satu perangkat yang
+// berlaku sejak ValidFrom. Nilai terkoreksi adalah nilai mentah * Gain + Offset.
+type Calibration struct {
+	Id          int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor      string    `json:"sensor" gorm:"column:sensor"`
+	DeviceToken string    `json:"device_token" gorm:"column:device_token"`
+	Field       string    `json:"field" gorm:"column:field"`
+	Offset      float64   `json:"offset" gorm:"column:offset"`
+	Gain        float64   `json:"gain" gorm:"column:gain"`
+	ValidFrom   time.Time `json:"valid_from" gorm:"column:valid_from"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Calibration) TableName() string {
+	return "db_calibration"
+}
+
+// Apply mengembalikan nilai terkoreksi, dibulatkan ke 4 angka desimal agar
+// tidak menyimpan sisa pembulatan float.
+func (calibration Calibration) Apply(value float64) float64 {
+	return math.Round((value*calibration.Gain+calibration.Offset)*10000) / 10000
+}
+
+// RecomputeReport berisi hasil hitung ulang data lama satu perangkat.
+type RecomputeReport struct {
+	Sensor      string `json:"sensor"`
+	DeviceToken string `json:"device_token"`
+	Rows        int64  `json:"rows"`
+	Calibrated  int64  `json:"calibrated"`
+}
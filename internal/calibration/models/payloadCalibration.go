@@ -0,0 +1,26 @@
+package models
+
+// CreatePayload adalah payload untuk menyimpan kalibrasi baru. Gain kosong
+// bernilai 1 dan valid_from kosong berarti berlaku mulai sekarang.
+type CreatePayload struct {
+	Sensor      string   `json:"sensor" validate:"required"`
+	DeviceToken string   `json:"device_token" validate:"required"`
+	Field       string   `json:"field" validate:"required"`
+	Offset      float64  `json:"offset"`
+	Gain        *float64 `json:"gain" validate:"omitempty,ne=0"`
+	ValidFrom   string   `json:"valid_from"`
+}
+
+// UpdatePayload adalah payload untuk mengubah kalibrasi.
+type UpdatePayload struct {
+	Offset    float64  `json:"offset"`
+	Gain      *float64 `json:"gain" validate:"omitempty,ne=0"`
+	ValidFrom string   `json:"valid_from" validate:"required"`
+}
+
+// RecomputePayload adalah payload untuk menghitung ulang data lama satu perangkat.
+type RecomputePayload struct {
+	Sensor      string `json:"sensor" validate:"required"`
+	DeviceToken string `json:"device_token" validate:"required"`
+	Start       string `json:"start"`
+}
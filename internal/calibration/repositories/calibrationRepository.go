@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"iot-golang/config"
+	"iot-golang/internal/calibration/models"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type dbCalibration struct {
+	Conn *gorm.DB
+}
+
+// Create implements CalibrationRepository.
+// Kalibrasi yang didaftarkan ulang dengan valid_from yang sama akan diperbarui.
+func (db *dbCalibration) Create(ctx context.Context, calibration models.Calibration) error {
+	return config.Upsert(db.Conn.WithContext(metrics.WithQuery(ctx, "calibration", "Create")), &calibration, []string{"sensor", "device_token", "field", "valid_from"}, []string{"offset", "gain", "updated_at"})
+}
+
+// Update implements CalibrationRepository.
+// Offset, gain dan valid_from selalu ditulis agar nilai 0 tetap tersimpan.
+func (db *dbCalibration) Update(ctx context.Context, Id int64, calibration models.Calibration) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "calibration", "Update")).Model(&models.Calibration{}).Where("id", Id).Select("offset", "gain", "valid_from", "updated_at").Updates(calibration).Error
+}
+
+// Delete implements CalibrationRepository.
+func (db *dbCalibration) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "calibration", "Delete")).Delete(&models.Calibration{Id: Id}).Error
+}
+
+// GetAll implements CalibrationRepository.
+func (db *dbCalibration) GetAll(ctx context.Context, Sensor string, DeviceToken string) ([]models.Calibration, error) {
+	var data []models.Calibration
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "calibration", "GetAll"))
+	if Sensor != "" {
+		query = query.Where("sensor = ?", Sensor)
+	}
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+	result := query.Order("sensor, device_token, field, valid_from desc").Find(&data)
+	return data, result.Error
+}
+
+// GetById implements CalibrationRepository.
+func (db *dbCalibration) GetById(ctx context.Context, Id int64) (models.Calibration, error) {
+	var data models.Calibration
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "calibration", "GetById")).Where("id", Id).First(&data)
+	return data, result.Error
+}
+
+// GetByDevice implements CalibrationRepository.
+// Data diurutkan dari valid_from terbaru sehingga kalibrasi yang berlaku pada
+// suatu waktu adalah kalibrasi pertama dengan valid_from tidak melebihi waktu tersebut.
+func (db *dbCalibration) GetByDevice(ctx context.Context, Sensor string, DeviceToken string) ([]models.Calibration, error) {
+	var data []models.Calibration
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "calibration", "GetByDevice")).Where("sensor = ? AND device_token = ?", Sensor, DeviceToken).Order("valid_from desc").Find(&data)
+	return data, result.Error
+}
+
+// GetReadings implements CalibrationRepository.
+// Data diambil per batch berdasarkan id agar tabel besar tidak dimuat sekaligus.
+func (db *dbCalibration) GetReadings(ctx context.Context, Table string, Columns []string, DeviceToken string, Start time.Time, AfterId int64, Limit int) ([]map[string]interface{}, error) {
+	var data []map[string]interface{}
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "calibration", "GetReadings")).Table(Table).
+		Select(append([]string{"id", "raw_values", "created_at"}, Columns...)).
+		Where("device_token = ? AND created_at >= ? AND id > ?", DeviceToken, Start, AfterId).
+		Order("id").
+		Limit(Limit).
+		Find(&data)
+	return data, result.Error
+}
+
+// UpdateReadings implements CalibrationRepository.
+func (db *dbCalibration) UpdateReadings(ctx context.Context, Table string, updates map[int64]map[string]interface{}) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "calibration", "UpdateReadings")).Transaction(func(tx *gorm.DB) error {
+		for id, values := range updates {
+			if err := tx.Table(Table).Where("id = ?", id).Updates(values).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type CalibrationRepository interface {
+	Create(ctx context.Context, calibration models.Calibration) error
+	Update(ctx context.Context, Id int64, calibration models.Calibration) error
+	Delete(ctx context.Context, Id int64) error
+	GetAll(ctx context.Context, Sensor string, DeviceToken string) ([]models.Calibration, error)
+	GetById(ctx context.Context, Id int64) (models.Calibration, error)
+	GetByDevice(ctx context.Context, Sensor string, DeviceToken string) ([]models.Calibration, error)
+	GetReadings(ctx context.Context, Table string, Columns []string, DeviceToken string, Start time.Time, AfterId int64, Limit int) ([]map[string]interface{}, error)
+	UpdateReadings(ctx context.Context, Table string, updates map[int64]map[string]interface{}) error
+}
+
+func NewCalibrationRepository(Conn *gorm.DB, logger *slog.Logger) CalibrationRepository {
+	return &dbCalibration{Conn: logging.Session(Conn, logger.With("repository", "calibration"))}
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iot-golang/internal/calibration/models"
+	"iot-golang/internal/calibration/repositories"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/sensors"
+	"iot-golang/internal/tracing"
+	"log/slog"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const recomputeBatchSize = 500
+
+type calibrationService struct {
+	logger          *slog.Logger
+	calibrationRepo repositories.CalibrationRepository
+}
+
+// Calibrator implements CalibrationService.
+func (service *calibrationService) Calibrator(ctx context.Context, Sensor string) *Calibrator {
+	sensor, _ := sensors.Find(Sensor)
+	return &Calibrator{ctx: ctx, sensor: sensor, repo: service.calibrationRepo, devices: map[string][]models.Calibration{}}
+}
+
+// Create implements CalibrationService.
+func (service *calibrationService) Create(ctx context.Context, calibration models.Calibration) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "CalibrationService.Create")
+	defer span.End()
+
+	var response helpers.Response
+	if err := service.calibrationRepo.Create(ctx, calibration); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal menyimpan kalibrasi", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.CalibrationCreateFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil menyimpan kalibrasi", "sensor", calibration.Sensor, "device_token", calibration.DeviceToken, "field", calibration.Field, "valid_from", calibration.ValidFrom)
+	response.SetMessage(ctx, i18n.CalibrationCreated)
+
+	return response, nil
+}
+
+// Update implements CalibrationService.
+func (service *calibrationService) Update(ctx context.Context, Id int64, calibration models.Calibration) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "CalibrationService.Update")
+	defer span.End()
+
+	var response helpers.Response
+
+	// Cek apakah data ada sebelum di update
+	if _, err := service.find(ctx, Id); err != nil {
+		return response, err
+	}
+
+	// Data ditemukan, lakukan update
+	if err := service.calibrationRepo.Update(ctx, Id, calibration); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengubah data kalibrasi", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.CalibrationUpdateFailed, Id), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengubah data kalibrasi", "id", Id)
+	response.SetMessage(ctx, i18n.CalibrationUpdated)
+
+	return response, nil
+}
+
+// Delete implements CalibrationService.
+func (service *calibrationService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "CalibrationService.Delete")
+	defer span.End()
+
+	var response helpers.Response
+
+	// Cek apakah data ada sebelum dihapus
+	if _, err := service.find(ctx, Id); err != nil {
+		return response, err
+	}
+
+	// Data ditemukan, lakukan penghapusan
+	if err := service.calibrationRepo.Delete(ctx, Id); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal menghapus data kalibrasi", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.CalibrationDeleteFailed, Id), err)
+	}
+
+	service.logger.InfoContext(ctx, "Data kalibrasi berhasil dihapus", "id", Id)
+	response.SetMessage(ctx, i18n.CalibrationDeleted)
+
+	return response, nil
+}
+
+// GetAll implements CalibrationService.
+func (service *calibrationService) GetAll(ctx context.Context, Sensor string, DeviceToken string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "CalibrationService.GetAll")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.calibrationRepo.GetAll(ctx, Sensor, DeviceToken)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data kalibrasi", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.CalibrationListFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil semua data kalibrasi")
+	response.SetMessage(ctx, i18n.CalibrationListed)
+	response.Data = data
+	return response, nil
+}
+
+// GetById implements CalibrationService.
+func (service *calibrationService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "CalibrationService.GetById")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.find(ctx, Id)
+	if err != nil {
+		return response, err
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data kalibrasi", "id", Id)
+	response.SetMessage(ctx, i18n.CalibrationFoundByID, Id)
+	response.Data = data
+
+	return response, nil
+}
+
+func (service *calibrationService) find(ctx context.Context, Id int64) (models.Calibration, error) {
+	data, err := service.calibrationRepo.GetById(ctx, Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data kalibrasi", "id", Id)
+			return data, helpers.NotFound(i18n.Msg(i18n.CalibrationNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data kalibrasi", "id", Id, logging.Error(err))
+		return data, helpers.Internal(i18n.Msg(i18n.CalibrationGetByIDFailed, Id), err)
+	}
+	return data, nil
+}
+
+// Recompute implements CalibrationService.
+// Data sejak Start dihitung ulang dari nilai mentahnya dengan kalibrasi yang
+// berlaku pada waktu data tersebut. Field yang tidak lagi memiliki kalibrasi
+// dikembalikan ke nilai mentah. Data yang tidak pernah dan tidak akan
+// dikalibrasi dilewati.
+func (service *calibrationService) Recompute(ctx context.Context, Sensor string, DeviceToken string, Start time.Time) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "CalibrationService.Recompute")
+	defer span.End()
+
+	var response helpers.Response
+
+	sensor, ok := sensors.Find(Sensor)
+	if !ok {
+		service.logger.ErrorContext(ctx, "Jenis sensor tidak dikenali", "sensor", Sensor)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorUnknown, Sensor))
+	}
+
+	calibrations, err := service.calibrationRepo.GetByDevice(ctx, Sensor, DeviceToken)
+	if err != nil {
+		return service.recomputeError(ctx, Sensor, DeviceToken, err)
+	}
+
+	columns := make([]string, 0, len(sensor.Fields))
+	for _, field := range sensor.Fields {
+		columns = append(columns, field.Column)
+	}
+
+	report := models.RecomputeReport{Sensor: Sensor, DeviceToken: DeviceToken}
+	var afterId int64
+	for {
+		rows, err := service.calibrationRepo.GetReadings(ctx, sensor.Table, columns, DeviceToken, Start, afterId, recomputeBatchSize)
+		if err != nil {
+			return service.recomputeError(ctx, Sensor, DeviceToken, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		updates := make(map[int64]map[string]interface{}, len(rows))
+		for _, row := range rows {
+			id, _ := helpers.ToFloat(row["id"])
+			afterId = int64(id)
+
+			update, calibrated, err := recomputeRow(sensor, calibrations, row)
+			if err != nil {
+				return service.recomputeError(ctx, Sensor, DeviceToken, fmt.Errorf("data id %d: %w", afterId, err))
+			}
+			if update != nil {
+				updates[afterId] = update
+				if calibrated {
+					report.Calibrated++
+				}
+			}
+		}
+
+		if err := service.calibrationRepo.UpdateReadings(ctx, sensor.Table, updates); err != nil {
+			return service.recomputeError(ctx, Sensor, DeviceToken, err)
+		}
+		report.Rows += int64(len(updates))
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil menghitung ulang data sensor", "sensor", Sensor, "device_token", DeviceToken, "rows", report.Rows, "calibrated", report.Calibrated)
+	response.SetMessage(ctx, i18n.CalibrationRecomputed, Sensor, DeviceToken)
+	response.Data = report
+
+	return response, nil
+}
+
+func (service *calibrationService) recomputeError(ctx context.Context, Sensor string, DeviceToken string, err error) (helpers.Response, error) {
+	service.logger.ErrorContext(ctx, "Gagal menghitung ulang data sensor", "sensor", Sensor, "device_token", DeviceToken, logging.Error(err))
+	return helpers.Response{}, helpers.Internal(i18n.Msg(i18n.CalibrationRecomputeFailed, Sensor, DeviceToken), err)
+}
+
+// recomputeRow mengembalikan kolom yang harus diubah pada satu data, atau nil
+// jika data tidak pernah dan tidak perlu dikalibrasi. Nilai setiap kolom
+// mengikuti tipe kolomnya.
+func recomputeRow(sensor sensors.Sensor, calibrations []models.Calibration, row map[string]interface{}) (map[string]interface{}, bool, error) {
+	stored, err := helpers.RawValues(row["raw_values"])
+	if err != nil {
+		return nil, false, err
+	}
+
+	values := make(map[string]float64, len(sensor.Fields))
+	for _, field := range sensor.Fields {
+		value, ok := helpers.ToFloat(row[field.Column])
+		if rawValue, saved := stored[field.Name]; saved {
+			value, ok = helpers.ToFloat(rawValue)
+		}
+		if ok {
+			values[field.Name] = value
+		}
+	}
+
+	// Tanpa waktu data tidak diketahui kalibrasi mana yang berlaku
+	at, ok := helpers.ToTime(row["created_at"])
+	if !ok {
+		return nil, false, fmt.Errorf("created_at %v tidak dapat dibaca", row["created_at"])
+	}
+	raw, corrected := calibrate(calibrations, values, at)
+	if len(stored) == 0 && len(corrected) == 0 {
+		return nil, false, nil
+	}
+
+	update := map[string]interface{}{"raw_values": nil}
+	if len(raw) > 0 {
+		encoded, _ := json.Marshal(raw)
+		update["raw_values"] = string(encoded)
+	}
+
+	for _, field := range sensor.Fields {
+		value, ok := values[field.Name]
+		if !ok {
+			continue
+		}
+		if correctedValue, ok := corrected[field.Name]; ok {
+			if field.Integer {
+				correctedValue = math.Round(correctedValue)
+			}
+			values[field.Name] = correctedValue
+			update[field.Column] = field.Value(correctedValue)
+		} else if _, saved := stored[field.Name]; saved {
+			update[field.Column] = field.Value(value)
+		}
+	}
+	update["quality"] = helpers.AssessValues(sensor.Fields, values).Quality
+
+	return update, len(corrected) > 0, nil
+}
+
+type CalibrationService interface {
+	Calibrator(ctx context.Context, Sensor string) *Calibrator
+	Create(ctx context.Context, calibration models.Calibration) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, calibration models.Calibration) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetAll(ctx context.Context, Sensor string, DeviceToken string) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	Recompute(ctx context.Context, Sensor string, DeviceToken string, Start time.Time) (helpers.Response, error)
+}
+
+func NewCalibrationService(db *gorm.DB, logger *slog.Logger) CalibrationService {
+	return &calibrationService{logger: logger.With("component", "calibration"), calibrationRepo: repositories.NewCalibrationRepository(db, logger)}
+}
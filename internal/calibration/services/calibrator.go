@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"iot-golang/internal/calibration/models"
+	"iot-golang/internal/calibration/repositories"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/sensors"
+	"strconv"
+	"time"
+)
+
+// Calibrator menerapkan kalibrasi pada data sensor yang masuk. Kalibrasi setiap
+// perangkat diambil sekali lalu disimpan selama Calibrator dipakai, sehingga
+// satu Calibrator cukup untuk satu request create atau satu batch import.
+type Calibrator struct {
+	ctx     context.Context
+	sensor  sensors.Sensor
+	repo    repositories.CalibrationRepository
+	devices map[string][]models.Calibration
+}
+
+// Apply mengoreksi field reading yang memiliki kalibrasi yang berlaku pada
+// waktu At. reading harus berupa pointer ke model sensor. Nilai mentah field
+// yang dikoreksi dikembalikan, atau nil jika tidak ada kalibrasi yang berlaku.
+func (calibrator *Calibrator) Apply(DeviceToken string, At time.Time, reading interface{}) (map[string]string, error) {
+	calibrations, ok := calibrator.devices[DeviceToken]
+	if !ok {
+		var err error
+		calibrations, err = calibrator.repo.GetByDevice(calibrator.ctx, calibrator.sensor.Name, DeviceToken)
+		if err != nil {
+			return nil, err
+		}
+		calibrator.devices[DeviceToken] = calibrations
+	}
+	if len(calibrations) == 0 {
+		return nil, nil
+	}
+
+	raw, corrected := calibrate(calibrations, helpers.FieldValues(calibrator.sensor.Fields, reading), At)
+	if len(corrected) == 0 {
+		return nil, nil
+	}
+
+	// Nilai mentah hanya dicatat untuk field yang benar-benar dikoreksi
+	set := helpers.SetFieldValues(reading, corrected)
+	for field := range raw {
+		if !set[field] {
+			delete(raw, field)
+		}
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return raw, nil
+}
+
+// calibrate mengembalikan nilai mentah dan nilai terkoreksi dari field yang
+// memiliki kalibrasi yang berlaku pada waktu at.
+func calibrate(calibrations []models.Calibration, values map[string]float64, at time.Time) (map[string]string, map[string]float64) {
+	raw := map[string]string{}
+	corrected := map[string]float64{}
+	for field, value := range values {
+		if calibration, ok := active(calibrations, field, at); ok {
+			raw[field] = strconv.FormatFloat(value, 'f', -1, 64)
+			corrected[field] = calibration.Apply(value)
+		}
+	}
+	return raw, corrected
+}
+
+// active mencari kalibrasi field yang berlaku pada waktu at. calibrations harus
+// urut dari valid_from terbaru.
+func active(calibrations []models.Calibration, field string, at time.Time) (models.Calibration, bool) {
+	for _, calibration := range calibrations {
+		if calibration.Field == field && !calibration.ValidFrom.After(at) {
+			return calibration, true
+		}
+	}
+	return models.Calibration{}, false
+}
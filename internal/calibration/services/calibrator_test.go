@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"io"
+	"iot-golang/internal/calibration/models"
+	"iot-golang/internal/migrations"
+	thigrowModels "iot-golang/internal/thigrow/models"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB membuka database SQLite di memori yang sudah dimigrasi. Pool
+// dibatasi satu koneksi karena setiap koneksi :memory: memiliki database sendiri.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard, TranslateError: true})
+	if err != nil {
+		t.Fatalf("gagal membuka database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("gagal mengambil pool database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if _, err := migrations.NewMigrator(db, discardLogger()).Up(); err != nil {
+		t.Fatalf("gagal menjalankan migration: %v", err)
+	}
+
+	return db
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newTestService membuat CalibrationService dengan kalibrasi yang sudah tersimpan.
+func newTestService(t *testing.T, db *gorm.DB, calibrations ...models.Calibration) CalibrationService {
+	t.Helper()
+
+	service := NewCalibrationService(db, discardLogger())
+	for _, calibration := range calibrations {
+		if _, err := service.Create(context.Background(), calibration); err != nil {
+			t.Fatalf("gagal menyimpan kalibrasi %s: %v", calibration.Field, err)
+		}
+	}
+	return service
+}
+
+func TestCalibratorApplyThigrow(t *testing.T) {
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	service := newTestService(t, openTestDB(t),
+		models.Calibration{Sensor: "thigrow", DeviceToken: "dev1", Field: "kelembaban_tanah_th", Offset: 1.7, Gain: 1, ValidFrom: validFrom},
+		models.Calibration{Sensor: "thigrow", DeviceToken: "dev1", Field: "temperature", Offset: -0.5, Gain: 1, ValidFrom: validFrom},
+	)
+
+	reading := thigrowModels.Thigrow{DeviceToken: "dev1", KelembabanTanahTh: 40, KelembabanTanahSm: 50, Temperature: "25"}
+	raw, err := service.Calibrator(context.Background(), "thigrow").Apply(reading.DeviceToken, validFrom.Add(time.Hour), &reading)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	// Field bilangan bulat dibulatkan ke nilai terdekat
+	if reading.KelembabanTanahTh != 42 {
+		t.Errorf("kelembaban_tanah_th = %d, seharusnya 42", reading.KelembabanTanahTh)
+	}
+	if reading.Temperature != "24.5" {
+		t.Errorf("temperature = %q, seharusnya 24.5", reading.Temperature)
+	}
+	if reading.KelembabanTanahSm != 50 {
+		t.Errorf("kelembaban_tanah_sm = %d, seharusnya tidak berubah", reading.KelembabanTanahSm)
+	}
+
+	want := map[string]string{"kelembaban_tanah_th": "40", "temperature": "25"}
+	if len(raw) != len(want) {
+		t.Fatalf("raw_values = %v, seharusnya %v", raw, want)
+	}
+	for field, value := range want {
+		if raw[field] != value {
+			t.Errorf("raw_values[%s] = %q, seharusnya %q", field, raw[field], value)
+		}
+	}
+}
+
+func TestCalibratorApplyBeforeValidFrom(t *testing.T) {
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	service := newTestService(t, openTestDB(t),
+		models.Calibration{Sensor: "thigrow", DeviceToken: "dev1", Field: "kelembaban_tanah_th", Offset: 1.7, Gain: 1, ValidFrom: validFrom},
+	)
+
+	reading := thigrowModels.Thigrow{DeviceToken: "dev1", KelembabanTanahTh: 40}
+	raw, err := service.Calibrator(context.Background(), "thigrow").Apply(reading.DeviceToken, validFrom.Add(-time.Hour), &reading)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if raw != nil || reading.KelembabanTanahTh != 40 {
+		t.Errorf("data sebelum valid_from ikut dikalibrasi: raw_values = %v, kelembaban_tanah_th = %d", raw, reading.KelembabanTanahTh)
+	}
+}
+
+func TestRecomputeThigrow(t *testing.T) {
+	db := openTestDB(t)
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reading := thigrowModels.Thigrow{DeviceToken: "dev1", KelembabanTanahTh: 40, Temperature: "25", CreatedAt: validFrom.Add(time.Hour)}
+	if err := db.Create(&reading).Error; err != nil {
+		t.Fatalf("gagal menyimpan data sensor: %v", err)
+	}
+
+	service := newTestService(t, db,
+		models.Calibration{Sensor: "thigrow", DeviceToken: "dev1", Field: "kelembaban_tanah_th", Offset: 1.7, Gain: 1, ValidFrom: validFrom},
+	)
+	if _, err := service.Recompute(context.Background(), "thigrow", "dev1", time.Time{}); err != nil {
+		t.Fatalf("Recompute: %v", err)
+	}
+
+	var stored thigrowModels.Thigrow
+	if err := db.First(&stored, reading.Id).Error; err != nil {
+		t.Fatalf("gagal membaca data sensor: %v", err)
+	}
+	if stored.KelembabanTanahTh != 42 {
+		t.Errorf("kelembaban_tanah_th = %d, seharusnya 42", stored.KelembabanTanahTh)
+	}
+	if stored.RawValues["kelembaban_tanah_th"] != "40" {
+		t.Errorf("raw_values = %v, seharusnya berisi kelembaban_tanah_th 40", stored.RawValues)
+	}
+}
+
+func TestRecomputeUnreadableCreatedAt(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.Exec("INSERT INTO db_sensor_thigrow (device_token, kelembaban_tanah_th, created_at) VALUES (?, ?, ?)", "dev1", 40, "bukan waktu").Error; err != nil {
+		t.Fatalf("gagal menyimpan data sensor: %v", err)
+	}
+
+	service := newTestService(t, db,
+		models.Calibration{Sensor: "thigrow", DeviceToken: "dev1", Field: "kelembaban_tanah_th", Offset: 1.7, Gain: 1, ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	)
+	if _, err := service.Recompute(context.Background(), "thigrow", "dev1", time.Time{}); err == nil {
+		t.Fatal("Recompute tidak mengembalikan error untuk created_at yang tidak dapat dibaca")
+	}
+}
@@ -0,0 +1,140 @@
+package coap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	ingestionServices "iot-golang/internal/ingestion/services"
+	"log/slog"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/plgd-dev/go-coap/v3/message"
+	"github.com/plgd-dev/go-coap/v3/message/codes"
+	"github.com/plgd-dev/go-coap/v3/mux"
+	"gorm.io/gorm"
+)
+
+// Response adalah body response CoAP, dikirim dalam format yang sama dengan
+// request (JSON atau CBOR).
+type Response struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+type ReadingHandler struct {
+	logger           *slog.Logger
+	ingestionService ingestionServices.IngestionService
+}
+
+// Create menerima POST /{sensor} dengan body CreatePayload sensor. Request
+// tanpa Content-Format dibaca sebagai JSON.
+func (handler ReadingHandler) Create(w mux.ResponseWriter, r *mux.Message) {
+	ctx := r.Context()
+
+	if r.Code() != codes.POST {
+		write(w, message.AppJSON, codes.MethodNotAllowed, Response{Code: i18n.HTTPMethodNotAllowed, Message: i18n.T(ctx, i18n.HTTPMethodNotAllowed)})
+		return
+	}
+
+	format, err := r.ContentFormat()
+	if err != nil {
+		format = message.AppJSON
+	}
+	if format != message.AppJSON && format != message.AppCBOR {
+		write(w, message.AppJSON, codes.UnsupportedMediaType, Response{Code: i18n.HTTPUnsupportedMedia, Message: i18n.T(ctx, i18n.HTTPUnsupportedMedia)})
+		return
+	}
+
+	sensor := r.RouteParams.Vars["sensor"]
+	payload, err := handler.ingestionService.Payload(sensor)
+	if err != nil {
+		writeError(w, r, format, err)
+		return
+	}
+
+	body, err := r.ReadBody()
+	if err == nil {
+		err = decode(format, body, payload)
+	}
+	if err != nil {
+		handler.logger.WarnContext(ctx, "Body request CoAP tidak valid", "sensor", sensor, "error", err.Error())
+		writeError(w, r, format, helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), map[string]string{"payload": err.Error()}))
+		return
+	}
+
+	result, err := handler.ingestionService.Create(ctx, sensor, payload)
+	if err != nil {
+		writeError(w, r, format, err)
+		return
+	}
+
+	write(w, format, codes.Created, Response{Code: result.Code, Message: result.Messages})
+}
+
+// NotFound menjawab path yang tidak terdaftar.
+func NotFound(w mux.ResponseWriter, r *mux.Message) {
+	write(w, message.AppJSON, codes.NotFound, Response{Code: i18n.HTTPNotFound, Message: i18n.T(r.Context(), i18n.HTTPNotFound)})
+}
+
+func decode(format message.MediaType, body []byte, payload interface{}) error {
+	if format == message.AppCBOR {
+		return cbor.Unmarshal(body, payload)
+	}
+	return json.Unmarshal(body, payload)
+}
+
+// writeError memetakan error service ke kode response CoAP. Pesan
+// diterjemahkan sesuai bahasa request dan error per field dikirim pada errors.
+func writeError(w mux.ResponseWriter, r *mux.Message, format message.MediaType, err error) {
+	ctx := r.Context()
+
+	var appError *helpers.Error
+	if !errors.As(err, &appError) {
+		write(w, format, codes.InternalServerError, Response{Code: i18n.HTTPInternalError, Message: i18n.T(ctx, i18n.HTTPInternalError)})
+		return
+	}
+
+	code := codes.InternalServerError
+	switch appError.Kind {
+	case helpers.KindValidation:
+		code = codes.BadRequest
+	case helpers.KindNotFound:
+		code = codes.NotFound
+	default:
+		switch helpers.ErrorStatus(appError.Err) {
+		case 504:
+			code = codes.GatewayTimeout
+		case 503:
+			code = codes.ServiceUnavailable
+		}
+	}
+
+	errorList, _ := appError.Details.(map[string]string)
+	write(w, format, code, Response{Code: appError.Message.Code, Message: appError.Message.Translate(i18n.Language(ctx)), Errors: errorList})
+}
+
+func write(w mux.ResponseWriter, format message.MediaType, code codes.Code, response Response) {
+	var body []byte
+	var err error
+	if format == message.AppCBOR {
+		body, err = cbor.Marshal(response)
+	} else {
+		body, err = json.Marshal(response)
+	}
+	if err != nil {
+		_ = w.SetResponse(codes.InternalServerError, message.TextPlain, nil)
+		return
+	}
+
+	_ = w.SetResponse(code, format, bytes.NewReader(body))
+}
+
+func NewReadingHandler(db *gorm.DB, logger *slog.Logger) ReadingHandler {
+	return ReadingHandler{
+		logger:           logger,
+		ingestionService: ingestionServices.NewIngestionService(db, logger),
+	}
+}
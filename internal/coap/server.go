@@ -0,0 +1,124 @@
+package coap
+
+import (
+	"context"
+	"fmt"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/middleware"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/plgd-dev/go-coap/v3/message/codes"
+	"github.com/plgd-dev/go-coap/v3/mux"
+	coapNet "github.com/plgd-dev/go-coap/v3/net"
+	"github.com/plgd-dev/go-coap/v3/options"
+	"github.com/plgd-dev/go-coap/v3/udp"
+	udpServer "github.com/plgd-dev/go-coap/v3/udp/server"
+	"gorm.io/gorm"
+)
+
+// Server menjalankan server CoAP di atas UDP sebagai worker lifecycle untuk
+// perangkat yang tidak sanggup memakai TCP dan HTTP.
+type Server struct {
+	logger  *slog.Logger
+	address string
+	server  *udpServer.Server
+}
+
+func (server *Server) Name() string {
+	return "coap"
+}
+
+// Run membuka listener UDP dan melayani request sampai ctx dibatalkan.
+// Listener memakai udp4 karena response ke client IPv4 dari socket dual-stack
+// gagal dikirim oleh go-coap.
+func (server *Server) Run(ctx context.Context) error {
+	listener, err := coapNet.NewListenUDP("udp4", server.address)
+	if err != nil {
+		return fmt.Errorf("gagal membuka listener CoAP %s: %w", server.address, err)
+	}
+	server.logger.Info("Server CoAP berjalan", "address", listener.LocalAddr().String())
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		server.server.Stop()
+		return <-serveErr
+	case err := <-serveErr:
+		_ = listener.Close()
+		return err
+	}
+}
+
+func NewServer(db *gorm.DB, address string, logger *slog.Logger) *Server {
+	logger = logger.With("component", "coap")
+	readingHandler := NewReadingHandler(db, logger)
+
+	router := mux.NewRouter()
+	router.SetErrorHandler(func(err error) {
+		logger.Error("Gagal mengirim response CoAP", logging.Error(err))
+	})
+	router.Use(requestContext, accessLog(logger))
+	_ = router.Handle("/{sensor}", mux.HandlerFunc(readingHandler.Create))
+	router.DefaultHandle(mux.HandlerFunc(NotFound))
+
+	return &Server{
+		logger:  logger,
+		address: address,
+		server: udp.NewServer(options.WithMux(router), options.WithErrors(func(err error) {
+			logger.Warn("Gagal memproses paket CoAP", logging.Error(err))
+		})),
+	}
+}
+
+// requestContext menyimpan request ID baru dan bahasa dari query lang ke
+// context request. CoAP tidak memiliki header Accept-Language.
+func requestContext(next mux.Handler) mux.Handler {
+	return mux.HandlerFunc(func(w mux.ResponseWriter, r *mux.Message) {
+		lang := ""
+		if queries, err := r.Queries(); err == nil {
+			for _, query := range queries {
+				if value, ok := strings.CutPrefix(query, "lang="); ok {
+					lang = value
+				}
+			}
+		}
+
+		ctx := logging.WithRequestID(r.Context(), middleware.NewRequestID())
+		r.SetContext(i18n.WithLanguage(ctx, i18n.Match(lang, "")))
+		next.ServeCOAP(w, r)
+	})
+}
+
+// accessLog mencatat setiap request CoAP beserta kode response dan durasinya.
+func accessLog(logger *slog.Logger) mux.MiddlewareFunc {
+	return func(next mux.Handler) mux.Handler {
+		return mux.HandlerFunc(func(w mux.ResponseWriter, r *mux.Message) {
+			started := time.Now()
+			next.ServeCOAP(w, r)
+
+			code := w.Message().Code()
+			level := slog.LevelInfo
+			if code >= codes.InternalServerError {
+				level = slog.LevelError
+			} else if code >= codes.BadRequest {
+				level = slog.LevelWarn
+			}
+
+			path, _ := r.Path()
+			logger.Log(r.Context(), level, "Request CoAP",
+				"method", r.Code().String(),
+				"path", path,
+				"code", code.String(),
+				logging.Duration(time.Since(started)),
+				"remote_addr", w.Conn().RemoteAddr().String(),
+			)
+		})
+	}
+}
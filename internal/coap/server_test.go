@@ -0,0 +1,141 @@
+package coap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/migrations"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/plgd-dev/go-coap/v3/message"
+	"github.com/plgd-dev/go-coap/v3/message/codes"
+	coapNet "github.com/plgd-dev/go-coap/v3/net"
+	"github.com/plgd-dev/go-coap/v3/udp"
+	"github.com/plgd-dev/go-coap/v3/udp/client"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// startServer menjalankan Server di atas SQLite di memori yang sudah
+// dimigrasi dan mengembalikan client yang terhubung ke server tersebut.
+func startServer(t *testing.T) *client.Conn {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard, TranslateError: true})
+	if err != nil {
+		t.Fatalf("gagal membuka database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("gagal mengambil pool database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := migrations.NewMigrator(db, discard).Up(); err != nil {
+		t.Fatalf("gagal menjalankan migration: %v", err)
+	}
+
+	// Listener dibuka di sini agar port acak dari 127.0.0.1:0 diketahui
+	server := NewServer(db, "127.0.0.1:0", discard)
+	listener, err := coapNet.NewListenUDP("udp4", server.address)
+	if err != nil {
+		t.Fatalf("gagal membuka listener CoAP: %v", err)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		server.server.Stop()
+		<-serveErr
+		_ = listener.Close()
+	})
+
+	conn, err := udp.Dial(listener.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("gagal terhubung ke server CoAP: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestServerCreate(t *testing.T) {
+	conn := startServer(t)
+
+	reading := map[string]string{
+		"device_token":     "dev1",
+		"tekanan_udara":    "1000",
+		"tinggi_permukaan": "10",
+		"battery":          "9",
+	}
+	incomplete := map[string]string{"device_token": "dev1"}
+
+	tests := []struct {
+		name     string
+		path     string
+		format   message.MediaType
+		body     interface{}
+		wantCode codes.Code
+		wantBody string
+	}{
+		{name: "json", path: "/bmp", format: message.AppJSON, body: reading, wantCode: codes.Created, wantBody: i18n.SensorCreated},
+		{name: "cbor", path: "/bmp", format: message.AppCBOR, body: reading, wantCode: codes.Created, wantBody: i18n.SensorCreated},
+		{name: "field kosong", path: "/bmp", format: message.AppJSON, body: incomplete, wantCode: codes.BadRequest, wantBody: i18n.RequestRejected},
+		{name: "sensor tidak dikenal", path: "/foo", format: message.AppJSON, body: reading, wantCode: codes.NotFound, wantBody: i18n.SensorUnknown},
+		{name: "content format tidak didukung", path: "/bmp", format: message.TextPlain, body: "dev1;1000;10;9", wantCode: codes.UnsupportedMediaType, wantBody: i18n.HTTPUnsupportedMedia},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body []byte
+			var err error
+			switch test.format {
+			case message.AppCBOR:
+				body, err = cbor.Marshal(test.body)
+			case message.AppJSON:
+				body, err = json.Marshal(test.body)
+			default:
+				body = []byte(test.body.(string))
+			}
+			if err != nil {
+				t.Fatalf("gagal menyusun body: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			resp, err := conn.Post(ctx, test.path, test.format, bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("POST %s: %v", test.path, err)
+			}
+
+			if resp.Code() != test.wantCode {
+				t.Fatalf("kode response = %v, seharusnya %v", resp.Code(), test.wantCode)
+			}
+
+			payload, err := resp.ReadBody()
+			if err != nil {
+				t.Fatalf("gagal membaca body response: %v", err)
+			}
+			format, err := resp.ContentFormat()
+			if err != nil {
+				t.Fatalf("response tidak memiliki Content-Format: %v", err)
+			}
+			var response Response
+			if err := decode(format, payload, &response); err != nil {
+				t.Fatalf("gagal membaca body response: %v", err)
+			}
+			if response.Code != test.wantBody {
+				t.Errorf("code = %q, seharusnya %q", response.Code, test.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"iot-golang/internal/completeness/services"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/sensors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type CompletenessController struct {
+	completenessService services.CompletenessService
+}
+
+func (controller CompletenessController) GetReport(c echo.Context) error {
+	sensor := c.QueryParam("sensor")
+	if _, ok := sensors.Find(sensor); sensor != "" && !ok {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"sensor": i18n.T(c.Request().Context(), i18n.SensorUnknown, sensor)})
+	}
+
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	result, err := controller.completenessService.GetReport(c.Request().Context(), sensor, c.QueryParam("device_token"), start, end)
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func NewCompletenessController(db *gorm.DB, logger *slog.Logger) CompletenessController {
+	controller := CompletenessController{
+		completenessService: services.NewCompletenessService(db, logger),
+	}
+
+	return controller
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	// SourceRaw berarti laporan dihitung dari waktu setiap data mentah.
+	SourceRaw = "raw"
+	// SourceHour berarti data mentah sudah dihapus oleh kebijakan retensi dan
+	// laporan dihitung dari jumlah data per jam pada rollup.
+	SourceHour = "hour"
+)
+
+// DailyReport berisi kelengkapan data satu perangkat pada satu sensor untuk
+// satu hari UTC. Hari pertama dan terakhir dipotong sesuai rentang laporan.
+type DailyReport struct {
+	Sensor      string `json:"sensor"`
+	DeviceToken string `json:"device_token"`
+	Date        string `json:"date"`
+	Source      string `json:"source"`
+	// Interval adalah interval pengiriman yang diharapkan dalam detik.
+	Interval   int64   `json:"expected_interval"`
+	Expected   int64   `json:"expected"`
+	Received   int64   `json:"received"`
+	Percentage float64 `json:"percentage"`
+	Gaps       []Gap   `json:"gaps"`
+}
+
+// Gap adalah rentang waktu tanpa data yang lebih panjang dari batas toleransi.
+// Duration dalam detik, Missing adalah perkiraan jumlah data yang hilang.
+type Gap struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration int64     `json:"duration"`
+	Missing  int64     `json:"missing"`
+}
+
+// HourlyCount adalah jumlah data satu perangkat pada satu bucket rollup per jam.
+type HourlyCount struct {
+	BucketStart time.Time `gorm:"column:bucket_start"`
+	Count       int64     `gorm:"column:count"`
+}
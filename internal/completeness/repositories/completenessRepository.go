@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"iot-golang/internal/completeness/models"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	retentionModels "iot-golang/internal/retention/models"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type dbCompleteness struct {
+	Conn *gorm.DB
+}
+
+type deviceInterval struct {
+	DeviceToken      string `gorm:"column:device_token"`
+	ExpectedInterval int64  `gorm:"column:expected_interval"`
+}
+
+// GetDevices implements CompletenessRepository.
+// Seluruh perangkat yang pernah mengirim data ke tabel sensor diambil, sehingga
+// perangkat yang tidak mengirim data sama sekali pada rentang laporan tetap muncul.
+// Perangkat yang data mentahnya sudah dihapus diambil dari rollup.
+func (db *dbCompleteness) GetDevices(ctx context.Context, Sensor string, Table string, DeviceToken string) ([]string, error) {
+	conn := db.Conn.WithContext(metrics.WithQuery(ctx, "completeness", "GetDevices"))
+
+	var raw, rolled []string
+	query := conn.Table(Table).Distinct("device_token")
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+	if err := query.Pluck("device_token", &raw).Error; err != nil {
+		return nil, err
+	}
+
+	query = conn.Model(&retentionModels.RollupCount{}).Distinct("device_token").Where("sensor = ?", Sensor)
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+	if err := query.Pluck("device_token", &rolled).Error; err != nil {
+		return nil, err
+	}
+
+	return helpers.DistinctTokens(append(raw, rolled...)), nil
+}
+
+// GetTimestamps implements CompletenessRepository.
+func (db *dbCompleteness) GetTimestamps(ctx context.Context, Table string, DeviceToken string, Start time.Time, End time.Time) ([]time.Time, error) {
+	var data []time.Time
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "completeness", "GetTimestamps")).Table(Table).
+		Where("device_token = ? AND created_at >= ? AND created_at < ?", DeviceToken, Start, End).
+		Order("created_at").
+		Pluck("created_at", &data)
+	return data, result.Error
+}
+
+// GetHourlyCounts implements CompletenessRepository.
+// Jumlah data per jam diambil dari RollupCount yang juga menghitung data bad,
+// bukan dari count rollup yang hanya berisi data yang dipakai agregat.
+func (db *dbCompleteness) GetHourlyCounts(ctx context.Context, Sensor string, DeviceToken string, Start time.Time, End time.Time) ([]models.HourlyCount, error) {
+	var data []models.HourlyCount
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "completeness", "GetHourlyCounts")).Model(&retentionModels.RollupCount{}).
+		Select("bucket_start, count").
+		Where("sensor = ? AND device_token = ? AND resolution = ?", Sensor, DeviceToken, retentionModels.ResolutionHour).
+		Where("bucket_start >= ? AND bucket_start < ?", Start, End).
+		Order("bucket_start").
+		Scan(&data)
+	return data, result.Error
+}
+
+// GetIntervals implements CompletenessRepository.
+func (db *dbCompleteness) GetIntervals(ctx context.Context) (map[string]time.Duration, error) {
+	var rows []deviceInterval
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "completeness", "GetIntervals")).Table("db_device").
+		Select("device_token, expected_interval").
+		Where("expected_interval > 0").
+		Scan(&rows)
+
+	intervals := make(map[string]time.Duration, len(rows))
+	for _, row := range rows {
+		intervals[row.DeviceToken] = time.Duration(row.ExpectedInterval) * time.Second
+	}
+	return intervals, result.Error
+}
+
+type CompletenessRepository interface {
+	GetDevices(ctx context.Context, Sensor string, Table string, DeviceToken string) ([]string, error)
+	GetTimestamps(ctx context.Context, Table string, DeviceToken string, Start time.Time, End time.Time) ([]time.Time, error)
+	GetHourlyCounts(ctx context.Context, Sensor string, DeviceToken string, Start time.Time, End time.Time) ([]models.HourlyCount, error)
+	GetIntervals(ctx context.Context) (map[string]time.Duration, error)
+}
+
+func NewCompletenessRepository(Conn *gorm.DB, logger *slog.Logger) CompletenessRepository {
+	return &dbCompleteness{Conn: logging.Session(Conn, logger.With("repository", "completeness"))}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"iot-golang/internal/completeness/models"
+	"iot-golang/internal/completeness/repositories"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	retentionServices "iot-golang/internal/retention/services"
+	"iot-golang/internal/sensors"
+	"iot-golang/internal/tracing"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type completenessService struct {
+	logger           *slog.Logger
+	completenessRepo repositories.CompletenessRepository
+	retentionService retentionServices.RetentionService
+}
+
+// GetReport implements CompletenessService.
+// Sensor dan DeviceToken bersifat opsional. Jika kosong, seluruh sensor dan
+// perangkat dilaporkan. End yang melewati waktu sekarang dipotong ke sekarang.
+// Rentang yang data mentahnya sudah dihapus dihitung dari rollup per jam,
+// rentang yang rollup per jamnya juga sudah dihapus tidak dilaporkan.
+func (service *completenessService) GetReport(ctx context.Context, Sensor string, DeviceToken string, Start time.Time, End time.Time) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "CompletenessService.GetReport")
+	defer span.End()
+
+	var response helpers.Response
+
+	now := time.Now()
+	if End.After(now) {
+		End = now
+	}
+
+	intervals, err := service.completenessRepo.GetIntervals(ctx)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil interval perangkat", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.CompletenessFailed), err)
+	}
+
+	reports := []models.DailyReport{}
+	var clampedAt time.Time
+	for _, sensor := range sensors.All {
+		if Sensor != "" && sensor.Name != Sensor {
+			continue
+		}
+
+		policy, err := service.retentionService.Policy(ctx, sensor.Name)
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal mengambil kebijakan retensi", "sensor", sensor.Name, logging.Error(err))
+			return response, helpers.Internal(i18n.Msg(i18n.CompletenessFailed), err)
+		}
+
+		start, rawStart := Start, Start
+		if rawCutoff, ok := policy.RawCutoff(now); ok && Start.Before(rawCutoff) {
+			rawStart = rawCutoff
+			if hourlyCutoff, ok := policy.HourlyCutoff(now); ok && start.Before(hourlyCutoff) {
+				start = minTime(hourlyCutoff, rawCutoff)
+				if start.After(clampedAt) {
+					clampedAt = start
+				}
+			}
+		}
+
+		devices, err := service.completenessRepo.GetDevices(ctx, sensor.Name, sensor.Table, DeviceToken)
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal mengambil daftar perangkat sensor", "sensor", sensor.Name, logging.Error(err))
+			return response, helpers.Internal(i18n.Msg(i18n.CompletenessFailed), err)
+		}
+
+		for _, device := range devices {
+			interval, ok := intervals[device]
+			if !ok {
+				interval = DefaultInterval
+			}
+
+			if hourEnd := minTime(rawStart, End); start.Before(hourEnd) {
+				counts, err := service.completenessRepo.GetHourlyCounts(ctx, sensor.Name, device, start.Truncate(time.Hour), hourEnd)
+				if err != nil {
+					service.logger.ErrorContext(ctx, "Gagal mengambil rollup data sensor", "sensor", sensor.Name, "device_token", device, logging.Error(err))
+					return response, helpers.Internal(i18n.Msg(i18n.CompletenessFailed), err)
+				}
+				reports = append(reports, hourlyReports(sensor.Name, device, interval, start, hourEnd, counts)...)
+			}
+
+			if rawStart.Before(End) {
+				timestamps, err := service.completenessRepo.GetTimestamps(ctx, sensor.Table, device, rawStart, End)
+				if err != nil {
+					service.logger.ErrorContext(ctx, "Gagal mengambil waktu data sensor", "sensor", sensor.Name, "device_token", device, logging.Error(err))
+					return response, helpers.Internal(i18n.Msg(i18n.CompletenessFailed), err)
+				}
+				reports = append(reports, dailyReports(sensor.Name, device, interval, rawStart, End, timestamps)...)
+			}
+		}
+	}
+
+	if len(reports) == 0 {
+		service.logger.InfoContext(ctx, "Tidak menemukan data untuk laporan kelengkapan", "sensor", Sensor, "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.CompletenessMissing))
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil membuat laporan kelengkapan data", "sensor", Sensor, "device_token", DeviceToken, "count", len(reports))
+	if clampedAt.IsZero() {
+		response.SetMessage(ctx, i18n.CompletenessFound)
+	} else {
+		response.SetMessage(ctx, i18n.CompletenessClamped, clampedAt.Format(time.RFC3339))
+	}
+	response.Data = reports
+
+	return response, nil
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+type CompletenessService interface {
+	GetReport(ctx context.Context, Sensor string, DeviceToken string, Start time.Time, End time.Time) (helpers.Response, error)
+}
+
+func NewCompletenessService(db *gorm.DB, logger *slog.Logger) CompletenessService {
+	return &completenessService{
+		logger:           logger.With("component", "completeness"),
+		completenessRepo: repositories.NewCompletenessRepository(db, logger),
+		retentionService: retentionServices.NewRetentionService(db, logger),
+	}
+}
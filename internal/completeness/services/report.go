@@ -0,0 +1,148 @@
+package services
+
+import (
+	"iot-golang/internal/completeness/models"
+	"math"
+	"time"
+)
+
+// Parameter laporan, diatur dari COMPLETENESS_DEFAULT_INTERVAL dan
+// COMPLETENESS_GAP_TOLERANCE di main.go.
+var (
+	// DefaultInterval dipakai untuk perangkat yang belum memiliki expected_interval.
+	DefaultInterval = 5 * time.Minute
+	// GapTolerance adalah kelipatan interval sebelum jeda antar data dianggap gap.
+	GapTolerance = 1.5
+)
+
+const day = 24 * time.Hour
+
+// dailyReports membagi rentang laporan per hari UTC lalu menghitung jumlah data
+// yang diharapkan, yang diterima, dan gap di antaranya. timestamps harus urut
+// dari yang terlama dan berada di dalam rentang Start sampai End.
+func dailyReports(sensor string, deviceToken string, interval time.Duration, start time.Time, end time.Time, timestamps []time.Time) []models.DailyReport {
+	var reports []models.DailyReport
+	tolerance := time.Duration(float64(interval) * GapTolerance)
+
+	i := 0
+	eachDay(start, end, func(dayStart time.Time, from time.Time, to time.Time) {
+		report := newReport(sensor, deviceToken, models.SourceRaw, interval, dayStart, from, to)
+
+		previous, received := from, false
+		for ; i < len(timestamps) && timestamps[i].Before(to); i++ {
+			if timestamps[i].Sub(previous) > tolerance {
+				report.Gaps = append(report.Gaps, newGap(previous, timestamps[i], interval, received))
+			}
+			previous, received = timestamps[i], true
+			report.Received++
+		}
+		if to.Sub(previous) > tolerance {
+			report.Gaps = append(report.Gaps, newGap(previous, to, interval, false))
+		}
+
+		reports = append(reports, finishReport(report))
+	})
+
+	return reports
+}
+
+// hourlyReports menghitung kelengkapan dari jumlah data per jam pada rollup
+// untuk rentang yang data mentahnya sudah dihapus. Gap hanya terlihat per jam,
+// yaitu jam berurutan tanpa data sama sekali. counts harus urut dari yang terlama.
+func hourlyReports(sensor string, deviceToken string, interval time.Duration, start time.Time, end time.Time, counts []models.HourlyCount) []models.DailyReport {
+	var reports []models.DailyReport
+	tolerance := time.Duration(float64(interval) * GapTolerance)
+
+	i := 0
+	eachDay(start, end, func(dayStart time.Time, from time.Time, to time.Time) {
+		report := newReport(sensor, deviceToken, models.SourceHour, interval, dayStart, from, to)
+
+		var empty time.Time
+		for hour := from.Truncate(time.Hour); hour.Before(to); hour = hour.Add(time.Hour) {
+			var count int64
+			for ; i < len(counts) && counts[i].BucketStart.Before(hour.Add(time.Hour)); i++ {
+				if !counts[i].BucketStart.Before(hour) {
+					count += counts[i].Count
+				}
+			}
+			report.Received += count
+
+			if count == 0 && empty.IsZero() {
+				empty = hour
+				if empty.Before(from) {
+					empty = from
+				}
+			} else if count > 0 && !empty.IsZero() {
+				if hour.Sub(empty) > tolerance {
+					report.Gaps = append(report.Gaps, newGap(empty, hour, interval, false))
+				}
+				empty = time.Time{}
+			}
+		}
+		if !empty.IsZero() && to.Sub(empty) > tolerance {
+			report.Gaps = append(report.Gaps, newGap(empty, to, interval, false))
+		}
+
+		reports = append(reports, finishReport(report))
+	})
+
+	return reports
+}
+
+// eachDay memanggil fn untuk setiap hari UTC di antara start dan end. from dan
+// to adalah batas hari yang sudah dipotong sesuai rentang.
+func eachDay(start time.Time, end time.Time, fn func(dayStart time.Time, from time.Time, to time.Time)) {
+	for dayStart := start.UTC().Truncate(day); dayStart.Before(end); dayStart = dayStart.Add(day) {
+		from, to := dayStart, dayStart.Add(day)
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if from.Before(to) {
+			fn(dayStart, from, to)
+		}
+	}
+}
+
+func newReport(sensor string, deviceToken string, source string, interval time.Duration, dayStart time.Time, from time.Time, to time.Time) models.DailyReport {
+	return models.DailyReport{
+		Sensor:      sensor,
+		DeviceToken: deviceToken,
+		Date:        dayStart.Format("2006-01-02"),
+		Source:      source,
+		Interval:    int64(interval / time.Second),
+		Expected:    int64(to.Sub(from) / interval),
+		Gaps:        []models.Gap{},
+	}
+}
+
+// finishReport menghitung persentase data yang diterima.
+func finishReport(report models.DailyReport) models.DailyReport {
+	report.Percentage = 100
+	if report.Expected > 0 {
+		report.Percentage = math.Min(100, math.Round(float64(report.Received)/float64(report.Expected)*10000)/100)
+	}
+	return report
+}
+
+// newGap membuat gap antara dua waktu. Jika kedua ujungnya adalah data yang
+// diterima, kedua ujung tidak dihitung sebagai data yang hilang. Jika salah satu
+// ujung adalah batas hari, satu slot di ujung tersebut juga dihitung hilang.
+func newGap(start time.Time, end time.Time, interval time.Duration, betweenReadings bool) models.Gap {
+	duration := end.Sub(start)
+	missing := int64(math.Round(float64(duration) / float64(interval)))
+	if betweenReadings {
+		missing--
+	}
+	if missing < 1 {
+		missing = 1
+	}
+	return models.Gap{
+		Start:    start.UTC(),
+		End:      end.UTC(),
+		Duration: int64(duration / time.Second),
+		Missing:  missing,
+	}
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"iot-golang/internal/device/models"
+	"iot-golang/internal/device/services"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type DeviceController struct {
+	deviceService services.DeviceService
+	mapService    services.MapService
+	validate      v1.Validate
+}
+
+func (controller DeviceController) Create(c echo.Context) error {
+	payloadValidator := new(models.CreatePayload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	err := controller.validate.Struct(payloadValidator)
+	if err != nil {
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator, helpers.ValidationMessages{"min": i18n.FieldNotNegative})
+
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	result, err := controller.deviceService.Create(c.Request().Context(), models.Device{DeviceToken: payloadValidator.DeviceToken, Name: payloadValidator.Name, Farm: payloadValidator.Farm, Latitude: payloadValidator.Latitude, Longitude: payloadValidator.Longitude, ExpectedInterval: payloadValidator.ExpectedInterval})
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusCreated)
+}
+
+func (controller DeviceController) Update(c echo.Context) error {
+	payloadValidator := new(models.UpdatePayload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	err := controller.validate.Struct(payloadValidator)
+	if err != nil {
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator, helpers.ValidationMessages{"min": i18n.FieldNotNegative})
+
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	idDevice, _ := strconv.Atoi(c.Param("id"))
+	result, err := controller.deviceService.Update(c.Request().Context(), int64(idDevice), models.Device{Name: payloadValidator.Name, Farm: payloadValidator.Farm, Latitude: payloadValidator.Latitude, Longitude: payloadValidator.Longitude, ExpectedInterval: payloadValidator.ExpectedInterval})
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller DeviceController) Delete(c echo.Context) error {
+	idDevice, _ := strconv.Atoi(c.Param("id"))
+	result, err := controller.deviceService.Delete(c.Request().Context(), int64(idDevice))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller DeviceController) GetAll(c echo.Context) error {
+	result, err := controller.deviceService.GetAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller DeviceController) GetById(c echo.Context) error {
+	idDevice, _ := strconv.Atoi(c.QueryParam("id"))
+	result, err := controller.deviceService.GetById(c.Request().Context(), int64(idDevice))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+// GetMap mengembalikan GeoJSON FeatureCollection secara langsung agar dapat
+// dipakai oleh Leaflet tanpa membuka envelope helpers.Response.
+func (controller DeviceController) GetMap(c echo.Context) error {
+	result, err := controller.mapService.GetLayer(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result.Data)
+}
+
+func NewDeviceController(db *gorm.DB, logger *slog.Logger) DeviceController {
+	controller := DeviceController{
+		deviceService: services.NewDeviceService(db, logger),
+		mapService:    services.NewMapService(db, logger),
+		validate:      *v1.New(),
+	}
+
+	return controller
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Device struct {
+	Id          int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken string `json:"device_token" gorm:"column:device_token"`
+	Name        string `json:"name" gorm:"column:name"`
+	Farm        string `json:"farm" gorm:"column:farm"`
+	Latitude    string `json:"latitude" gorm:"column:latitude"`
+	Longitude   string `json:"longitude" gorm:"column:longitude"`
+	// ExpectedInterval adalah interval pengiriman data yang diharapkan dalam
+	// detik, dipakai oleh laporan kelengkapan data. Nilai 0 memakai default.
+	ExpectedInterval int64     `json:"expected_interval" gorm:"column:expected_interval;default:0"`
+	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Device) TableName() string {
+	return "db_device"
+}
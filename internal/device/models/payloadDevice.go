@@ -0,0 +1,21 @@
+package models
+
+// CreatePayload adalah payload untuk mendaftarkan perangkat baru.
+// ExpectedInterval dalam detik.
+type CreatePayload struct {
+	DeviceToken      string `json:"device_token" validate:"required"`
+	Name             string `json:"name" validate:"required"`
+	Farm             string `json:"farm"`
+	Latitude         string `json:"latitude" validate:"omitempty,numeric"`
+	Longitude        string `json:"longitude" validate:"omitempty,numeric"`
+	ExpectedInterval int64  `json:"expected_interval" validate:"min=0"`
+}
+
+// UpdatePayload adalah payload untuk mengubah data perangkat.
+type UpdatePayload struct {
+	Name             string `json:"name"`
+	Farm             string `json:"farm"`
+	Latitude         string `json:"latitude" validate:"omitempty,numeric"`
+	Longitude        string `json:"longitude" validate:"omitempty,numeric"`
+	ExpectedInterval int64  `json:"expected_interval" validate:"min=0"`
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"iot-golang/internal/device/models"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type dbDevice struct {
+	Conn *gorm.DB
+}
+
+// Create implements DeviceRepository.
+func (db *dbDevice) Create(ctx context.Context, device models.Device) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "device", "Create")).Create(&device).Error
+}
+
+// Delete implements DeviceRepository.
+func (db *dbDevice) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "device", "Delete")).Delete(&models.Device{Id: Id}).Error
+}
+
+// GetAll implements DeviceRepository.
+func (db *dbDevice) GetAll(ctx context.Context) ([]models.Device, error) {
+	var data []models.Device
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "device", "GetAll")).Find(&data)
+	return data, result.Error
+}
+
+// GetById implements DeviceRepository.
+func (db *dbDevice) GetById(ctx context.Context, Id int64) (models.Device, error) {
+	var data models.Device
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "device", "GetById")).Where("id", Id).First(&data)
+	return data, result.Error
+}
+
+// GetByToken implements DeviceRepository.
+func (db *dbDevice) GetByToken(ctx context.Context, DeviceToken string) (models.Device, error) {
+	var data models.Device
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "device", "GetByToken")).Where("device_token", DeviceToken).First(&data)
+	return data, result.Error
+}
+
+// Update implements DeviceRepository.
+func (db *dbDevice) Update(ctx context.Context, Id int64, device models.Device) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "device", "Update")).Where("id", Id).Updates(device).Error
+}
+
+type DeviceRepository interface {
+	Create(ctx context.Context, device models.Device) error
+	Update(ctx context.Context, Id int64, device models.Device) error
+	Delete(ctx context.Context, Id int64) error
+	GetById(ctx context.Context, Id int64) (models.Device, error)
+	GetByToken(ctx context.Context, DeviceToken string) (models.Device, error)
+	GetAll(ctx context.Context) ([]models.Device, error)
+}
+
+func NewDeviceRepository(Conn *gorm.DB, logger *slog.Logger) DeviceRepository {
+	return &dbDevice{Conn: logging.Session(Conn, logger.With("repository", "device"))}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"iot-golang/internal/device/models"
+	"iot-golang/internal/device/repositories"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/tracing"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type deviceService struct {
+	logger     *slog.Logger
+	deviceRepo repositories.DeviceRepository
+}
+
+// Create implements DeviceService.
+func (service *deviceService) Create(ctx context.Context, device models.Device) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.Create")
+	defer span.End()
+
+	var response helpers.Response
+	if err := service.deviceRepo.Create(ctx, device); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data perangkat baru", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceCreateFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil membuat data perangkat baru")
+	response.SetMessage(ctx, i18n.DeviceCreated)
+
+	return response, nil
+}
+
+// Update implements DeviceService.
+func (service *deviceService) Update(ctx context.Context, Id int64, device models.Device) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.Update")
+	defer span.End()
+
+	var response helpers.Response
+
+	// Cek apakah data ada sebelum di update
+	_, findErr := service.deviceRepo.GetById(ctx, Id)
+	if findErr != nil {
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data perangkat", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.DeviceNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data perangkat", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceGetByIDFailed, Id), findErr)
+	}
+
+	// Data ditemukan, lakukan update
+	if err := service.deviceRepo.Update(ctx, Id, device); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengubah data perangkat", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceUpdateFailed, Id), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengubah data perangkat")
+	response.SetMessage(ctx, i18n.DeviceUpdated)
+
+	return response, nil
+}
+
+// Delete implements DeviceService.
+func (service *deviceService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.Delete")
+	defer span.End()
+
+	var response helpers.Response
+
+	// Cek apakah data ada sebelum dihapus
+	_, findErr := service.deviceRepo.GetById(ctx, Id)
+	if findErr != nil {
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data perangkat", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.DeviceNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data perangkat", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceGetByIDFailed, Id), findErr)
+	}
+
+	// Data ditemukan, lakukan penghapusan
+	if err := service.deviceRepo.Delete(ctx, Id); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal menghapus data perangkat", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceDeleteFailed, Id), err)
+	}
+
+	service.logger.InfoContext(ctx, "Data perangkat berhasil dihapus")
+	response.SetMessage(ctx, i18n.DeviceDeleted)
+
+	return response, nil
+}
+
+// GetAll implements DeviceService.
+func (service *deviceService) GetAll(ctx context.Context) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.GetAll")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.deviceRepo.GetAll(ctx)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data perangkat", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceListFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil semua data perangkat")
+	response.SetMessage(ctx, i18n.DeviceListed)
+	response.Data = data
+	return response, nil
+}
+
+// GetById implements DeviceService.
+func (service *deviceService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.GetById")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.deviceRepo.GetById(ctx, Id)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data perangkat", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.DeviceNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data perangkat", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceGetByIDFailed, Id), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data perangkat", "id", Id)
+	response.SetMessage(ctx, i18n.DeviceFoundByID, Id)
+	response.Data = data
+
+	return response, nil
+}
+
+// GetByToken implements DeviceService.
+func (service *deviceService) GetByToken(ctx context.Context, DeviceToken string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.GetByToken")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.deviceRepo.GetByToken(ctx, DeviceToken)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data perangkat", "device_token", DeviceToken)
+			return response, helpers.NotFound(i18n.Msg(i18n.DeviceNotFoundByToken, DeviceToken))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data perangkat", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceGetByTokenFailed, DeviceToken), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data perangkat", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.DeviceFoundByToken, DeviceToken)
+	response.Data = data
+
+	return response, nil
+}
+
+type DeviceService interface {
+	Create(ctx context.Context, device models.Device) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, device models.Device) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	GetByToken(ctx context.Context, DeviceToken string) (helpers.Response, error)
+	GetAll(ctx context.Context) (helpers.Response, error)
+}
+
+func NewDeviceService(db *gorm.DB, logger *slog.Logger) DeviceService {
+	return &deviceService{logger: logger.With("component", "device"), deviceRepo: repositories.NewDeviceRepository(db, logger)}
+}
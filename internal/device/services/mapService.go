@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	beitianModels "iot-golang/internal/beitian/models"
+	beitianRepositories "iot-golang/internal/beitian/repositories"
+	bmpRepositories "iot-golang/internal/bmp/repositories"
+	"iot-golang/internal/device/models"
+	"iot-golang/internal/device/repositories"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	inaRepositories "iot-golang/internal/ina/repositories"
+	"iot-golang/internal/logging"
+	pzemRepositories "iot-golang/internal/pzem/repositories"
+	thigrowRepositories "iot-golang/internal/thigrow/repositories"
+	thmRepositories "iot-golang/internal/thm/repositories"
+	"iot-golang/internal/tracing"
+
+	"gorm.io/gorm"
+)
+
+const (
+	LocationBeitian = "beitian"
+	LocationStatic  = "static"
+)
+
+type mapService struct {
+	logger      *slog.Logger
+	deviceRepo  repositories.DeviceRepository
+	beitianRepo beitianRepositories.BeitianRepository
+	bmpRepo     bmpRepositories.BmpRepository
+	inaRepo     inaRepositories.InaRepository
+	pzemRepo    pzemRepositories.PzemRepository
+	thigrowRepo thigrowRepositories.ThigrowRepository
+	thmRepo     thmRepositories.ThmRepository
+}
+
+// latestReadings mengambil data terbaru setiap sensor untuk seluruh perangkat
+// dengan satu query per tabel sensor, beserta posisi Beitian terakhir setiap
+// perangkat. Sensor yang gagal dibaca dilewati agar peta tetap bisa ditampilkan.
+func (service *mapService) latestReadings(ctx context.Context, DeviceTokens []string) (map[string]map[string]interface{}, map[string]beitianModels.Beitian) {
+	readings := make(map[string]map[string]interface{}, len(DeviceTokens))
+	for _, token := range DeviceTokens {
+		readings[token] = make(map[string]interface{})
+	}
+	add := func(sensor string, token string, data interface{}) {
+		if _, ok := readings[token][sensor]; !ok {
+			readings[token][sensor] = data
+		}
+	}
+	skip := func(sensor string, err error) {
+		service.logger.WarnContext(ctx, "Gagal mengambil data terbaru sensor untuk peta", "sensor", sensor, logging.Error(err))
+	}
+
+	fixes := make(map[string]beitianModels.Beitian)
+	if data, err := service.beitianRepo.GetNewByTokens(ctx, DeviceTokens); err != nil {
+		skip("beitian", err)
+	} else {
+		for _, row := range data {
+			if _, ok := fixes[row.DeviceToken]; !ok {
+				fixes[row.DeviceToken] = row
+			}
+			add("beitian", row.DeviceToken, row)
+		}
+	}
+	if data, err := service.bmpRepo.GetNewByTokens(ctx, DeviceTokens); err != nil {
+		skip("bmp", err)
+	} else {
+		for _, row := range data {
+			add("bmp", row.DeviceToken, row)
+		}
+	}
+	if data, err := service.inaRepo.GetNewByTokens(ctx, DeviceTokens); err != nil {
+		skip("ina", err)
+	} else {
+		for _, row := range data {
+			add("ina", row.DeviceToken, row)
+		}
+	}
+	if data, err := service.pzemRepo.GetNewByTokens(ctx, DeviceTokens); err != nil {
+		skip("pzem", err)
+	} else {
+		for _, row := range data {
+			add("pzem", row.DeviceToken, row)
+		}
+	}
+	if data, err := service.thigrowRepo.GetNewByTokens(ctx, DeviceTokens); err != nil {
+		skip("thigrow", err)
+	} else {
+		for _, row := range data {
+			add("thigrow", row.DeviceToken, row)
+		}
+	}
+	if data, err := service.thmRepo.GetNewByTokens(ctx, DeviceTokens); err != nil {
+		skip("thm", err)
+	} else {
+		for _, row := range data {
+			add("thm", row.DeviceToken, row)
+		}
+	}
+
+	return readings, fixes
+}
+
+// GetLayer implements MapService.
+// Posisi perangkat diambil dari data Beitian terbaru, jika tidak ada maka
+// menggunakan lokasi statis yang terdaftar pada perangkat.
+func (service *mapService) GetLayer(ctx context.Context) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "MapService.GetLayer")
+	defer span.End()
+
+	var response helpers.Response
+
+	devices, err := service.deviceRepo.GetAll(ctx)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data perangkat", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceMapFailed), err)
+	}
+
+	tokens, err := service.beitianRepo.GetDeviceTokens(ctx)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil token perangkat beitian", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.DeviceMapFailed), err)
+	}
+
+	// Perangkat yang hanya mengirim data Beitian tetap ditampilkan walaupun belum terdaftar
+	registered := make(map[string]bool)
+	for _, device := range devices {
+		registered[device.DeviceToken] = true
+	}
+	for _, token := range tokens {
+		if !registered[token] {
+			devices = append(devices, models.Device{DeviceToken: token})
+		}
+	}
+
+	deviceTokens := make([]string, 0, len(devices))
+	for _, device := range devices {
+		deviceTokens = append(deviceTokens, device.DeviceToken)
+	}
+	readings, fixes := service.latestReadings(ctx, deviceTokens)
+
+	collection := helpers.NewFeatureCollection()
+	for _, device := range devices {
+		properties := map[string]interface{}{
+			"device_token": device.DeviceToken,
+			"name":         device.Name,
+			"farm":         device.Farm,
+			"readings":     readings[device.DeviceToken],
+		}
+
+		var lat, lng float64
+		located := false
+		if fix, ok := fixes[device.DeviceToken]; ok {
+			if fixLat, fixLng, err := helpers.ParseCoordinate(fix.Latitude, fix.Longitude); err == nil {
+				lat, lng, located = fixLat, fixLng, true
+				properties["location_source"] = LocationBeitian
+				properties["located_at"] = fix.CreatedAt
+			}
+		}
+		if !located {
+			if staticLat, staticLng, err := helpers.ParseCoordinate(device.Latitude, device.Longitude); err == nil {
+				lat, lng, located = staticLat, staticLng, true
+				properties["location_source"] = LocationStatic
+			}
+		}
+		if !located {
+			service.logger.InfoContext(ctx, "Perangkat tidak memiliki lokasi", "device_token", device.DeviceToken)
+			continue
+		}
+
+		collection.Features = append(collection.Features, helpers.NewPointFeature(lat, lng, properties))
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data peta perangkat")
+	response.SetMessage(ctx, i18n.DeviceMapFound)
+	response.Data = collection
+
+	return response, nil
+}
+
+type MapService interface {
+	GetLayer(ctx context.Context) (helpers.Response, error)
+}
+
+func NewMapService(db *gorm.DB, logger *slog.Logger) MapService {
+	return &mapService{
+		logger:      logger.With("component", "device"),
+		deviceRepo:  repositories.NewDeviceRepository(db, logger),
+		beitianRepo: beitianRepositories.NewBeitianRepository(db, logger),
+		bmpRepo:     bmpRepositories.NewBmpRepository(db, logger),
+		inaRepo:     inaRepositories.NewInaRepository(db, logger),
+		pzemRepo:    pzemRepositories.NewPzemRepository(db, logger),
+		thigrowRepo: thigrowRepositories.NewThigrowRepository(db, logger),
+		thmRepo:     thmRepositories.NewThmRepository(db, logger),
+	}
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"iot-golang/internal/geofence/models"
+	"iot-golang/internal/geofence/services"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type GeofenceController struct {
+	geofenceService services.GeofenceService
+	validate        v1.Validate
+}
+
+func (controller GeofenceController) validatePayload(c echo.Context) (*models.Payload, error) {
+	payloadValidator := new(models.Payload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return nil, helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	err := controller.validate.Struct(payloadValidator)
+	if err != nil {
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator, helpers.ValidationMessages{"min": i18n.FieldMinPoints, "len": i18n.FieldPointFormat})
+
+		return nil, helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	return payloadValidator, nil
+}
+
+func (controller GeofenceController) Create(c echo.Context) error {
+	payloadValidator, err := controller.validatePayload(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := controller.geofenceService.Create(c.Request().Context(), models.Geofence{Farm: payloadValidator.Farm, Name: payloadValidator.Name}, payloadValidator.Polygon)
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusCreated)
+}
+
+func (controller GeofenceController) Update(c echo.Context) error {
+	payloadValidator, err := controller.validatePayload(c)
+	if err != nil {
+		return err
+	}
+
+	idGeofence, _ := strconv.Atoi(c.Param("id"))
+	result, err := controller.geofenceService.Update(c.Request().Context(), int64(idGeofence), models.Geofence{Farm: payloadValidator.Farm, Name: payloadValidator.Name}, payloadValidator.Polygon)
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller GeofenceController) Delete(c echo.Context) error {
+	idGeofence, _ := strconv.Atoi(c.Param("id"))
+	result, err := controller.geofenceService.Delete(c.Request().Context(), int64(idGeofence))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller GeofenceController) GetAll(c echo.Context) error {
+	result, err := controller.geofenceService.GetAll(c.Request().Context(), c.QueryParam("farm"))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller GeofenceController) GetById(c echo.Context) error {
+	idGeofence, _ := strconv.Atoi(c.QueryParam("id"))
+	result, err := controller.geofenceService.GetById(c.Request().Context(), int64(idGeofence))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller GeofenceController) GetEventsByToken(c echo.Context) error {
+	idTokenBeitian := c.QueryParam("device_token")
+	result, err := controller.geofenceService.GetEventsByToken(c.Request().Context(), idTokenBeitian)
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func NewGeofenceController(db *gorm.DB, logger *slog.Logger) GeofenceController {
+	service := services.NewGeofenceService(db, logger)
+	controller := GeofenceController{
+		geofenceService: service,
+		validate:        *v1.New(),
+	}
+
+	return controller
+}
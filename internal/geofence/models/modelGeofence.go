@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Geofence struct {
+	Id        int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Farm      string    `json:"farm" gorm:"column:farm"`
+	Name      string    `json:"name" gorm:"column:name"`
+	Polygon   string    `json:"-" gorm:"column:polygon"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Geofence) TableName() string {
+	return "db_geofence"
+}
+
+// Points mengembalikan titik polygon dengan urutan GeoJSON [longitude, latitude].
+func (geofence Geofence) Points() ([][]float64, error) {
+	var points [][]float64
+	err := json.Unmarshal([]byte(geofence.Polygon), &points)
+	return points, err
+}
+
+func (geofence Geofence) MarshalJSON() ([]byte, error) {
+	type alias Geofence
+	points, _ := geofence.Points()
+	return json.Marshal(struct {
+		alias
+		Polygon [][]float64 `json:"polygon"`
+	}{alias(geofence), points})
+}
+
+type GeofenceEvent struct {
+	Id          int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	GeofenceId  int64     `json:"geofence_id" gorm:"column:geofence_id"`
+	DeviceToken string    `json:"device_token" gorm:"column:device_token"`
+	Event       string    `json:"event" gorm:"column:event"`
+	Latitude    string    `json:"latitude" gorm:"column:latitude"`
+	Longitude   string    `json:"longitude" gorm:"column:longitude"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (GeofenceEvent) TableName() string {
+	return "db_geofence_event"
+}
+
+const (
+	EventEnter = "enter"
+	EventExit  = "exit"
+)
@@ -0,0 +1,9 @@
+package models
+
+// Payload adalah payload untuk membuat dan mengubah geofence. Polygon berisi
+// minimal tiga titik [latitude, longitude].
+type Payload struct {
+	Farm    string      `json:"farm" validate:"required"`
+	Name    string      `json:"name" validate:"required"`
+	Polygon [][]float64 `json:"polygon" validate:"required,min=3,dive,len=2"`
+}
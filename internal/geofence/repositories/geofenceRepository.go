@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"iot-golang/internal/geofence/models"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type dbGeofence struct {
+	Conn *gorm.DB
+}
+
+// Create implements GeofenceRepository.
+func (db *dbGeofence) Create(ctx context.Context, geofence models.Geofence) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "geofence", "Create")).Create(&geofence).Error
+}
+
+// Delete implements GeofenceRepository.
+func (db *dbGeofence) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "geofence", "Delete")).Delete(&models.Geofence{Id: Id}).Error
+}
+
+// GetAll implements GeofenceRepository.
+func (db *dbGeofence) GetAll(ctx context.Context) ([]models.Geofence, error) {
+	var data []models.Geofence
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "geofence", "GetAll")).Find(&data)
+	return data, result.Error
+}
+
+// GetById implements GeofenceRepository.
+func (db *dbGeofence) GetById(ctx context.Context, Id int64) (models.Geofence, error) {
+	var data models.Geofence
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "geofence", "GetById")).Where("id", Id).First(&data)
+	return data, result.Error
+}
+
+// GetByFarm implements GeofenceRepository.
+func (db *dbGeofence) GetByFarm(ctx context.Context, Farm string) ([]models.Geofence, error) {
+	var data []models.Geofence
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "geofence", "GetByFarm")).Where("farm", Farm).Find(&data)
+	return data, result.Error
+}
+
+// Update implements GeofenceRepository.
+func (db *dbGeofence) Update(ctx context.Context, Id int64, geofence models.Geofence) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "geofence", "Update")).Where("id", Id).Updates(geofence).Error
+}
+
+// CreateEvent implements GeofenceRepository.
+func (db *dbGeofence) CreateEvent(ctx context.Context, event *models.GeofenceEvent) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "geofence", "CreateEvent")).Create(event).Error
+}
+
+// GetEventsByToken implements GeofenceRepository.
+func (db *dbGeofence) GetEventsByToken(ctx context.Context, DeviceToken string) ([]models.GeofenceEvent, error) {
+	var data []models.GeofenceEvent
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "geofence", "GetEventsByToken")).Where("device_token", DeviceToken).Order("created_at desc").Find(&data)
+	return data, result.Error
+}
+
+type GeofenceRepository interface {
+	Create(ctx context.Context, geofence models.Geofence) error
+	Update(ctx context.Context, Id int64, geofence models.Geofence) error
+	Delete(ctx context.Context, Id int64) error
+	GetById(ctx context.Context, Id int64) (models.Geofence, error)
+	GetByFarm(ctx context.Context, Farm string) ([]models.Geofence, error)
+	GetAll(ctx context.Context) ([]models.Geofence, error)
+	CreateEvent(ctx context.Context, event *models.GeofenceEvent) error
+	GetEventsByToken(ctx context.Context, DeviceToken string) ([]models.GeofenceEvent, error)
+}
+
+func NewGeofenceRepository(Conn *gorm.DB, logger *slog.Logger) GeofenceRepository {
+	return &dbGeofence{Conn: logging.Session(Conn, logger.With("repository", "geofence"))}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	beitianModels "iot-golang/internal/beitian/models"
+	"iot-golang/internal/geofence/models"
+	"iot-golang/internal/geofence/repositories"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/tracing"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type geofenceService struct {
+	logger       *slog.Logger
+	geofenceRepo repositories.GeofenceRepository
+}
+
+// Create implements GeofenceService.
+func (service *geofenceService) Create(ctx context.Context, geofence models.Geofence, polygon [][]float64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "GeofenceService.Create")
+	defer span.End()
+
+	var response helpers.Response
+
+	encoded, _ := json.Marshal(polygon)
+	geofence.Polygon = string(encoded)
+
+	if err := service.geofenceRepo.Create(ctx, geofence); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data geofence baru", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.GeofenceCreateFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil membuat data geofence baru")
+	response.SetMessage(ctx, i18n.GeofenceCreated)
+
+	return response, nil
+}
+
+// Update implements GeofenceService.
+func (service *geofenceService) Update(ctx context.Context, Id int64, geofence models.Geofence, polygon [][]float64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "GeofenceService.Update")
+	defer span.End()
+
+	var response helpers.Response
+
+	// Cek apakah data ada sebelum di update
+	_, findErr := service.geofenceRepo.GetById(ctx, Id)
+	if findErr != nil {
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data geofence", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.GeofenceNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data geofence", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.GeofenceGetByIDFailed, Id), findErr)
+	}
+
+	encoded, _ := json.Marshal(polygon)
+	geofence.Polygon = string(encoded)
+
+	// Data ditemukan, lakukan update
+	if err := service.geofenceRepo.Update(ctx, Id, geofence); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengubah data geofence", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.GeofenceUpdateFailed, Id), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengubah data geofence")
+	response.SetMessage(ctx, i18n.GeofenceUpdated)
+
+	return response, nil
+}
+
+// Delete implements GeofenceService.
+func (service *geofenceService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "GeofenceService.Delete")
+	defer span.End()
+
+	var response helpers.Response
+
+	// Cek apakah data ada sebelum dihapus
+	_, findErr := service.geofenceRepo.GetById(ctx, Id)
+	if findErr != nil {
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data geofence", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.GeofenceNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data geofence", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.GeofenceGetByIDFailed, Id), findErr)
+	}
+
+	// Data ditemukan, lakukan penghapusan
+	if err := service.geofenceRepo.Delete(ctx, Id); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal menghapus data geofence", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.GeofenceDeleteFailed, Id), err)
+	}
+
+	service.logger.InfoContext(ctx, "Data geofence berhasil dihapus")
+	response.SetMessage(ctx, i18n.GeofenceDeleted)
+
+	return response, nil
+}
+
+// GetAll implements GeofenceService.
+func (service *geofenceService) GetAll(ctx context.Context, Farm string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "GeofenceService.GetAll")
+	defer span.End()
+
+	var response helpers.Response
+	var data []models.Geofence
+	var err error
+
+	if Farm != "" {
+		data, err = service.geofenceRepo.GetByFarm(ctx, Farm)
+	} else {
+		data, err = service.geofenceRepo.GetAll(ctx)
+	}
+
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data geofence", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.GeofenceListFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil semua data geofence")
+	response.SetMessage(ctx, i18n.GeofenceListed)
+	response.Data = data
+	return response, nil
+}
+
+// GetById implements GeofenceService.
+func (service *geofenceService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "GeofenceService.GetById")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.geofenceRepo.GetById(ctx, Id)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data geofence", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.GeofenceNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data geofence", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.GeofenceGetByIDFailed, Id), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data geofence", "id", Id)
+	response.SetMessage(ctx, i18n.GeofenceFoundByID, Id)
+	response.Data = data
+
+	return response, nil
+}
+
+// GetEventsByToken implements GeofenceService.
+func (service *geofenceService) GetEventsByToken(ctx context.Context, DeviceToken string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "GeofenceService.GetEventsByToken")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.geofenceRepo.GetEventsByToken(ctx, DeviceToken)
+
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil event geofence", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.GeofenceEventsFailed, DeviceToken), err)
+	} else if len(data) == 0 {
+		service.logger.InfoContext(ctx, "Tidak menemukan event geofence", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.GeofenceEventsMissing, DeviceToken))
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil event geofence", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.GeofenceEventsFound, DeviceToken)
+	response.Data = data
+
+	return response, nil
+}
+
+// Evaluate implements GeofenceService.
+// Posisi sebelumnya dibandingkan dengan posisi terbaru untuk setiap geofence,
+// event enter/exit dicatat ketika status di dalam polygon berubah.
+func (service *geofenceService) Evaluate(ctx context.Context, previous *beitianModels.Beitian, current beitianModels.Beitian) []models.GeofenceEvent {
+	ctx, span := tracing.Start(ctx, "GeofenceService.Evaluate")
+	defer span.End()
+
+	lat, lng, err := helpers.ParseCoordinate(current.Latitude, current.Longitude)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Koordinat sensor tidak valid", "device_token", current.DeviceToken, logging.Error(err))
+		return nil
+	}
+
+	var prevLat, prevLng float64
+	hasPrevious := false
+	if previous != nil {
+		if prevLat, prevLng, err = helpers.ParseCoordinate(previous.Latitude, previous.Longitude); err == nil {
+			hasPrevious = true
+		}
+	}
+
+	geofences, err := service.geofenceRepo.GetAll(ctx)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data geofence", logging.Error(err))
+		return nil
+	}
+
+	var events []models.GeofenceEvent
+	for _, geofence := range geofences {
+		points, err := geofence.Points()
+		if err != nil || len(points) < 3 {
+			continue
+		}
+
+		wasInside := hasPrevious && helpers.PointInPolygon(prevLat, prevLng, points)
+		isInside := helpers.PointInPolygon(lat, lng, points)
+		if wasInside == isInside {
+			continue
+		}
+
+		event := models.GeofenceEvent{
+			GeofenceId:  geofence.Id,
+			DeviceToken: current.DeviceToken,
+			Event:       models.EventExit,
+			Latitude:    current.Latitude,
+			Longitude:   current.Longitude,
+		}
+		if isInside {
+			event.Event = models.EventEnter
+		}
+
+		if err := service.geofenceRepo.CreateEvent(ctx, &event); err != nil {
+			service.logger.ErrorContext(ctx, "Gagal menyimpan event geofence", "device_token", current.DeviceToken, "event", event.Event, "geofence", geofence.Name, logging.Error(err))
+			continue
+		}
+		service.logger.InfoContext(ctx, "Sensor melewati batas geofence", "device_token", current.DeviceToken, "event", event.Event, "geofence", geofence.Name)
+		events = append(events, event)
+	}
+
+	return events
+}
+
+type GeofenceService interface {
+	Create(ctx context.Context, geofence models.Geofence, polygon [][]float64) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, geofence models.Geofence, polygon [][]float64) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	GetAll(ctx context.Context, Farm string) (helpers.Response, error)
+	GetEventsByToken(ctx context.Context, DeviceToken string) (helpers.Response, error)
+	Evaluate(ctx context.Context, previous *beitianModels.Beitian, current beitianModels.Beitian) []models.GeofenceEvent
+}
+
+func NewGeofenceService(db *gorm.DB, logger *slog.Logger) GeofenceService {
+	return &geofenceService{logger: logger.With("component", "geofence"), geofenceRepo: repositories.NewGeofenceRepository(db, logger)}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"iot-golang/internal/graph"
+	"iot-golang/internal/graph/models"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"log/slog"
+	"net/http"
+
+	v1 "github.com/go-playground/validator/v10"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type GraphController struct {
+	schema   *graphql.Schema
+	validate v1.Validate
+}
+
+// Query menjalankan query GraphQL. Error pada query dikirim di field errors
+// dengan status 200, hanya body yang tidak valid yang ditolak dengan 400.
+func (controller GraphController) Query(c echo.Context) error {
+	payloadValidator := new(models.QueryPayload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"query": i18n.T(c.Request().Context(), i18n.FieldRequired, "query")})
+	}
+
+	response := controller.schema.Exec(c.Request().Context(), payloadValidator.Query, payloadValidator.OperationName, payloadValidator.Variables)
+	return c.JSON(http.StatusOK, response)
+}
+
+func NewGraphController(db *gorm.DB, logger *slog.Logger) GraphController {
+	controller := GraphController{
+		schema:   graph.NewSchema(db, logger),
+		validate: *v1.New(),
+	}
+
+	return controller
+}
@@ -0,0 +1,8 @@
+package models
+
+// QueryPayload adalah body request GraphQL sesuai konvensi GraphQL over HTTP.
+type QueryPayload struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
@@ -0,0 +1,275 @@
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	beitianServices "iot-golang/internal/beitian/services"
+	bmpServices "iot-golang/internal/bmp/services"
+	deviceModels "iot-golang/internal/device/models"
+	deviceServices "iot-golang/internal/device/services"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	inaServices "iot-golang/internal/ina/services"
+	pzemServices "iot-golang/internal/pzem/services"
+	thigrowServices "iot-golang/internal/thigrow/services"
+	thmServices "iot-golang/internal/thm/services"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+//go:embed schema.graphql
+var schema string
+
+var (
+	// DefaultLimit adalah jumlah data sensor bila first dan last tidak diisi.
+	DefaultLimit int32 = 100
+	// MaxLimit adalah batas first dan last agar satu query tidak membaca
+	// seluruh tabel sensor.
+	MaxLimit int32 = 1000
+	// MaxDepth membatasi kedalaman query bersarang.
+	MaxDepth = 6
+)
+
+// Resolver adalah root resolver GraphQL. Setiap field dibaca lewat service
+// yang juga dipakai route REST.
+type Resolver struct {
+	deviceService  deviceServices.DeviceService
+	beitianService beitianServices.BeitianService
+	bmpService     bmpServices.BmpService
+	inaService     inaServices.InaService
+	pzemService    pzemServices.PzemService
+	thigrowService thigrowServices.ThigrowService
+	thmService     thmServices.ThmService
+}
+
+type devicesArgs struct {
+	Farm   *string
+	First  *int32
+	Offset *int32
+}
+
+// Devices menyaring perangkat per kebun dan memotongnya sesuai first dan
+// offset. Registry perangkat kecil sehingga cukup disaring di memori.
+func (resolver *Resolver) Devices(ctx context.Context, args devicesArgs) ([]*deviceResolver, error) {
+	result, err := resolver.deviceService.GetAll(ctx)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	first, err := pageArgument(ctx, "first", args.First, MaxLimit)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := pageArgument(ctx, "offset", args.Offset, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _ := result.Data.([]deviceModels.Device)
+	devices := []*deviceResolver{}
+	for _, device := range data {
+		if args.Farm != nil && device.Farm != *args.Farm {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if args.First != nil && int32(len(devices)) >= first {
+			break
+		}
+		devices = append(devices, &deviceResolver{Device: device, resolver: resolver})
+	}
+	return devices, nil
+}
+
+type deviceArgs struct {
+	Token string
+}
+
+func (resolver *Resolver) Device(ctx context.Context, args deviceArgs) (*deviceResolver, error) {
+	result, err := resolver.deviceService.GetByToken(ctx, args.Token)
+	var appError *helpers.Error
+	if errors.As(err, &appError) && appError.Kind == helpers.KindNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	device, _ := result.Data.(deviceModels.Device)
+	return &deviceResolver{Device: device, resolver: resolver}, nil
+}
+
+type deviceResolver struct {
+	deviceModels.Device
+	resolver *Resolver
+}
+
+func (device *deviceResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(device.Id, 10))
+}
+
+func (device *deviceResolver) ExpectedInterval() int32 {
+	return int32(device.Device.ExpectedInterval)
+}
+
+func (device *deviceResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: device.Device.CreatedAt}
+}
+
+// readingArgs adalah argumen field sensor pada Query.
+type readingArgs struct {
+	DeviceToken *string
+	Start       *graphql.Time
+	End         *graphql.Time
+	Quality     *[]string
+	First       *int32
+	Last        *int32
+	Offset      *int32
+	Units       *string
+}
+
+func (args readingArgs) page() pageArgs {
+	return pageArgs{Start: args.Start, End: args.End, Quality: args.Quality, First: args.First, Last: args.Last, Offset: args.Offset, Units: args.Units}
+}
+
+func (args readingArgs) deviceToken() string {
+	if args.DeviceToken == nil {
+		return ""
+	}
+	return *args.DeviceToken
+}
+
+// pageArgs adalah argumen field sensor pada Device.
+type pageArgs struct {
+	Start   *graphql.Time
+	End     *graphql.Time
+	Quality *[]string
+	First   *int32
+	Last    *int32
+	Offset  *int32
+	Units   *string
+}
+
+// readingUnits membaca argumen units untuk field sensor dengan metadata fields.
+func readingUnits(ctx context.Context, fields []helpers.Field, args pageArgs) (helpers.Units, error) {
+	value := ""
+	if args.Units != nil {
+		value = *args.Units
+	}
+	units, err := helpers.ParseUnits(fields, value)
+	if err != nil {
+		return units, queryError(ctx, err)
+	}
+	return units, nil
+}
+
+// readingQuery menerjemahkan argumen GraphQL ke helpers.ReadingQuery. Tanpa
+// first dan last, DefaultLimit data terbaru yang diambil.
+func readingQuery(ctx context.Context, DeviceToken string, args pageArgs) (helpers.ReadingQuery, error) {
+	query := helpers.ReadingQuery{DeviceToken: DeviceToken, Limit: int(DefaultLimit), Newest: true}
+
+	if args.First != nil && args.Last != nil {
+		return query, invalidArgument(i18n.T(ctx, i18n.GraphQLPageConflict))
+	}
+	if args.First != nil {
+		first, err := pageArgument(ctx, "first", args.First, MaxLimit)
+		if err != nil {
+			return query, err
+		}
+		query.Limit, query.Newest = int(first), false
+	}
+	if args.Last != nil {
+		last, err := pageArgument(ctx, "last", args.Last, MaxLimit)
+		if err != nil {
+			return query, err
+		}
+		query.Limit = int(last)
+	}
+	offset, err := pageArgument(ctx, "offset", args.Offset, math.MaxInt32)
+	if err != nil {
+		return query, err
+	}
+	query.Offset = int(offset)
+
+	if args.Start != nil {
+		query.Start = args.Start.Time
+	}
+	if args.End != nil {
+		query.End = args.End.Time
+	}
+	if args.Quality != nil {
+		quality, err := helpers.ParseQuality(strings.Join(*args.Quality, ","))
+		if err != nil {
+			return query, queryError(ctx, err)
+		}
+		query.Quality = quality
+	}
+
+	return query, nil
+}
+
+// pageArgument memeriksa argumen paginasi, nilai kosong dianggap 0.
+func pageArgument(ctx context.Context, name string, value *int32, max int32) (int32, error) {
+	if value == nil {
+		return 0, nil
+	}
+	if *value < 0 || *value > max {
+		return 0, invalidArgument(i18n.T(ctx, i18n.GraphQLPageInvalid, name, max))
+	}
+	return *value, nil
+}
+
+// resolverError adalah error GraphQL dengan kode katalog dan status HTTP pada
+// extensions, mengikuti kode error route REST.
+type resolverError struct {
+	message string
+	code    string
+	status  int
+}
+
+func (e resolverError) Error() string {
+	return e.message
+}
+
+func (e resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code, "status": e.status}
+}
+
+func invalidArgument(message string) error {
+	return resolverError{message: message, code: i18n.RequestRejected, status: http.StatusBadRequest}
+}
+
+// queryError menerjemahkan error service ke bahasa request. Detail error
+// internal tidak dikirim ke client karena sudah dicatat oleh service.
+func queryError(ctx context.Context, err error) error {
+	var appError *helpers.Error
+	if errors.As(err, &appError) {
+		return resolverError{message: appError.Message.Translate(i18n.Language(ctx)), code: appError.Message.Code, status: appError.Status()}
+	}
+	return resolverError{message: i18n.T(ctx, i18n.GraphQLQueryFailed), code: i18n.GraphQLQueryFailed, status: http.StatusInternalServerError}
+}
+
+// NewSchema menyusun schema GraphQL dengan resolver yang memakai service sensor
+// dan perangkat.
+func NewSchema(db *gorm.DB, logger *slog.Logger) *graphql.Schema {
+	resolver := &Resolver{
+		deviceService:  deviceServices.NewDeviceService(db, logger),
+		beitianService: beitianServices.NewBeitianService(db, logger),
+		bmpService:     bmpServices.NewBmpService(db, logger),
+		inaService:     inaServices.NewInaService(db, logger),
+		pzemService:    pzemServices.NewPzemService(db, logger),
+		thigrowService: thigrowServices.NewThigrowService(db, logger),
+		thmService:     thmServices.NewThmService(db, logger),
+	}
+
+	return graphql.MustParseSchema(schema, resolver, graphql.UseFieldResolvers(), graphql.MaxDepth(MaxDepth))
+}
@@ -0,0 +1,278 @@
+package graph
+
+import (
+	"context"
+	beitianModels "iot-golang/internal/beitian/models"
+	bmpModels "iot-golang/internal/bmp/models"
+	inaModels "iot-golang/internal/ina/models"
+	pzemModels "iot-golang/internal/pzem/models"
+	thigrowModels "iot-golang/internal/thigrow/models"
+	thmModels "iot-golang/internal/thm/models"
+	"strconv"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type beitianResolver struct {
+	beitianModels.Beitian
+}
+
+func (reading *beitianResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(reading.Id, 10))
+}
+
+func (reading *beitianResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: reading.Beitian.CreatedAt}
+}
+
+func (resolver *Resolver) Beitian(ctx context.Context, args readingArgs) ([]*beitianResolver, error) {
+	return resolver.beitian(ctx, args.deviceToken(), args.page())
+}
+
+func (device *deviceResolver) Beitian(ctx context.Context, args pageArgs) ([]*beitianResolver, error) {
+	return device.resolver.beitian(ctx, device.DeviceToken, args)
+}
+
+func (resolver *Resolver) beitian(ctx context.Context, DeviceToken string, args pageArgs) ([]*beitianResolver, error) {
+	query, err := readingQuery(ctx, DeviceToken, args)
+	if err != nil {
+		return nil, err
+	}
+	units, err := readingUnits(ctx, beitianModels.Fields, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := resolver.beitianService.Query(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	data, _ := result.Data.([]beitianModels.Beitian)
+	readings := make([]*beitianResolver, len(data))
+	for i := range data {
+		units.Apply(&data[i])
+		readings[i] = &beitianResolver{data[i]}
+	}
+	return readings, nil
+}
+
+type bmpResolver struct {
+	bmpModels.Bmp
+}
+
+func (reading *bmpResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(reading.Id, 10))
+}
+
+func (reading *bmpResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: reading.Bmp.CreatedAt}
+}
+
+func (resolver *Resolver) Bmp(ctx context.Context, args readingArgs) ([]*bmpResolver, error) {
+	return resolver.bmp(ctx, args.deviceToken(), args.page())
+}
+
+func (device *deviceResolver) Bmp(ctx context.Context, args pageArgs) ([]*bmpResolver, error) {
+	return device.resolver.bmp(ctx, device.DeviceToken, args)
+}
+
+func (resolver *Resolver) bmp(ctx context.Context, DeviceToken string, args pageArgs) ([]*bmpResolver, error) {
+	query, err := readingQuery(ctx, DeviceToken, args)
+	if err != nil {
+		return nil, err
+	}
+	units, err := readingUnits(ctx, bmpModels.Fields, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := resolver.bmpService.Query(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	data, _ := result.Data.([]bmpModels.Bmp)
+	readings := make([]*bmpResolver, len(data))
+	for i := range data {
+		units.Apply(&data[i])
+		readings[i] = &bmpResolver{data[i]}
+	}
+	return readings, nil
+}
+
+type inaResolver struct {
+	inaModels.Ina
+}
+
+func (reading *inaResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(reading.Id, 10))
+}
+
+func (reading *inaResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: reading.Ina.CreatedAt}
+}
+
+func (resolver *Resolver) Ina(ctx context.Context, args readingArgs) ([]*inaResolver, error) {
+	return resolver.ina(ctx, args.deviceToken(), args.page())
+}
+
+func (device *deviceResolver) Ina(ctx context.Context, args pageArgs) ([]*inaResolver, error) {
+	return device.resolver.ina(ctx, device.DeviceToken, args)
+}
+
+func (resolver *Resolver) ina(ctx context.Context, DeviceToken string, args pageArgs) ([]*inaResolver, error) {
+	query, err := readingQuery(ctx, DeviceToken, args)
+	if err != nil {
+		return nil, err
+	}
+	units, err := readingUnits(ctx, inaModels.Fields, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := resolver.inaService.Query(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	data, _ := result.Data.([]inaModels.Ina)
+	readings := make([]*inaResolver, len(data))
+	for i := range data {
+		units.Apply(&data[i])
+		readings[i] = &inaResolver{data[i]}
+	}
+	return readings, nil
+}
+
+type pzemResolver struct {
+	pzemModels.Pzem
+}
+
+func (reading *pzemResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(reading.Id, 10))
+}
+
+func (reading *pzemResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: reading.Pzem.CreatedAt}
+}
+
+func (resolver *Resolver) Pzem(ctx context.Context, args readingArgs) ([]*pzemResolver, error) {
+	return resolver.pzem(ctx, args.deviceToken(), args.page())
+}
+
+func (device *deviceResolver) Pzem(ctx context.Context, args pageArgs) ([]*pzemResolver, error) {
+	return device.resolver.pzem(ctx, device.DeviceToken, args)
+}
+
+func (resolver *Resolver) pzem(ctx context.Context, DeviceToken string, args pageArgs) ([]*pzemResolver, error) {
+	query, err := readingQuery(ctx, DeviceToken, args)
+	if err != nil {
+		return nil, err
+	}
+	units, err := readingUnits(ctx, pzemModels.Fields, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := resolver.pzemService.Query(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	data, _ := result.Data.([]pzemModels.Pzem)
+	readings := make([]*pzemResolver, len(data))
+	for i := range data {
+		units.Apply(&data[i])
+		readings[i] = &pzemResolver{data[i]}
+	}
+	return readings, nil
+}
+
+type thigrowResolver struct {
+	thigrowModels.Thigrow
+}
+
+func (reading *thigrowResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(reading.Id, 10))
+}
+
+func (reading *thigrowResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: reading.Thigrow.CreatedAt}
+}
+
+func (resolver *Resolver) Thigrow(ctx context.Context, args readingArgs) ([]*thigrowResolver, error) {
+	return resolver.thigrow(ctx, args.deviceToken(), args.page())
+}
+
+func (device *deviceResolver) Thigrow(ctx context.Context, args pageArgs) ([]*thigrowResolver, error) {
+	return device.resolver.thigrow(ctx, device.DeviceToken, args)
+}
+
+func (resolver *Resolver) thigrow(ctx context.Context, DeviceToken string, args pageArgs) ([]*thigrowResolver, error) {
+	query, err := readingQuery(ctx, DeviceToken, args)
+	if err != nil {
+		return nil, err
+	}
+	units, err := readingUnits(ctx, thigrowModels.Fields, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := resolver.thigrowService.Query(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	data, _ := result.Data.([]thigrowModels.Thigrow)
+	readings := make([]*thigrowResolver, len(data))
+	for i := range data {
+		units.Apply(&data[i])
+		readings[i] = &thigrowResolver{data[i]}
+	}
+	return readings, nil
+}
+
+type thmResolver struct {
+	thmModels.Thm
+}
+
+func (reading *thmResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(reading.Id, 10))
+}
+
+func (reading *thmResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: reading.Thm.CreatedAt}
+}
+
+func (resolver *Resolver) Thm(ctx context.Context, args readingArgs) ([]*thmResolver, error) {
+	return resolver.thm(ctx, args.deviceToken(), args.page())
+}
+
+func (device *deviceResolver) Thm(ctx context.Context, args pageArgs) ([]*thmResolver, error) {
+	return device.resolver.thm(ctx, device.DeviceToken, args)
+}
+
+func (resolver *Resolver) thm(ctx context.Context, DeviceToken string, args pageArgs) ([]*thmResolver, error) {
+	query, err := readingQuery(ctx, DeviceToken, args)
+	if err != nil {
+		return nil, err
+	}
+	units, err := readingUnits(ctx, thmModels.Fields, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := resolver.thmService.Query(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	data, _ := result.Data.([]thmModels.Thm)
+	readings := make([]*thmResolver, len(data))
+	for i := range data {
+		units.Apply(&data[i])
+		readings[i] = &thmResolver{data[i]}
+	}
+	return readings, nil
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"iot-golang/internal/health/models"
+	"iot-golang/internal/health/services"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type HealthController struct {
+	healthService services.HealthService
+}
+
+func (controller HealthController) Healthz(c echo.Context) error {
+	return controller.healthService.Live(c.Request().Context()).JSON(c, http.StatusOK)
+}
+
+func (controller HealthController) Readyz(c echo.Context) error {
+	result, err := controller.healthService.Ready(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	// Perangkat orkestrasi hanya membaca status HTTP, bukan body
+	if readiness, ok := result.Data.(models.Readiness); ok && readiness.Status != models.CheckOK {
+		return result.JSON(c, http.StatusServiceUnavailable)
+	}
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller HealthController) Info(c echo.Context) error {
+	result, err := controller.healthService.Info(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func NewHealthController(db *gorm.DB, workers services.WorkerSource, logger *slog.Logger) HealthController {
+	controller := HealthController{
+		healthService: services.NewHealthService(db, workers, logger),
+	}
+
+	return controller
+}
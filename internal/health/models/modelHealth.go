@@ -0,0 +1,71 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	CheckOK   = "ok"
+	CheckFail = "fail"
+)
+
+// Check adalah hasil satu pemeriksaan kesiapan.
+type Check struct {
+	Name   string      `json:"name"`
+	Status string      `json:"status"`
+	Error  string      `json:"error,omitempty"`
+	Detail interface{} `json:"detail,omitempty"`
+}
+
+// Readiness berisi hasil seluruh pemeriksaan /readyz.
+type Readiness struct {
+	Status string  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// DBStats adalah statistik pool koneksi database.
+type DBStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+}
+
+func NewDBStats(stats sql.DBStats) DBStats {
+	return DBStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// SensorCount adalah jumlah baris pada tabel satu jenis sensor.
+type SensorCount struct {
+	Sensor string `json:"sensor"`
+	Table  string `json:"table"`
+	Rows   int64  `json:"rows"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Info berisi data diagnostik untuk /debug/info.
+type Info struct {
+	Version   string        `json:"version"`
+	GoVersion string        `json:"go_version"`
+	StartedAt time.Time     `json:"started_at"`
+	Uptime    string        `json:"uptime"`
+	Database  DBStats       `json:"database"`
+	Sensors   []SensorCount `json:"sensors"`
+	Workers   interface{}   `json:"workers"`
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"iot-golang/internal/metrics"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type dbHealth struct {
+	Conn *gorm.DB
+}
+
+// Ping implements HealthRepository.
+func (db *dbHealth) Ping(ctx context.Context) error {
+	sqlDB, err := db.Conn.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats implements HealthRepository.
+func (db *dbHealth) Stats() (sql.DBStats, error) {
+	sqlDB, err := db.Conn.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDB.Stats(), nil
+}
+
+// CountRows implements HealthRepository.
+func (db *dbHealth) CountRows(ctx context.Context, Table string) (int64, error) {
+	var count int64
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "health", "CountRows")).Table(Table).Count(&count)
+	return count, result.Error
+}
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	Stats() (sql.DBStats, error)
+	CountRows(ctx context.Context, Table string) (int64, error)
+}
+
+func NewHealthRepository(Conn *gorm.DB, logger *slog.Logger) HealthRepository {
+	return &dbHealth{Conn: Conn}
+}
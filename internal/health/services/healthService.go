@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"iot-golang/config"
+	"iot-golang/internal/health/models"
+	"iot-golang/internal/health/repositories"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/lifecycle"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/migrations"
+	"iot-golang/internal/sensors"
+	"iot-golang/internal/tracing"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkerSource menyediakan status worker background, dipenuhi oleh lifecycle.Manager.
+type WorkerSource interface {
+	Workers() []lifecycle.WorkerStatus
+}
+
+type healthService struct {
+	logger     *slog.Logger
+	healthRepo repositories.HealthRepository
+	migrator   *migrations.Migrator
+	workers    WorkerSource
+}
+
+func (service *healthService) checkDatabase(ctx context.Context) models.Check {
+	check := models.Check{Name: "database", Status: models.CheckOK}
+	if err := service.healthRepo.Ping(ctx); err != nil {
+		check.Status = models.CheckFail
+		check.Error = err.Error()
+	}
+	return check
+}
+
+func (service *healthService) checkMigrations(ctx context.Context) models.Check {
+	check := models.Check{Name: "migrations", Status: models.CheckOK}
+	pending, err := service.migrator.Pending(ctx)
+	if err != nil {
+		check.Status = models.CheckFail
+		check.Error = err.Error()
+		return check
+	}
+
+	if len(pending) > 0 {
+		versions := make([]string, len(pending))
+		for i, migration := range pending {
+			versions[i] = fmt.Sprintf("%04d_%s", migration.Version, migration.Name)
+		}
+		check.Status = models.CheckFail
+		check.Error = fmt.Sprintf("%d migration belum dijalankan", len(pending))
+		check.Detail = versions
+	}
+	return check
+}
+
+func (service *healthService) checkWorkers() models.Check {
+	check := models.Check{Name: "workers", Status: models.CheckOK}
+	statuses := service.workers.Workers()
+	for _, status := range statuses {
+		if !status.Alive {
+			check.Status = models.CheckFail
+			check.Error = fmt.Sprintf("worker %s tidak berjalan", status.Name)
+			break
+		}
+	}
+	check.Detail = statuses
+	return check
+}
+
+// Live implements HealthService.
+func (service *healthService) Live(ctx context.Context) helpers.Response {
+	response := helpers.Response{Data: map[string]string{"status": models.CheckOK}}
+	response.SetMessage(ctx, i18n.HealthLive)
+	return response
+}
+
+// Ready implements HealthService.
+func (service *healthService) Ready(ctx context.Context) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "HealthService.Ready")
+	defer span.End()
+
+	var response helpers.Response
+	readiness := models.Readiness{Status: models.CheckOK}
+
+	database := service.checkDatabase(ctx)
+	readiness.Checks = append(readiness.Checks, database)
+	if database.Status == models.CheckOK {
+		readiness.Checks = append(readiness.Checks, service.checkMigrations(ctx))
+	}
+	readiness.Checks = append(readiness.Checks, service.checkWorkers())
+
+	for _, check := range readiness.Checks {
+		if check.Status != models.CheckOK {
+			readiness.Status = models.CheckFail
+			service.logger.ErrorContext(ctx, "Pemeriksaan kesiapan gagal", "check", check.Name, "error", check.Error)
+		}
+	}
+
+	if readiness.Status == models.CheckOK {
+		response.SetMessage(ctx, i18n.HealthReady)
+	} else {
+		response.SetMessage(ctx, i18n.HealthNotReady)
+	}
+	response.Data = readiness
+	return response, nil
+}
+
+// Info implements HealthService.
+func (service *healthService) Info(ctx context.Context) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "HealthService.Info")
+	defer span.End()
+
+	var response helpers.Response
+	info := models.Info{
+		Version:   config.Version,
+		GoVersion: runtime.Version(),
+		StartedAt: config.StartedAt,
+		Uptime:    time.Since(config.StartedAt).Round(time.Second).String(),
+		Workers:   service.workers.Workers(),
+	}
+
+	stats, err := service.healthRepo.Stats()
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil statistik database", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.HealthInfoFailed), err)
+	}
+
+	info.Database = models.NewDBStats(stats)
+
+	for _, sensor := range sensors.All {
+		count := models.SensorCount{Sensor: sensor.Name, Table: sensor.Table}
+		rows, err := service.healthRepo.CountRows(ctx, sensor.Table)
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal menghitung data sensor", "sensor", sensor.Name, logging.Error(err))
+			count.Error = err.Error()
+		}
+		count.Rows = rows
+		info.Sensors = append(info.Sensors, count)
+	}
+
+	response.SetMessage(ctx, i18n.HealthInfo)
+	response.Data = info
+	return response, nil
+}
+
+type HealthService interface {
+	Live(ctx context.Context) helpers.Response
+	Ready(ctx context.Context) (helpers.Response, error)
+	Info(ctx context.Context) (helpers.Response, error)
+}
+
+func NewHealthService(db *gorm.DB, workers WorkerSource, logger *slog.Logger) HealthService {
+	return &healthService{
+		logger:     logger.With("component", "health"),
+		healthRepo: repositories.NewHealthRepository(db, logger),
+		migrator:   migrations.NewMigrator(db, logger),
+		workers:    workers,
+	}
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorStatus menentukan status response dari error database. Query yang
+// melewati batas waktu menghasilkan 504, sedangkan request yang dibatalkan
+// client menghasilkan 503.
+func ErrorStatus(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return 504
+	}
+	if errors.Is(err, context.Canceled) {
+		return 503
+	}
+	return 500
+}
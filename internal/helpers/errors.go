@@ -0,0 +1,87 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"iot-golang/internal/i18n"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+type ErrorKind string
+
+const (
+	KindValidation ErrorKind = "validation"
+	KindNotFound   ErrorKind = "not_found"
+	KindConflict   ErrorKind = "conflict"
+	KindInternal   ErrorKind = "internal"
+)
+
+// Error adalah error yang dikembalikan service. HTTPErrorHandler memetakan
+// Kind ke status HTTP dan menyusun body response dari Message dan Details.
+// Message diterjemahkan sesuai bahasa request saat response dikirim.
+type Error struct {
+	Kind    ErrorKind
+	Message i18n.Message
+	Details interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message.String() + ": " + e.Err.Error()
+	}
+	return e.Message.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status mengembalikan status HTTP untuk error.
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	}
+	return ErrorStatus(e.Err)
+}
+
+// Validation dipakai untuk request yang ditolak, details berisi error per field.
+func Validation(message i18n.Message, details interface{}) error {
+	return &Error{Kind: KindValidation, Message: message, Details: details}
+}
+
+// NotFound dipakai ketika data yang diminta tidak ditemukan.
+func NotFound(message i18n.Message) error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+// Conflict dipakai ketika data bertabrakan dengan data yang sudah ada.
+func Conflict(message i18n.Message, err error) error {
+	return &Error{Kind: KindConflict, Message: message, Err: err}
+}
+
+// Internal membungkus error database. Pelanggaran unique key menjadi Conflict
+// dan batas waktu query tetap dipetakan oleh ErrorStatus.
+func Internal(message i18n.Message, err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return Conflict(message, err)
+	}
+	return &Error{Kind: KindInternal, Message: message, Err: err}
+}
+
+// ErrorMessage mengembalikan pesan Error dalam bahasa pada ctx tanpa detail
+// error database.
+func ErrorMessage(ctx context.Context, err error) string {
+	var appError *Error
+	if errors.As(err, &appError) {
+		return appError.Message.Translate(i18n.Language(ctx))
+	}
+	return err.Error()
+}
@@ -0,0 +1,148 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iot-golang/internal/i18n"
+	"net/http"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// ExportWriter menulis data ekspor baris per baris.
+type ExportWriter interface {
+	WriteRow(row []string) error
+	Close() error
+}
+
+// ExportHeaders menyusun judul kolom ekspor untuk id, device_token, created_at, field sensor dan quality.
+func ExportHeaders(fields []Field) []string {
+	headers := []string{"id", "device_token", "created_at"}
+	for _, field := range fields {
+		headers = append(headers, field.Header())
+	}
+	return append(headers, "quality")
+}
+
+// ExportContentType mengembalikan content type dan ekstensi file untuk format ekspor.
+func ExportContentType(format string) (string, error) {
+	switch format {
+	case ExportCSV:
+		return "text/csv; charset=utf-8", nil
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+	return "", Validation(i18n.Msg(i18n.ExportFormatUnknown, format), nil)
+}
+
+// ExportFilename menyusun nama file ekspor dari tanggal pertama dan terakhir
+// rentang. End tidak termasuk dalam rentang sehingga tanggal terakhir diambil
+// dari waktu sebelum End.
+func ExportFilename(name string, start time.Time, end time.Time, format string) string {
+	return fmt.Sprintf("%s_%s_%s.%s", name, start.Format("20060102"), end.Add(-time.Nanosecond).Format("20060102"), format)
+}
+
+// ExportResponse menunda header response ekspor sampai byte pertama ditulis.
+// Error sebelum itu masih bisa dikirim sebagai response error biasa tanpa
+// header Content-Disposition.
+type ExportResponse struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	committed   bool
+}
+
+func (response *ExportResponse) Write(p []byte) (int, error) {
+	if !response.committed {
+		response.committed = true
+		response.w.Header().Set("Content-Type", response.contentType)
+		response.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", response.filename))
+		response.w.WriteHeader(http.StatusOK)
+	}
+	return response.w.Write(p)
+}
+
+func NewExportResponse(w http.ResponseWriter, contentType string, filename string) *ExportResponse {
+	return &ExportResponse{w: w, contentType: contentType, filename: filename}
+}
+
+func NewExportWriter(format string, w io.Writer, sheet string) (ExportWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvExportWriter{writer: csv.NewWriter(w)}, nil
+	case ExportXLSX:
+		file := excelize.NewFile()
+		if err := file.SetSheetName("Sheet1", sheet); err != nil {
+			return nil, err
+		}
+		stream, err := file.NewStreamWriter(sheet)
+		if err != nil {
+			return nil, err
+		}
+		return &xlsxExportWriter{file: file, stream: stream, out: w}, nil
+	}
+	return nil, Validation(i18n.Msg(i18n.ExportFormatUnknown, format), nil)
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+	rows   int
+}
+
+func (export *csvExportWriter) WriteRow(row []string) error {
+	if err := export.writer.Write(row); err != nil {
+		return err
+	}
+
+	// Flush berkala agar data langsung dikirim ke client
+	export.rows++
+	if export.rows%500 == 0 {
+		export.writer.Flush()
+	}
+	return export.writer.Error()
+}
+
+func (export *csvExportWriter) Close() error {
+	export.writer.Flush()
+	return export.writer.Error()
+}
+
+// xlsxExportWriter memakai StreamWriter excelize yang menyimpan baris ke file
+// sementara sehingga seluruh data tidak ditahan di memori.
+type xlsxExportWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	out    io.Writer
+	rows   int
+}
+
+func (export *xlsxExportWriter) WriteRow(row []string) error {
+	export.rows++
+	cell, err := excelize.CoordinatesToCellName(1, export.rows)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+	return export.stream.SetRow(cell, values)
+}
+
+func (export *xlsxExportWriter) Close() error {
+	defer export.file.Close()
+
+	if err := export.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := export.file.WriteTo(export.out)
+	return err
+}
@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"math"
+	"strconv"
+)
+
+// Field menyimpan metadata kolom sensor: nama json, nama kolom database, satuan,
+// rentang nilai yang masuk akal secara fisik dan batas perubahan per menit
+// untuk deteksi anomali. MaxRate 0 berarti perubahan tidak dibatasi. Integer
+// menandai kolom database bertipe bilangan bulat.
+type Field struct {
+	Name    string
+	Column  string
+	Unit    string
+	Range   *Range
+	MaxRate float64
+	Integer bool
+}
+
+// Value mengembalikan nilai yang disimpan ke kolom field: bilangan bulat yang
+// dibulatkan untuk kolom Integer, selain itu angka dalam bentuk teks.
+func (field Field) Value(number float64) interface{} {
+	if field.Integer {
+		return int64(math.Round(number))
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// Header mengembalikan judul kolom beserta satuan, contoh "tekanan_udara (hPa)".
+func (field Field) Header() string {
+	if field.Unit == "" {
+		return field.Name
+	}
+	return field.Name + " (" + field.Unit + ")"
+}
+
+// Range adalah batas nilai sebuah field. Nilai di luar Min..Max tidak mungkin
+// terukur sehingga dianggap bad, sedangkan nilai di luar SuspectMin..SuspectMax
+// masih mungkin tetapi dianggap suspect.
+type Range struct {
+	Min        float64
+	Max        float64
+	SuspectMin float64
+	SuspectMax float64
+}
+
+// Between membuat Range dengan batas suspect yang sama dengan batas fisiknya.
+func Between(min float64, max float64) *Range {
+	return &Range{Min: min, Max: max, SuspectMin: min, SuspectMax: max}
+}
+
+// Suspect mengembalikan salinan Range dengan batas suspect yang lebih sempit.
+func (r *Range) Suspect(min float64, max float64) *Range {
+	return &Range{Min: r.Min, Max: r.Max, SuspectMin: min, SuspectMax: max}
+}
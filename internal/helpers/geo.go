@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const earthRadiusMeter = 6371000.0
+
+// ParseCoordinate mengubah nilai latitude dan longitude berbentuk string menjadi float64.
+func ParseCoordinate(latitude string, longitude string) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return lat, lng, nil
+}
+
+// Haversine menghitung jarak dua titik koordinat dalam meter.
+func Haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusMeter * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// PointInPolygon memeriksa apakah titik berada di dalam polygon dengan metode ray casting.
+// Setiap titik polygon menggunakan urutan GeoJSON yaitu [longitude, latitude].
+func PointInPolygon(lat, lng float64, polygon [][]float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		xi, yi := polygon[i][0], polygon[i][1]
+		xj, yj := polygon[j][0], polygon[j][1]
+
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,37 @@
+package helpers
+
+type GeoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+func NewFeatureCollection() GeoJSONFeatureCollection {
+	return GeoJSONFeatureCollection{Type: "FeatureCollection", Features: []GeoJSONFeature{}}
+}
+
+func NewPointFeature(lat, lng float64, properties map[string]interface{}) GeoJSONFeature {
+	return GeoJSONFeature{
+		Type:       "Feature",
+		Geometry:   GeoJSONGeometry{Type: "Point", Coordinates: []float64{lng, lat}},
+		Properties: properties,
+	}
+}
+
+func NewLineStringFeature(coordinates [][]float64, properties map[string]interface{}) GeoJSONFeature {
+	return GeoJSONFeature{
+		Type:       "Feature",
+		Geometry:   GeoJSONGeometry{Type: "LineString", Coordinates: coordinates},
+		Properties: properties,
+	}
+}
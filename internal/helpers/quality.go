@@ -0,0 +1,275 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iot-golang/internal/i18n"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	QualityGood    = "good"
+	QualitySuspect = "suspect"
+	QualityBad     = "bad"
+)
+
+// Qualities berisi seluruh flag kualitas dari yang terbaik.
+var Qualities = []string{QualityGood, QualitySuspect, QualityBad}
+
+// RejectBadReadings menentukan apakah data dengan kualitas bad ditolak atau
+// tetap disimpan dengan flag bad. Diatur dari QUALITY_BAD_READINGS di main.go.
+var RejectBadReadings = true
+
+// QualityReport adalah hasil penilaian rentang nilai satu data sensor.
+type QualityReport struct {
+	Quality string
+	Issues  map[string]i18n.Message
+}
+
+// Rejected bernilai true jika data harus ditolak.
+func (report QualityReport) Rejected() bool {
+	return report.Quality == QualityBad && RejectBadReadings
+}
+
+// ErrorList menerjemahkan masalah per field ke bahasa pada ctx.
+func (report QualityReport) ErrorList(ctx context.Context) map[string]string {
+	errorList := make(map[string]string, len(report.Issues))
+	for field, message := range report.Issues {
+		errorList[field] = message.Translate(i18n.Language(ctx))
+	}
+	return errorList
+}
+
+// AssessQuality menilai setiap field payload yang memiliki Range. Field dicari
+// berdasarkan tag json, sehingga payload create maupun update bisa dinilai.
+// Kualitas data adalah kualitas terburuk dari seluruh field.
+func AssessQuality(fields []Field, payload interface{}) QualityReport {
+	report := QualityReport{Quality: QualityGood, Issues: map[string]i18n.Message{}}
+
+	values := jsonFields(payload)
+	for _, field := range fields {
+		raw, ok := values[field.Name]
+		if !ok || field.Range == nil {
+			continue
+		}
+
+		number, err := fieldNumber(raw)
+		if err != nil {
+			report.Quality = QualityBad
+			report.Issues[field.Name] = i18n.Msg(i18n.FieldNumeric, field.Name)
+			continue
+		}
+		report.assess(field, number)
+	}
+
+	return report
+}
+
+// AssessValues sama dengan AssessQuality untuk nilai yang sudah berupa angka,
+// misalnya saat data lama dihitung ulang setelah kalibrasi berubah.
+func AssessValues(fields []Field, values map[string]float64) QualityReport {
+	report := QualityReport{Quality: QualityGood, Issues: map[string]i18n.Message{}}
+	for _, field := range fields {
+		number, ok := values[field.Name]
+		if !ok || field.Range == nil {
+			continue
+		}
+		report.assess(field, number)
+	}
+	return report
+}
+
+func (report *QualityReport) assess(field Field, number float64) {
+	if number < field.Range.Min || number > field.Range.Max {
+		report.Quality = QualityBad
+		report.Issues[field.Name] = i18n.Msg(i18n.FieldOutOfRange, field.Name, field.Range.Min, field.Range.Max, field.Unit)
+	} else if number < field.Range.SuspectMin || number > field.Range.SuspectMax {
+		if report.Quality == QualityGood {
+			report.Quality = QualitySuspect
+		}
+		report.Issues[field.Name] = i18n.Msg(i18n.FieldSuspect, field.Name, field.Range.SuspectMin, field.Range.SuspectMax, field.Unit)
+	}
+}
+
+// FieldValues mengembalikan nilai numerik setiap field sensor pada payload atau
+// model berdasarkan tag json. Field yang bukan angka dilewati.
+func FieldValues(fields []Field, payload interface{}) map[string]float64 {
+	values := jsonFields(payload)
+	numbers := make(map[string]float64, len(fields))
+	for _, field := range fields {
+		raw, ok := values[field.Name]
+		if !ok {
+			continue
+		}
+		if number, err := fieldNumber(raw); err == nil {
+			numbers[field.Name] = number
+		}
+	}
+	return numbers
+}
+
+// SetFieldValues mengisi field payload berdasarkan tag json. payload harus
+// berupa pointer. Field bertipe string diisi dengan angka dalam bentuk teks dan
+// field bilangan bulat diisi dengan nilai yang dibulatkan. Nama field yang
+// berhasil diisi dikembalikan.
+func SetFieldValues(payload interface{}, values map[string]float64) map[string]bool {
+	fields := jsonFields(payload)
+	set := make(map[string]bool, len(values))
+	for name, number := range values {
+		field, ok := fields[name]
+		if !ok || !field.CanSet() {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(strconv.FormatFloat(number, 'f', -1, 64))
+		case reflect.Float32, reflect.Float64:
+			field.SetFloat(number)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			rounded := math.Round(number)
+			if field.OverflowInt(int64(rounded)) {
+				continue
+			}
+			field.SetInt(int64(rounded))
+		default:
+			continue
+		}
+		set[name] = true
+	}
+	return set
+}
+
+// ToFloat mengubah nilai kolom hasil query ke map menjadi angka. Driver database
+// mengembalikan tipe yang berbeda untuk kolom yang sama, contoh []byte pada MySQL.
+func ToFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case []byte:
+		number, err := strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+		return number, err == nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// RawValues membaca kolom raw_values hasil query ke map. Kolom kosong
+// menghasilkan map kosong.
+func RawValues(value interface{}) (map[string]string, error) {
+	raw := map[string]string{}
+	var err error
+	switch stored := value.(type) {
+	case string:
+		err = json.Unmarshal([]byte(stored), &raw)
+	case []byte:
+		err = json.Unmarshal(stored, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("raw_values tidak dapat dibaca: %w", err)
+	}
+	return raw, nil
+}
+
+// columnTimeLayouts adalah format teks kolom waktu yang dikembalikan driver
+// database, contoh SQLite dan MySQL tanpa parseTime.
+var columnTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999"}
+
+// ToTime mengubah nilai kolom waktu hasil query ke map menjadi time.Time.
+// Teks tanpa zona waktu dibaca dalam zona waktu lokal.
+func ToTime(value interface{}) (time.Time, bool) {
+	var text string
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return time.Time{}, false
+	}
+
+	for _, layout := range columnTimeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(text), time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func jsonFields(payload interface{}) map[string]reflect.Value {
+	value := reflect.Indirect(reflect.ValueOf(payload))
+	values := make(map[string]reflect.Value, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		values[name] = value.Field(i)
+	}
+	return values
+}
+
+func fieldNumber(value reflect.Value) (float64, error) {
+	switch value.Kind() {
+	case reflect.String:
+		return strconv.ParseFloat(strings.TrimSpace(value.String()), 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	}
+	return 0, fmt.Errorf("tipe %s bukan angka", value.Kind())
+}
+
+// ParseQuality membaca parameter quality berupa daftar flag yang dipisah koma,
+// contoh "good,suspect". Nilai kosong berarti tanpa filter.
+func ParseQuality(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var qualities []string
+	for _, quality := range strings.Split(value, ",") {
+		quality = strings.TrimSpace(quality)
+		if !isQuality(quality) {
+			return nil, Validation(i18n.Msg(i18n.QualityUnknown, quality), nil)
+		}
+		qualities = append(qualities, quality)
+	}
+	return qualities, nil
+}
+
+func isQuality(value string) bool {
+	for _, quality := range Qualities {
+		if quality == value {
+			return true
+		}
+	}
+	return false
+}
+
+// QualityScope membatasi query pada flag kualitas tertentu. Tanpa flag,
+// query tidak diubah.
+func QualityScope(Quality []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(Quality) == 0 {
+			return db
+		}
+		return db.Where("quality IN ?", Quality)
+	}
+}
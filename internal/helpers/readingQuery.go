@@ -0,0 +1,55 @@
+package helpers
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReadingQuery berisi filter dan paginasi untuk membaca data sensor. Nilai
+// kosong berarti tidak difilter. Newest mengurutkan dari data terbaru.
+type ReadingQuery struct {
+	DeviceToken string
+	Start       time.Time
+	End         time.Time
+	Quality     []string
+	Limit       int
+	Offset      int
+	Newest      bool
+}
+
+// Scope menerapkan ReadingQuery pada query tabel sensor.
+func (query ReadingQuery) Scope(db *gorm.DB) *gorm.DB {
+	if query.DeviceToken != "" {
+		db = db.Where("device_token = ?", query.DeviceToken)
+	}
+	if !query.Start.IsZero() {
+		db = db.Where("created_at >= ?", query.Start)
+	}
+	if !query.End.IsZero() {
+		db = db.Where("created_at < ?", query.End)
+	}
+	db = db.Scopes(QualityScope(query.Quality))
+
+	if query.Newest {
+		db = db.Order("created_at desc").Order("id desc")
+	} else {
+		db = db.Order("created_at").Order("id")
+	}
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	return db
+}
+
+// DistinctTokens mengembalikan device token unik yang terurut untuk dicatat
+// pada log penyimpanan batch.
+func DistinctTokens(tokens []string) []string {
+	tokens = slices.Clone(tokens)
+	slices.Sort(tokens)
+	return slices.Compact(tokens)
+}
@@ -1,14 +1,49 @@
 package helpers
 
+import (
+	"context"
+	"iot-golang/internal/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
 type Response struct {
-	Status   int         `json:"status"`
-	Messages string      `json:"message"`
-	Data     interface{} `json:"data"`
-	Error    error       `json:"-"`
+	Status   int               `json:"status"`
+	Code     string            `json:"code,omitempty"`
+	Messages string            `json:"message"`
+	Data     interface{}       `json:"data"`
+	Units    map[string]string `json:"units,omitempty"`
+}
+
+// SetMessage mengisi kode dan pesan response dalam bahasa pada ctx.
+func (response *Response) SetMessage(ctx context.Context, code string, args ...interface{}) {
+	response.Code = code
+	response.Messages = i18n.T(ctx, code, args...)
+}
+
+// JSON mengirim response dengan status HTTP yang dipilih controller. Field
+// status pada body selalu sama dengan status HTTP.
+func (response Response) JSON(c echo.Context, status int) error {
+	response.Status = status
+	return c.JSON(status, response)
 }
 
 type ValidationResponse struct {
 	Status   int         `json:"status"`
+	Code     string      `json:"code,omitempty"`
 	Messages string      `json:"message"`
 	Errors   interface{} `json:"error"`
 }
+
+// ErrorResponse adalah bentuk error API v2. Semua jenis error, termasuk error
+// validasi, memakai objek yang sama.
+type ErrorResponse struct {
+	Error ErrorObject `json:"error"`
+}
+
+type ErrorObject struct {
+	Status  int         `json:"status"`
+	Code    string      `json:"code,omitempty"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
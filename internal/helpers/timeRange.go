@@ -0,0 +1,84 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"iot-golang/internal/i18n"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", dateLayout}
+
+// TimeRangeError menandai parameter start atau end yang membuat ParseTimeRange gagal.
+type TimeRangeError struct {
+	Param string
+	Err   error
+}
+
+func (e *TimeRangeError) Error() string {
+	return e.Param + ": " + e.Err.Error()
+}
+
+func (e *TimeRangeError) Unwrap() error {
+	return e.Err
+}
+
+// ParseTime membaca waktu dalam format RFC3339, "2006-01-02 15:04:05" atau "2006-01-02".
+func ParseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, Validation(i18n.Msg(i18n.TimeFormatUnknown, value), nil)
+}
+
+// ParseEndTime membaca batas akhir rentang waktu. Tanggal tanpa jam dibaca
+// sebagai akhir hari tersebut, yaitu awal hari berikutnya yang tidak ikut
+// dalam rentang.
+func ParseEndTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(dateLayout, value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	return ParseTime(value)
+}
+
+// ParseTimeRange membaca parameter start dan end. Jika kosong, rentang default adalah 24 jam terakhir.
+// Rentang yang dihasilkan adalah [start, end), end tidak termasuk.
+func ParseTimeRange(start string, end string) (time.Time, time.Time, error) {
+	endTime := time.Now()
+	if end != "" {
+		t, err := ParseEndTime(end)
+		if err != nil {
+			return time.Time{}, time.Time{}, &TimeRangeError{Param: "end", Err: err}
+		}
+		endTime = t
+	}
+
+	startTime := endTime.Add(-24 * time.Hour)
+	if start != "" {
+		t, err := ParseTime(start)
+		if err != nil {
+			return time.Time{}, time.Time{}, &TimeRangeError{Param: "start", Err: err}
+		}
+		startTime = t
+	}
+
+	if startTime.After(endTime) {
+		return time.Time{}, time.Time{}, &TimeRangeError{Param: "start", Err: Validation(i18n.Msg(i18n.TimeRangeInvalid), nil)}
+	}
+
+	return startTime, endTime, nil
+}
+
+// TimeRangeErrorList menyusun error per parameter dari error ParseTimeRange.
+func TimeRangeErrorList(ctx context.Context, err error) map[string]string {
+	param := "start"
+	var rangeError *TimeRangeError
+	if errors.As(err, &rangeError) {
+		param = rangeError.Param
+	}
+	return map[string]string{param: ErrorMessage(ctx, err)}
+}
@@ -0,0 +1,172 @@
+package helpers
+
+import (
+	"iot-golang/internal/i18n"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// unit adalah satuan yang dapat dikonversi. Nilai dalam satuan dasar
+// dimensinya adalah nilai * Scale + Offset.
+type unit struct {
+	Symbol    string
+	Dimension string
+	Scale     float64
+	Offset    float64
+}
+
+// knownUnits berisi satuan yang didukung parameter units. Satuan dasar setiap
+// dimensi adalah Pa, °C, m, A, V dan W.
+var knownUnits = []unit{
+	{Symbol: "hPa", Dimension: "pressure", Scale: 100},
+	{Symbol: "kPa", Dimension: "pressure", Scale: 1000},
+	{Symbol: "inHg", Dimension: "pressure", Scale: 3386.389},
+	{Symbol: "°C", Dimension: "temperature", Scale: 1},
+	{Symbol: "°F", Dimension: "temperature", Scale: 5.0 / 9, Offset: -32 * 5.0 / 9},
+	{Symbol: "K", Dimension: "temperature", Scale: 1, Offset: -273.15},
+	{Symbol: "m", Dimension: "length", Scale: 1},
+	{Symbol: "ft", Dimension: "length", Scale: 0.3048},
+	{Symbol: "mA", Dimension: "current", Scale: 0.001},
+	{Symbol: "A", Dimension: "current", Scale: 1},
+	{Symbol: "mV", Dimension: "voltage", Scale: 0.001},
+	{Symbol: "V", Dimension: "voltage", Scale: 1},
+	{Symbol: "mW", Dimension: "power", Scale: 0.001},
+	{Symbol: "W", Dimension: "power", Scale: 1},
+	{Symbol: "kW", Dimension: "power", Scale: 1000},
+}
+
+// unitAliases memudahkan penulisan satuan suhu pada query string.
+var unitAliases = map[string]string{"c": "°C", "degc": "°C", "f": "°F", "degf": "°F"}
+
+func findUnit(symbol string) (unit, bool) {
+	symbol = strings.TrimSpace(symbol)
+	if alias, ok := unitAliases[strings.ToLower(symbol)]; ok {
+		symbol = alias
+	}
+	for _, u := range knownUnits {
+		if u.Symbol == symbol {
+			return u, true
+		}
+	}
+	return unit{}, false
+}
+
+// Units berisi satuan setiap field pada response. Field yang satuannya diminta
+// berbeda dari satuan kanonik akan dikonversi.
+type Units struct {
+	fields  []Field
+	convert map[string][2]unit
+}
+
+// ParseUnits membaca parameter units berupa daftar satuan yang dipisah koma,
+// contoh "kPa,°F,ft". Setiap satuan diterapkan pada field yang satuan kanoniknya
+// berdimensi sama. Satuan yang tidak berlaku untuk sensor tersebut diabaikan
+// sehingga client dapat memakai parameter yang sama untuk seluruh sensor.
+func ParseUnits(fields []Field, value string) (Units, error) {
+	units := Units{fields: make([]Field, len(fields)), convert: map[string][2]unit{}}
+	copy(units.fields, fields)
+
+	if value == "" {
+		return units, nil
+	}
+
+	targets := map[string]unit{}
+	for _, symbol := range strings.Split(value, ",") {
+		target, ok := findUnit(symbol)
+		if !ok {
+			return units, Validation(i18n.Msg(i18n.UnitUnknown, strings.TrimSpace(symbol)), nil)
+		}
+		targets[target.Dimension] = target
+	}
+
+	for i, field := range units.fields {
+		from, ok := findUnit(field.Unit)
+		if !ok {
+			continue
+		}
+		if to, ok := targets[from.Dimension]; ok && to.Symbol != from.Symbol {
+			units.convert[field.Name] = [2]unit{from, to}
+			units.fields[i].Unit = to.Symbol
+		}
+	}
+
+	return units, nil
+}
+
+// Fields mengembalikan metadata field dengan satuan hasil konversi.
+func (units Units) Fields() []Field {
+	return units.fields
+}
+
+// Map mengembalikan satuan setiap field berdasarkan nama json.
+func (units Units) Map() map[string]string {
+	symbols := make(map[string]string, len(units.fields))
+	for _, field := range units.fields {
+		if field.Unit != "" {
+			symbols[field.Name] = field.Unit
+		}
+	}
+	return symbols
+}
+
+// Value mengonversi satu nilai field ke satuan yang diminta.
+func (units Units) Value(field string, value float64) float64 {
+	conversion, ok := units.convert[field]
+	if !ok {
+		return value
+	}
+	from, to := conversion[0], conversion[1]
+	base := value*from.Scale + from.Offset
+	return math.Round((base-to.Offset)/to.Scale*10000) / 10000
+}
+
+// ConvertValues mengonversi nilai yang dikelompokkan berdasarkan nama field,
+// contoh nilai rollup pada endpoint riwayat.
+func (units Units) ConvertValues(values map[string]float64) {
+	for name, value := range values {
+		values[name] = units.Value(name, value)
+	}
+}
+
+// Apply mengonversi field pada reading berdasarkan tag json. reading harus
+// berupa pointer ke model sensor.
+func (units Units) Apply(reading interface{}) {
+	if len(units.convert) == 0 {
+		return
+	}
+
+	values := FieldValues(units.fields, reading)
+	for name, value := range values {
+		values[name] = units.Value(name, value)
+	}
+	SetFieldValues(reading, values)
+}
+
+// Convert mengisi satuan pada response lalu mengonversi Data, baik berupa satu
+// model maupun slice model.
+func (units Units) Convert(response *Response) {
+	response.Units = units.Map()
+	if len(units.convert) == 0 || response.Data == nil {
+		return
+	}
+
+	data := reflect.ValueOf(response.Data)
+	switch data.Kind() {
+	case reflect.Slice:
+		for i := 0; i < data.Len(); i++ {
+			if item := data.Index(i); item.Kind() == reflect.Struct {
+				units.Apply(item.Addr().Interface())
+			}
+		}
+	case reflect.Struct:
+		item := reflect.New(data.Type())
+		item.Elem().Set(data)
+		units.Apply(item.Interface())
+		response.Data = item.Elem().Interface()
+	case reflect.Ptr:
+		if data.Elem().Kind() == reflect.Struct {
+			units.Apply(response.Data)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"context"
+	"iot-golang/internal/i18n"
+	"reflect"
+
+	v1 "github.com/go-playground/validator/v10"
+)
+
+// ValidationMessages memetakan tag validator ke kode pesan i18n.
+type ValidationMessages map[string]string
+
+var defaultValidationMessages = ValidationMessages{
+	"required": i18n.FieldRequired,
+	"numeric":  i18n.FieldNumeric,
+}
+
+// ValidationErrorList menyusun pesan validasi per field berdasarkan tag json
+// payload dalam bahasa pada ctx. messages menambah atau mengganti pesan tag
+// bawaan, tag tanpa pesan memakai pesan field tidak valid.
+func ValidationErrorList(ctx context.Context, err error, payload interface{}, messages ...ValidationMessages) map[string]string {
+	errorList := make(map[string]string)
+
+	errors, ok := err.(v1.ValidationErrors)
+	if !ok {
+		errorList["payload"] = err.Error()
+		return errorList
+	}
+
+	payloadType := reflect.Indirect(reflect.ValueOf(payload)).Type()
+	for _, e := range errors {
+		field, _ := payloadType.FieldByName(e.StructField())
+		fieldName := field.Tag.Get("json")
+		errorList[fieldName] = i18n.T(ctx, validationMessage(e.Tag(), messages), fieldName)
+	}
+
+	return errorList
+}
+
+func validationMessage(tag string, messages []ValidationMessages) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if code, ok := messages[i][tag]; ok {
+			return code
+		}
+	}
+	if code, ok := defaultValidationMessages[tag]; ok {
+		return code
+	}
+	return i18n.FieldInvalid
+}
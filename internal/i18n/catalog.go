@@ -0,0 +1,273 @@
+package i18n
+
+// Kode pesan bersifat stabil dan dikirim ke client pada field "code" sehingga
+// client tidak perlu mencocokkan teks pesan. Jangan ubah kode yang sudah ada.
+const (
+	RequestRejected    = "request.rejected"
+	RequestInvalidBody = "request.invalid_body"
+
+	FieldRequired    = "field.required"
+	FieldNumeric     = "field.numeric"
+	FieldInvalid     = "field.invalid"
+	FieldNotNegative = "field.not_negative"
+	FieldInteger     = "field.integer"
+	FieldReadingId   = "field.reading_id"
+	FieldMinPoints   = "field.min_points"
+	FieldPointFormat = "field.point_format"
+	FieldOutOfRange  = "field.out_of_range"
+	FieldSuspect     = "field.suspect"
+
+	QualityUnknown = "quality.unknown"
+	UnitUnknown    = "unit.unknown"
+
+	TimeFormatUnknown   = "time.format_unknown"
+	TimeRangeInvalid    = "time.range_invalid"
+	ExportFormatUnknown = "export.format_unknown"
+
+	HTTPBadRequest         = "http.bad_request"
+	HTTPNotFound           = "http.not_found"
+	HTTPMethodNotAllowed   = "http.method_not_allowed"
+	HTTPEntityTooLarge     = "http.entity_too_large"
+	HTTPUnsupportedMedia   = "http.unsupported_media_type"
+	HTTPTooManyRequests    = "http.too_many_requests"
+	HTTPInternalError      = "http.internal_error"
+	HTTPServiceUnavailable = "http.service_unavailable"
+	HTTPGatewayTimeout     = "http.gateway_timeout"
+
+	SensorCreated         = "sensor.created"
+	SensorCreateFailed    = "sensor.create_failed"
+	SensorListed          = "sensor.listed"
+	SensorListFailed      = "sensor.list_failed"
+	SensorFoundByID       = "sensor.found_by_id"
+	SensorNotFoundByID    = "sensor.not_found_by_id"
+	SensorGetByIDFailed   = "sensor.get_by_id_failed"
+	SensorFoundByToken    = "sensor.found_by_token"
+	SensorNotFoundByToken = "sensor.not_found_by_token"
+	SensorGetByTokenFail  = "sensor.get_by_token_failed"
+	SensorUpdated         = "sensor.updated"
+	SensorUpdateFailed    = "sensor.update_failed"
+	SensorDeleted         = "sensor.deleted"
+	SensorDeleteFailed    = "sensor.delete_failed"
+	SensorUnknown         = "sensor.unknown"
+	SensorHistoryFound    = "sensor.history_found"
+	SensorHistoryFailed   = "sensor.history_failed"
+	SensorTrackFound      = "sensor.track_found"
+	SensorTrackNotFound   = "sensor.track_not_found"
+	SensorTrackFailed     = "sensor.track_failed"
+
+	DeviceCreated          = "device.created"
+	DeviceCreateFailed     = "device.create_failed"
+	DeviceListed           = "device.listed"
+	DeviceListFailed       = "device.list_failed"
+	DeviceFoundByID        = "device.found_by_id"
+	DeviceNotFoundByID     = "device.not_found_by_id"
+	DeviceGetByIDFailed    = "device.get_by_id_failed"
+	DeviceFoundByToken     = "device.found_by_token"
+	DeviceNotFoundByToken  = "device.not_found_by_token"
+	DeviceGetByTokenFailed = "device.get_by_token_failed"
+	DeviceUpdated          = "device.updated"
+	DeviceUpdateFailed     = "device.update_failed"
+	DeviceDeleted          = "device.deleted"
+	DeviceDeleteFailed     = "device.delete_failed"
+	DeviceMapFound         = "device.map_found"
+	DeviceMapFailed        = "device.map_failed"
+
+	GeofenceCreated       = "geofence.created"
+	GeofenceCreateFailed  = "geofence.create_failed"
+	GeofenceListed        = "geofence.listed"
+	GeofenceListFailed    = "geofence.list_failed"
+	GeofenceFoundByID     = "geofence.found_by_id"
+	GeofenceNotFoundByID  = "geofence.not_found_by_id"
+	GeofenceGetByIDFailed = "geofence.get_by_id_failed"
+	GeofenceUpdated       = "geofence.updated"
+	GeofenceUpdateFailed  = "geofence.update_failed"
+	GeofenceDeleted       = "geofence.deleted"
+	GeofenceDeleteFailed  = "geofence.delete_failed"
+	GeofenceEventsFound   = "geofence.events_found"
+	GeofenceEventsMissing = "geofence.events_not_found"
+	GeofenceEventsFailed  = "geofence.events_failed"
+
+	AnomalyEventsFound   = "anomaly.events_found"
+	AnomalyEventsMissing = "anomaly.events_not_found"
+	AnomalyEventsFailed  = "anomaly.events_failed"
+
+	CompletenessFound   = "completeness.found"
+	CompletenessClamped = "completeness.clamped"
+	CompletenessMissing = "completeness.not_found"
+	CompletenessFailed  = "completeness.failed"
+
+	CalibrationCreated         = "calibration.created"
+	CalibrationCreateFailed    = "calibration.create_failed"
+	CalibrationListed          = "calibration.listed"
+	CalibrationListFailed      = "calibration.list_failed"
+	CalibrationFoundByID       = "calibration.found_by_id"
+	CalibrationNotFoundByID    = "calibration.not_found_by_id"
+	CalibrationGetByIDFailed   = "calibration.get_by_id_failed"
+	CalibrationUpdated         = "calibration.updated"
+	CalibrationUpdateFailed    = "calibration.update_failed"
+	CalibrationDeleted         = "calibration.deleted"
+	CalibrationDeleteFailed    = "calibration.delete_failed"
+	CalibrationRecomputed      = "calibration.recomputed"
+	CalibrationRecomputeFailed = "calibration.recompute_failed"
+	CalibrationFieldUnknown    = "calibration.field_unknown"
+
+	RetentionPoliciesFound  = "retention.policies_found"
+	RetentionPoliciesFailed = "retention.policies_failed"
+	RetentionPolicySaved    = "retention.policy_saved"
+	RetentionPolicyFailed   = "retention.policy_save_failed"
+	RetentionStatus         = "retention.status"
+	RetentionRunning        = "retention.running"
+	RetentionTriggered      = "retention.triggered"
+
+	ImportFinished   = "import.finished"
+	ImportReadFailed = "import.read_failed"
+
+	HealthLive       = "health.live"
+	HealthReady      = "health.ready"
+	HealthNotReady   = "health.not_ready"
+	HealthInfo       = "health.info"
+	HealthInfoFailed = "health.info_failed"
+
+	GraphQLQueryFailed  = "graphql.query_failed"
+	GraphQLPageConflict = "graphql.page_conflict"
+	GraphQLPageInvalid  = "graphql.page_invalid"
+
+	GRPCReadingEmpty   = "grpc.reading_empty"
+	GRPCStreamFinished = "grpc.stream_finished"
+	GRPCServerStopping = "grpc.server_stopping"
+)
+
+var catalog = map[string]map[string]string{
+	RequestRejected:    {Indonesian: "Request di tolak", English: "Request rejected"},
+	RequestInvalidBody: {Indonesian: "Body request tidak valid", English: "Invalid request body"},
+
+	FieldRequired:    {Indonesian: "Field %s tidak boleh kosong", English: "Field %s is required"},
+	FieldNumeric:     {Indonesian: "Field %s tidak boleh huruf", English: "Field %s must be numeric"},
+	FieldInvalid:     {Indonesian: "Field %s tidak valid", English: "Field %s is invalid"},
+	FieldNotNegative: {Indonesian: "Field %s tidak boleh negatif", English: "Field %s must not be negative"},
+	FieldInteger:     {Indonesian: "Field %s harus berupa bilangan bulat", English: "Field %s must be an integer"},
+	FieldReadingId:   {Indonesian: "Field %s harus berformat <sensor>-<id>, misalnya bmp-42", English: "Field %s must be formatted as <sensor>-<id>, for example bmp-42"},
+	FieldMinPoints:   {Indonesian: "Field %s minimal memiliki 3 titik", English: "Field %s must have at least 3 points"},
+	FieldPointFormat: {Indonesian: "Setiap titik pada field %s harus berisi [longitude, latitude]", English: "Every point in field %s must be [longitude, latitude]"},
+	FieldOutOfRange:  {Indonesian: "Field %s harus di antara %g dan %g %s", English: "Field %s must be between %g and %g %s"},
+	FieldSuspect:     {Indonesian: "Field %s di luar rentang wajar %g sampai %g %s", English: "Field %s is outside the expected range %g to %g %s"},
+
+	QualityUnknown: {Indonesian: "quality %q tidak dikenali, gunakan good, suspect atau bad", English: "unknown quality %q, use good, suspect or bad"},
+	UnitUnknown:    {Indonesian: "satuan %q tidak dikenali", English: "unknown unit %q"},
+
+	TimeFormatUnknown:   {Indonesian: "format waktu %q tidak dikenali", English: "unrecognized time format %q"},
+	TimeRangeInvalid:    {Indonesian: "start tidak boleh melebihi end", English: "start must not be after end"},
+	ExportFormatUnknown: {Indonesian: "format ekspor %q tidak didukung, gunakan csv atau xlsx", English: "unsupported export format %q, use csv or xlsx"},
+
+	HTTPBadRequest:         {Indonesian: "Request tidak valid", English: "Bad request"},
+	HTTPNotFound:           {Indonesian: "Halaman tidak ditemukan", English: "Not found"},
+	HTTPMethodNotAllowed:   {Indonesian: "Method tidak diizinkan", English: "Method not allowed"},
+	HTTPEntityTooLarge:     {Indonesian: "Body request terlalu besar", English: "Request entity too large"},
+	HTTPUnsupportedMedia:   {Indonesian: "Content-Type tidak didukung", English: "Unsupported media type"},
+	HTTPTooManyRequests:    {Indonesian: "Terlalu banyak request", English: "Too many requests"},
+	HTTPInternalError:      {Indonesian: "Terjadi kesalahan pada server", English: "Internal server error"},
+	HTTPServiceUnavailable: {Indonesian: "Service tidak tersedia", English: "Service unavailable"},
+	HTTPGatewayTimeout:     {Indonesian: "Request melebihi batas waktu", English: "Request timed out"},
+
+	SensorCreated:         {Indonesian: "Berhasil membuat data sensor baru", English: "Sensor data created"},
+	SensorCreateFailed:    {Indonesian: "Gagal membuat data sensor baru", English: "Failed to create sensor data"},
+	SensorListed:          {Indonesian: "Berhasil mengambil semua data sensor", English: "Retrieved all sensor data"},
+	SensorListFailed:      {Indonesian: "Gagal mengambil seluruh data sensor", English: "Failed to retrieve sensor data"},
+	SensorFoundByID:       {Indonesian: "Berhasil mengambil data sensor dengan id : %d", English: "Retrieved sensor data with id %d"},
+	SensorNotFoundByID:    {Indonesian: "Tidak menemukan data sensor dengan id : %d", English: "Sensor data with id %d not found"},
+	SensorGetByIDFailed:   {Indonesian: "Gagal mengambil data sensor dengan id : %d", English: "Failed to retrieve sensor data with id %d"},
+	SensorFoundByToken:    {Indonesian: "Berhasil mengambil data sensor dengan token : %s", English: "Retrieved sensor data for token %s"},
+	SensorNotFoundByToken: {Indonesian: "Tidak menemukan data sensor dengan token : %s", English: "Sensor data for token %s not found"},
+	SensorGetByTokenFail:  {Indonesian: "Gagal mengambil data sensor dengan token : %s", English: "Failed to retrieve sensor data for token %s"},
+	SensorUpdated:         {Indonesian: "Berhasil mengubah data sensor", English: "Sensor data updated"},
+	SensorUpdateFailed:    {Indonesian: "Gagal mengubah data sensor dengan id : %d", English: "Failed to update sensor data with id %d"},
+	SensorDeleted:         {Indonesian: "Data sensor berhasil dihapus", English: "Sensor data deleted"},
+	SensorDeleteFailed:    {Indonesian: "Gagal menghapus data sensor dengan id : %d", English: "Failed to delete sensor data with id %d"},
+	SensorUnknown:         {Indonesian: "Jenis sensor %s tidak dikenali", English: "Unknown sensor type %s"},
+	SensorHistoryFound:    {Indonesian: "Berhasil mengambil riwayat sensor %s", English: "Retrieved %s sensor history"},
+	SensorHistoryFailed:   {Indonesian: "Gagal mengambil riwayat sensor %s", English: "Failed to retrieve %s sensor history"},
+	SensorTrackFound:      {Indonesian: "Berhasil mengambil data track sensor dengan token : %s", English: "Retrieved sensor track for token %s"},
+	SensorTrackNotFound:   {Indonesian: "Tidak menemukan data track sensor dengan token : %s", English: "Sensor track for token %s not found"},
+	SensorTrackFailed:     {Indonesian: "Gagal mengambil data track sensor dengan token : %s", English: "Failed to retrieve sensor track for token %s"},
+
+	DeviceCreated:          {Indonesian: "Berhasil membuat data perangkat baru", English: "Device created"},
+	DeviceCreateFailed:     {Indonesian: "Gagal membuat data perangkat baru", English: "Failed to create device"},
+	DeviceListed:           {Indonesian: "Berhasil mengambil semua data perangkat", English: "Retrieved all devices"},
+	DeviceListFailed:       {Indonesian: "Gagal mengambil seluruh data perangkat", English: "Failed to retrieve devices"},
+	DeviceFoundByID:        {Indonesian: "Berhasil mengambil data perangkat dengan id : %d", English: "Retrieved device with id %d"},
+	DeviceNotFoundByID:     {Indonesian: "Tidak menemukan data perangkat dengan id : %d", English: "Device with id %d not found"},
+	DeviceGetByIDFailed:    {Indonesian: "Gagal mengambil data perangkat dengan id : %d", English: "Failed to retrieve device with id %d"},
+	DeviceFoundByToken:     {Indonesian: "Berhasil mengambil data perangkat dengan token : %s", English: "Retrieved device with token %s"},
+	DeviceNotFoundByToken:  {Indonesian: "Tidak menemukan data perangkat dengan token : %s", English: "Device with token %s not found"},
+	DeviceGetByTokenFailed: {Indonesian: "Gagal mengambil data perangkat dengan token : %s", English: "Failed to retrieve device with token %s"},
+	DeviceUpdated:          {Indonesian: "Berhasil mengubah data perangkat", English: "Device updated"},
+	DeviceUpdateFailed:     {Indonesian: "Gagal mengubah data perangkat dengan id : %d", English: "Failed to update device with id %d"},
+	DeviceDeleted:          {Indonesian: "Data perangkat berhasil dihapus", English: "Device deleted"},
+	DeviceDeleteFailed:     {Indonesian: "Gagal menghapus data perangkat dengan id : %d", English: "Failed to delete device with id %d"},
+	DeviceMapFound:         {Indonesian: "Berhasil mengambil data peta perangkat", English: "Retrieved device map"},
+	DeviceMapFailed:        {Indonesian: "Gagal mengambil data peta perangkat", English: "Failed to retrieve device map"},
+
+	GeofenceCreated:       {Indonesian: "Berhasil membuat data geofence baru", English: "Geofence created"},
+	GeofenceCreateFailed:  {Indonesian: "Gagal membuat data geofence baru", English: "Failed to create geofence"},
+	GeofenceListed:        {Indonesian: "Berhasil mengambil semua data geofence", English: "Retrieved all geofences"},
+	GeofenceListFailed:    {Indonesian: "Gagal mengambil seluruh data geofence", English: "Failed to retrieve geofences"},
+	GeofenceFoundByID:     {Indonesian: "Berhasil mengambil data geofence dengan id : %d", English: "Retrieved geofence with id %d"},
+	GeofenceNotFoundByID:  {Indonesian: "Tidak menemukan data geofence dengan id : %d", English: "Geofence with id %d not found"},
+	GeofenceGetByIDFailed: {Indonesian: "Gagal mengambil data geofence dengan id : %d", English: "Failed to retrieve geofence with id %d"},
+	GeofenceUpdated:       {Indonesian: "Berhasil mengubah data geofence", English: "Geofence updated"},
+	GeofenceUpdateFailed:  {Indonesian: "Gagal mengubah data geofence dengan id : %d", English: "Failed to update geofence with id %d"},
+	GeofenceDeleted:       {Indonesian: "Data geofence berhasil dihapus", English: "Geofence deleted"},
+	GeofenceDeleteFailed:  {Indonesian: "Gagal menghapus data geofence dengan id : %d", English: "Failed to delete geofence with id %d"},
+	GeofenceEventsFound:   {Indonesian: "Berhasil mengambil event geofence dengan token : %s", English: "Retrieved geofence events for token %s"},
+	GeofenceEventsMissing: {Indonesian: "Tidak menemukan event geofence dengan token : %s", English: "Geofence events for token %s not found"},
+	GeofenceEventsFailed:  {Indonesian: "Gagal mengambil event geofence dengan token : %s", English: "Failed to retrieve geofence events for token %s"},
+
+	AnomalyEventsFound:   {Indonesian: "Berhasil mengambil event anomali dengan token : %s", English: "Retrieved anomaly events for token %s"},
+	AnomalyEventsMissing: {Indonesian: "Tidak menemukan event anomali dengan token : %s", English: "Anomaly events for token %s not found"},
+	AnomalyEventsFailed:  {Indonesian: "Gagal mengambil event anomali dengan token : %s", English: "Failed to retrieve anomaly events for token %s"},
+
+	CompletenessFound:   {Indonesian: "Berhasil membuat laporan kelengkapan data", English: "Data completeness report created"},
+	CompletenessClamped: {Indonesian: "Berhasil membuat laporan kelengkapan data, rentang sebelum %s tidak dilaporkan karena rollup per jam sudah dihapus", English: "Data completeness report created, the range before %s is not reported because its hourly rollups have been deleted"},
+	CompletenessMissing: {Indonesian: "Tidak menemukan data untuk laporan kelengkapan", English: "No data found for the completeness report"},
+	CompletenessFailed:  {Indonesian: "Gagal membuat laporan kelengkapan data", English: "Failed to create data completeness report"},
+
+	CalibrationCreated:         {Indonesian: "Berhasil menyimpan kalibrasi", English: "Calibration saved"},
+	CalibrationCreateFailed:    {Indonesian: "Gagal menyimpan kalibrasi", English: "Failed to save calibration"},
+	CalibrationListed:          {Indonesian: "Berhasil mengambil semua data kalibrasi", English: "Retrieved all calibrations"},
+	CalibrationListFailed:      {Indonesian: "Gagal mengambil seluruh data kalibrasi", English: "Failed to retrieve calibrations"},
+	CalibrationFoundByID:       {Indonesian: "Berhasil mengambil data kalibrasi dengan id : %d", English: "Retrieved calibration with id %d"},
+	CalibrationNotFoundByID:    {Indonesian: "Tidak menemukan data kalibrasi dengan id : %d", English: "Calibration with id %d not found"},
+	CalibrationGetByIDFailed:   {Indonesian: "Gagal mengambil data kalibrasi dengan id : %d", English: "Failed to retrieve calibration with id %d"},
+	CalibrationUpdated:         {Indonesian: "Berhasil mengubah data kalibrasi", English: "Calibration updated"},
+	CalibrationUpdateFailed:    {Indonesian: "Gagal mengubah data kalibrasi dengan id : %d", English: "Failed to update calibration with id %d"},
+	CalibrationDeleted:         {Indonesian: "Data kalibrasi berhasil dihapus", English: "Calibration deleted"},
+	CalibrationDeleteFailed:    {Indonesian: "Gagal menghapus data kalibrasi dengan id : %d", English: "Failed to delete calibration with id %d"},
+	CalibrationRecomputed:      {Indonesian: "Berhasil menghitung ulang data sensor %s dengan token : %s", English: "Recomputed %s sensor data for token %s"},
+	CalibrationRecomputeFailed: {Indonesian: "Gagal menghitung ulang data sensor %s dengan token : %s", English: "Failed to recompute %s sensor data for token %s"},
+	CalibrationFieldUnknown:    {Indonesian: "Sensor %s tidak memiliki field %s", English: "Sensor %s has no field %s"},
+
+	RetentionPoliciesFound:  {Indonesian: "Berhasil mengambil kebijakan retensi", English: "Retrieved retention policies"},
+	RetentionPoliciesFailed: {Indonesian: "Gagal mengambil kebijakan retensi", English: "Failed to retrieve retention policies"},
+	RetentionPolicySaved:    {Indonesian: "Berhasil menyimpan kebijakan retensi sensor %s", English: "Saved retention policy for sensor %s"},
+	RetentionPolicyFailed:   {Indonesian: "Gagal menyimpan kebijakan retensi sensor %s", English: "Failed to save retention policy for sensor %s"},
+	RetentionStatus:         {Indonesian: "Berhasil mengambil status job retensi", English: "Retrieved retention job status"},
+	RetentionRunning:        {Indonesian: "Job retensi sedang berjalan", English: "Retention job is already running"},
+	RetentionTriggered:      {Indonesian: "Job retensi akan segera dijalankan", English: "Retention job scheduled"},
+
+	ImportFinished:   {Indonesian: "Import sensor %s selesai, %d berhasil, %d ditolak", English: "Import of sensor %s finished, %d accepted, %d rejected"},
+	ImportReadFailed: {Indonesian: "Gagal membaca file CSV sensor %s", English: "Failed to read CSV file for sensor %s"},
+
+	HealthLive:       {Indonesian: "Service berjalan", English: "Service is running"},
+	HealthReady:      {Indonesian: "Service siap menerima request", English: "Service is ready to accept requests"},
+	HealthNotReady:   {Indonesian: "Service belum siap menerima request", English: "Service is not ready to accept requests"},
+	HealthInfo:       {Indonesian: "Berhasil mengambil informasi service", English: "Retrieved service information"},
+	HealthInfoFailed: {Indonesian: "Gagal mengambil informasi service", English: "Failed to retrieve service information"},
+
+	GraphQLQueryFailed:  {Indonesian: "Query GraphQL gagal dijalankan", English: "GraphQL query failed"},
+	GraphQLPageConflict: {Indonesian: "Argumen first dan last tidak boleh dipakai bersamaan", English: "Arguments first and last cannot be used together"},
+	GraphQLPageInvalid:  {Indonesian: "Argumen %s harus antara 0 dan %d", English: "Argument %s must be between 0 and %d"},
+
+	GRPCReadingEmpty:   {Indonesian: "Data sensor tidak berisi jenis sensor", English: "Reading does not contain a sensor type"},
+	GRPCStreamFinished: {Indonesian: "Stream data sensor selesai, %d berhasil, %d ditolak", English: "Reading stream finished, %d accepted, %d rejected"},
+	GRPCServerStopping: {Indonesian: "Server gRPC sedang berhenti", English: "gRPC server is shutting down"},
+}
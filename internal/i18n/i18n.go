@@ -0,0 +1,103 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	Indonesian = "id"
+	English    = "en"
+)
+
+// Default adalah bahasa yang dipakai ketika request tidak meminta bahasa
+// tertentu atau bahasa yang diminta tidak tersedia.
+var Default = Indonesian
+
+// Languages adalah daftar bahasa yang memiliki katalog pesan.
+var Languages = []string{Indonesian, English}
+
+var matcher = language.NewMatcher([]language.Tag{language.Indonesian, language.English})
+
+type contextKey struct{}
+
+// WithLanguage menyimpan bahasa response ke context.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// Language mengembalikan bahasa yang tersimpan di context atau Default.
+func Language(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok {
+		return lang
+	}
+	return Default
+}
+
+// Match memilih bahasa dari parameter query lang atau header Accept-Language.
+// Parameter query diutamakan, keduanya boleh kosong.
+func Match(query string, acceptLanguage string) string {
+	if query != "" {
+		if tag, err := language.Parse(query); err == nil {
+			return matchTags(tag)
+		}
+	}
+
+	if acceptLanguage != "" {
+		if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(tags) > 0 {
+			return matchTags(tags...)
+		}
+	}
+
+	return Default
+}
+
+func matchTags(tags ...language.Tag) string {
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return Languages[index]
+}
+
+// Message adalah pesan yang belum diterjemahkan, berisi kode stabil dan
+// argumen untuk format pesan di katalog.
+type Message struct {
+	Code string
+	Args []interface{}
+}
+
+// Msg membuat Message dari kode katalog dan argumennya.
+func Msg(code string, args ...interface{}) Message {
+	return Message{Code: code, Args: args}
+}
+
+// Translate menerjemahkan pesan ke bahasa lang. Bahasa yang tidak ada di
+// katalog memakai Default, dan kode yang tidak dikenal dikembalikan apa adanya.
+func (message Message) Translate(lang string) string {
+	translations, ok := catalog[message.Code]
+	if !ok {
+		return message.Code
+	}
+
+	format, ok := translations[lang]
+	if !ok {
+		format = translations[Default]
+	}
+	if len(message.Args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, message.Args...)
+}
+
+// String menerjemahkan pesan ke bahasa Default.
+func (message Message) String() string {
+	return message.Translate(Default)
+}
+
+// T menerjemahkan kode ke bahasa yang tersimpan di context.
+func T(ctx context.Context, code string, args ...interface{}) string {
+	return Msg(code, args...).Translate(Language(ctx))
+}
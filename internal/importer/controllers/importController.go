@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"io"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/importer/services"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type ImportController struct {
+	importService services.ImportService
+}
+
+// Import menerima file CSV melalui form field "file" atau langsung sebagai body request.
+func (controller ImportController) Import(c echo.Context) error {
+	var reader io.Reader = c.Request().Body
+
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"file": i18n.T(c.Request().Context(), i18n.FieldRequired, "file")})
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"file": err.Error()})
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	result, err := controller.importService.Import(c.Request().Context(), c.Param("sensor"), reader, services.ParseMapping(c.QueryParam("map")))
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func NewImportController(db *gorm.DB, logger *slog.Logger) ImportController {
+	controller := ImportController{
+		importService: services.NewImportService(db, logger),
+	}
+
+	return controller
+}
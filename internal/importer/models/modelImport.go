@@ -0,0 +1,14 @@
+package models
+
+type RejectedRow struct {
+	Line    int               `json:"line"`
+	Reasons map[string]string `json:"reasons"`
+}
+
+type ImportReport struct {
+	Sensor    string        `json:"sensor"`
+	TotalRows int           `json:"total_rows"`
+	Inserted  int           `json:"inserted"`
+	Rejected  int           `json:"rejected"`
+	Rows      []RejectedRow `json:"rejected_rows"`
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	beitianModels "iot-golang/internal/beitian/models"
+	beitianServices "iot-golang/internal/beitian/services"
+	bmpModels "iot-golang/internal/bmp/models"
+	bmpServices "iot-golang/internal/bmp/services"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/importer/models"
+	inaModels "iot-golang/internal/ina/models"
+	inaServices "iot-golang/internal/ina/services"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	pzemModels "iot-golang/internal/pzem/models"
+	pzemServices "iot-golang/internal/pzem/services"
+	"iot-golang/internal/sensors"
+	thigrowModels "iot-golang/internal/thigrow/models"
+	thigrowServices "iot-golang/internal/thigrow/services"
+	thmModels "iot-golang/internal/thm/models"
+	thmServices "iot-golang/internal/thm/services"
+	"iot-golang/internal/tracing"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+const defaultBatchSize = 500
+
+type importService struct {
+	logger         *slog.Logger
+	beitianService beitianServices.BeitianService
+	bmpService     bmpServices.BmpService
+	inaService     inaServices.InaService
+	pzemService    pzemServices.PzemService
+	thigrowService thigrowServices.ThigrowService
+	thmService     thmServices.ThmService
+	validate       *v1.Validate
+	batchSize      int
+}
+
+// Import implements ImportService.
+// Mapping berisi pasangan nama kolom CSV ke nama field sensor, kolom yang
+// tidak ada di mapping dicocokkan langsung dengan nama field.
+func (service *importService) Import(ctx context.Context, Sensor string, r io.Reader, Mapping map[string]string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.Import")
+	defer span.End()
+
+	var response helpers.Response
+	var report models.ImportReport
+	var err error
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	switch Sensor {
+	case "beitian":
+		report, err = importRows(ctx, service, Sensor, reader, Mapping, func(payload beitianModels.CreatePayload, createdAt time.Time, quality string) beitianModels.Beitian {
+			data := payload.ToModel()
+			data.CreatedAt = createdAt
+			data.Quality = quality
+			return data
+		}, service.beitianService.CreateBatch)
+	case "bmp":
+		report, err = importRows(ctx, service, Sensor, reader, Mapping, func(payload bmpModels.CreatePayload, createdAt time.Time, quality string) bmpModels.Bmp {
+			data := payload.ToModel()
+			data.CreatedAt = createdAt
+			data.Quality = quality
+			return data
+		}, service.bmpService.CreateBatch)
+	case "ina":
+		report, err = importRows(ctx, service, Sensor, reader, Mapping, func(payload inaModels.CreatePayload, createdAt time.Time, quality string) inaModels.Ina {
+			data := payload.ToModel()
+			data.CreatedAt = createdAt
+			data.Quality = quality
+			return data
+		}, service.inaService.CreateBatch)
+	case "pzem":
+		report, err = importRows(ctx, service, Sensor, reader, Mapping, func(payload pzemModels.CreatePayload, createdAt time.Time, quality string) pzemModels.Pzem {
+			data := payload.ToModel()
+			data.CreatedAt = createdAt
+			data.Quality = quality
+			return data
+		}, service.pzemService.CreateBatch)
+	case "thigrow":
+		report, err = importRows(ctx, service, Sensor, reader, Mapping, func(payload thigrowModels.CreatePayload, createdAt time.Time, quality string) thigrowModels.Thigrow {
+			data := payload.ToModel()
+			data.CreatedAt = createdAt
+			data.Quality = quality
+			return data
+		}, service.thigrowService.CreateBatch)
+	case "thm":
+		report, err = importRows(ctx, service, Sensor, reader, Mapping, func(payload thmModels.CreatePayload, createdAt time.Time, quality string) thmModels.Thm {
+			data := payload.ToModel()
+			data.CreatedAt = createdAt
+			data.Quality = quality
+			return data
+		}, service.thmService.CreateBatch)
+	default:
+		service.logger.ErrorContext(ctx, "Jenis sensor tidak dikenali", "sensor", Sensor)
+		return response, helpers.Validation(i18n.Msg(i18n.SensorUnknown, Sensor), nil)
+	}
+
+	report.Sensor = Sensor
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membaca file CSV sensor", "sensor", Sensor, logging.Error(err))
+		return response, helpers.Validation(i18n.Msg(i18n.ImportReadFailed, Sensor), report)
+	}
+
+	service.logger.InfoContext(ctx, "Import sensor selesai", "sensor", Sensor, "total", report.TotalRows, "inserted", report.Inserted, "rejected", report.Rejected)
+	response.SetMessage(ctx, i18n.ImportFinished, Sensor, report.Inserted, report.Rejected)
+	response.Data = report
+
+	return response, nil
+}
+
+// importRows membaca CSV baris per baris, memvalidasi setiap baris dengan aturan
+// CreatePayload dan rentang nilai field sensor lalu menyimpan data yang valid
+// secara batch.
+func importRows[P any, M any](ctx context.Context, service *importService, Sensor string, reader *csv.Reader, mapping map[string]string, toModel func(P, time.Time, string) M, insert func(context.Context, []M) (helpers.Response, error)) (models.ImportReport, error) {
+	report := models.ImportReport{Rows: []models.RejectedRow{}}
+
+	header, err := reader.Read()
+	if err != nil {
+		return report, err
+	}
+	columns := mapColumns(header, mapping)
+	sensor, _ := sensors.Find(Sensor)
+
+	var batch []M
+	var batchLines []int
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if _, err := insert(ctx, batch); err != nil {
+			for _, line := range batchLines {
+				report.Rows = append(report.Rows, models.RejectedRow{Line: line, Reasons: map[string]string{"database": helpers.ErrorMessage(ctx, err)}})
+			}
+			report.Rejected += len(batch)
+		} else {
+			report.Inserted += len(batch)
+		}
+		batch, batchLines = nil, nil
+	}
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			report.TotalRows++
+			report.Rejected++
+			report.Rows = append(report.Rows, models.RejectedRow{Line: line, Reasons: map[string]string{"csv": err.Error()}})
+			continue
+		}
+		report.TotalRows++
+
+		var payload P
+		reasons := setPayload(ctx, &payload, columns, record)
+
+		var createdAt time.Time
+		if index, ok := columns["created_at"]; ok && index < len(record) && record[index] != "" {
+			if createdAt, err = helpers.ParseTime(record[index]); err != nil {
+				reasons["created_at"] = helpers.ErrorMessage(ctx, err)
+			}
+		}
+
+		if len(reasons) == 0 {
+			if err := service.validate.Struct(payload); err != nil {
+				reasons = helpers.ValidationErrorList(ctx, err, payload)
+			}
+		}
+
+		quality := helpers.AssessQuality(sensor.Fields, &payload)
+		if len(reasons) == 0 && quality.Rejected() {
+			reasons = quality.ErrorList(ctx)
+		}
+
+		if len(reasons) > 0 {
+			metrics.RecordValidationRejections(Sensor, reasons)
+			report.Rejected++
+			report.Rows = append(report.Rows, models.RejectedRow{Line: line, Reasons: reasons})
+			continue
+		}
+
+		batch = append(batch, toModel(payload, createdAt, quality.Quality))
+		batchLines = append(batchLines, line)
+		if len(batch) >= service.batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	return report, nil
+}
+
+// mapColumns memetakan nama field ke indeks kolom CSV. Satuan pada header
+// hasil ekspor seperti "tekanan_udara (hPa)" diabaikan.
+func mapColumns(header []string, mapping map[string]string) map[string]int {
+	columns := make(map[string]int)
+	for index, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if unit := strings.Index(name, " ("); unit > 0 {
+			name = name[:unit]
+		}
+		if mapped, ok := mapping[name]; ok {
+			name = mapped
+		}
+		columns[name] = index
+	}
+	return columns
+}
+
+// setPayload mengisi field payload berdasarkan tag json dari nilai kolom CSV.
+func setPayload(ctx context.Context, payload interface{}, columns map[string]int, record []string) map[string]string {
+	reasons := make(map[string]string)
+	value := reflect.ValueOf(payload).Elem()
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := field.Tag.Get("json")
+
+		index, ok := columns[name]
+		if !ok || index >= len(record) {
+			continue
+		}
+		raw := strings.TrimSpace(record[index])
+
+		switch field.Type.Kind() {
+		case reflect.String:
+			value.Field(i).SetString(raw)
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			if raw == "" {
+				continue
+			}
+			number, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				reasons[name] = i18n.T(ctx, i18n.FieldInteger, name)
+				continue
+			}
+			value.Field(i).SetInt(number)
+		}
+	}
+
+	return reasons
+}
+
+type ImportService interface {
+	Import(ctx context.Context, Sensor string, r io.Reader, Mapping map[string]string) (helpers.Response, error)
+}
+
+func NewImportService(db *gorm.DB, logger *slog.Logger) ImportService {
+	batchSize, err := strconv.Atoi(os.Getenv("IMPORT_BATCH_SIZE"))
+	if err != nil || batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &importService{
+		logger:         logger.With("component", "importer"),
+		beitianService: beitianServices.NewBeitianService(db, logger),
+		bmpService:     bmpServices.NewBmpService(db, logger),
+		inaService:     inaServices.NewInaService(db, logger),
+		pzemService:    pzemServices.NewPzemService(db, logger),
+		thigrowService: thigrowServices.NewThigrowService(db, logger),
+		thmService:     thmServices.NewThmService(db, logger),
+		validate:       v1.New(),
+		batchSize:      batchSize,
+	}
+}
+
+// ParseMapping membaca mapping kolom dengan format "kolom_csv=field,kolom_lain=field".
+func ParseMapping(value string) map[string]string {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		mapping[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.TrimSpace(parts[1])
+	}
+	return mapping
+}
@@ -1,12 +1,14 @@
 package controllers
 
 import (
-	"fmt"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
 	"iot-golang/internal/ina/models"
 	"iot-golang/internal/ina/services"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
 	"net/http"
-	"reflect"
 	"strconv"
 
 	v1 "github.com/go-playground/validator/v10"
@@ -15,181 +17,190 @@ import (
 )
 
 type InaController struct {
+	logger     *slog.Logger
 	inaService services.InaService
 	validate   v1.Validate
 }
 
-var httpStatus int
-
 func (controller InaController) Create(c echo.Context) error {
-	type payload struct {
-		DeviceToken string `json:"device_token" validate:"required"`
-		Tegangan    string `json:"tegangan" validate:"required"`
-		Arus        string `json:"arus" validate:"required"`
-		Daya        string `json:"daya" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.CreatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		if err := c.Bind(payloadValidator); err != nil {
-			return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-				Status:   400,
-				Errors:   err,
-				Messages: "Invalid request body",
-			})
-		}
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
 	}
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
-
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+		metrics.RecordValidationRejections("ina", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
-	result := controller.inaService.Create(models.Ina{DeviceToken: payloadValidator.DeviceToken, Tegangan: payloadValidator.Tegangan, Arus: payloadValidator.Arus, Daya: payloadValidator.Daya})
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("ina", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-	if result.Status == 201 {
-		httpStatus = http.StatusCreated
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	reading := payloadValidator.ToModel()
+	reading.Quality = report.Quality
+	result, err := controller.inaService.Create(c.Request().Context(), reading)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusCreated)
 }
 
 func (controller InaController) Update(c echo.Context) error {
-	type payload struct {
-		Tegangan string `json:"tegangan" validate:"required"`
-		Arus     string `json:"arus" validate:"required"`
-		Daya     string `json:"daya" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.UpdatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		if err := c.Bind(payloadValidator); err != nil {
-			return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-				Status:   400,
-				Errors:   err,
-				Messages: "Invalid request body",
-			})
-		}
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
 	}
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
+
+		metrics.RecordValidationRejections("ina", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("ina", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
 	idIna, _ := strconv.Atoi(c.Param("id"))
-	result := controller.inaService.Update(int64(idIna), models.Ina{Tegangan: payloadValidator.Tegangan, Arus: payloadValidator.Arus, Daya: payloadValidator.Daya})
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.inaService.Update(c.Request().Context(), int64(idIna), models.Ina{Tegangan: payloadValidator.Tegangan, Arus: payloadValidator.Arus, Daya: payloadValidator.Daya, Quality: report.Quality})
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller InaController) Delete(c echo.Context) error {
 	idIna, _ := strconv.Atoi(c.Param("id"))
-	result := controller.inaService.Delete(int64(idIna))
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.inaService.Delete(c.Request().Context(), int64(idIna))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller InaController) GetAll(c echo.Context) error {
-	result := controller.inaService.GetAll()
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
 	}
 
-	return c.JSON(httpStatus, result)
+	result, err := controller.inaService.GetAll(c.Request().Context(), quality)
+	if err != nil {
+		return err
+	}
+
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller InaController) GetById(c echo.Context) error {
-	idIna, _ := strconv.Atoi(c.QueryParam("id"))
-	result := controller.inaService.GetById(int64(idIna))
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	idIna, _ := strconv.Atoi(c.QueryParam("id"))
+	result, err := controller.inaService.GetById(c.Request().Context(), int64(idIna))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller InaController) GetByToken(c echo.Context) error {
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
 	idTokenIna := c.QueryParam("device_token")
-	result := controller.inaService.GetByToken(idTokenIna)
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.inaService.GetByToken(c.Request().Context(), idTokenIna, quality)
+	if err != nil {
+		return err
+	}
+
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller InaController) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = helpers.ExportCSV
 	}
 
-	return c.JSON(httpStatus, result)
+	contentType, err := helpers.ExportContentType(format)
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"format": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	response := helpers.NewExportResponse(c.Response(), contentType, helpers.ExportFilename("ina", start, end, format))
+	err = controller.inaService.Export(c.Request().Context(), c.QueryParam("device_token"), start, end, quality, units, format, response)
+	if err != nil && c.Response().Committed {
+		// Status 200 sudah terkirim, koneksi diputus agar client tidak
+		// menganggap file yang terpotong sebagai file lengkap.
+		controller.logger.ErrorContext(c.Request().Context(), "Ekspor terhenti setelah response dikirim", logging.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+	return err
 }
 
-func NewInaController(db *gorm.DB) InaController {
-	service := services.NewInaService(db)
+func NewInaController(db *gorm.DB, logger *slog.Logger) InaController {
+	service := services.NewInaService(db, logger)
 	controller := InaController{
+		logger:     logger.With("sensor", "ina"),
 		inaService: service,
 		validate:   *v1.New(),
 	}
@@ -1,13 +1,30 @@
 package models
 
+import (
+	"iot-golang/internal/helpers"
+	"time"
+)
+
 type Ina struct {
-	Id          int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	DeviceToken string `json:"device_token" gorm:"column:device_token"`
-	Tegangan    string `json:"tegangan" gorm:"column:tegangan"`
-	Arus        string `json:"arus" gorm:"column:arus"`
-	Daya        string `json:"daya" gorm:"column:daya"`
+	Id           int64             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken  string            `json:"device_token" gorm:"column:device_token"`
+	Tegangan     string            `json:"tegangan" gorm:"column:tegangan"`
+	Arus         string            `json:"arus" gorm:"column:arus"`
+	Daya         string            `json:"daya" gorm:"column:daya"`
+	Quality      string            `json:"quality" gorm:"column:quality;default:good"`
+	AnomalyScore float64           `json:"anomaly_score" gorm:"column:anomaly_score;default:0"`
+	RawValues    map[string]string `json:"raw_values,omitempty" gorm:"column:raw_values;serializer:json"`
+	CreatedAt    time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 }
 
 func (Ina) TableName() string {
 	return "db_sensor_ina219"
 }
+
+// Fields berisi metadata field sensor ina beserta satuan, rentang nilai dan
+// batas perubahan per menitnya.
+var Fields = []helpers.Field{
+	{Name: "tegangan", Column: "tegangan", Unit: "V", Range: helpers.Between(0, 26), MaxRate: 5},
+	{Name: "arus", Column: "arus", Unit: "mA", Range: helpers.Between(-3200, 3200)},
+	{Name: "daya", Column: "daya", Unit: "mW", Range: helpers.Between(0, 83200)},
+}
@@ -0,0 +1,26 @@
+package models
+
+// CreatePayload adalah payload untuk membuat data sensor ina baru.
+// Aturan validasi yang sama dipakai oleh endpoint create dan import CSV.
+type CreatePayload struct {
+	DeviceToken string `json:"device_token" validate:"required"`
+	Tegangan    string `json:"tegangan" validate:"required,numeric"`
+	Arus        string `json:"arus" validate:"required,numeric"`
+	Daya        string `json:"daya" validate:"required,numeric"`
+}
+
+func (payload CreatePayload) ToModel() Ina {
+	return Ina{
+		DeviceToken: payload.DeviceToken,
+		Tegangan:    payload.Tegangan,
+		Arus:        payload.Arus,
+		Daya:        payload.Daya,
+	}
+}
+
+// UpdatePayload adalah payload untuk mengubah data sensor ina.
+type UpdatePayload struct {
+	Tegangan string `json:"tegangan" validate:"required,numeric"`
+	Arus     string `json:"arus" validate:"required,numeric"`
+	Daya     string `json:"daya" validate:"required,numeric"`
+}
@@ -1,7 +1,13 @@
 package repositories
 
 import (
+	"context"
+	"iot-golang/internal/helpers"
 	"iot-golang/internal/ina/models"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,50 +17,118 @@ type dbIna struct {
 }
 
 // Create implements InaRepository.
-func (db *dbIna) Create(ina models.Ina) error {
-	return db.Conn.Create(&ina).Error
+func (db *dbIna) Create(ctx context.Context, ina models.Ina) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "Create")).Create(&ina).Error
+}
+
+// CreateBatch implements InaRepository.
+func (db *dbIna) CreateBatch(ctx context.Context, ina []models.Ina, BatchSize int) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "CreateBatch")).CreateInBatches(&ina, BatchSize).Error
 }
 
 // Delete implements InaRepository.
-func (db *dbIna) Delete(Id int64) error {
-	return db.Conn.Delete(&models.Ina{Id: Id}).Error
+func (db *dbIna) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "Delete")).Delete(&models.Ina{Id: Id}).Error
 }
 
 // GetAll implements InaRepository.
-func (db *dbIna) GetAll() ([]models.Ina, error) {
+func (db *dbIna) GetAll(ctx context.Context, Quality []string) ([]models.Ina, error) {
+	var data []models.Ina
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "GetAll")).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// Query implements InaRepository.
+func (db *dbIna) Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Ina, error) {
 	var data []models.Ina
-	result := db.Conn.Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "Query")).Scopes(Query.Scope).Find(&data)
 	return data, result.Error
 }
 
 // GetById implements InaRepository.
-func (db *dbIna) GetById(Id int64) (models.Ina, error) {
+func (db *dbIna) GetById(ctx context.Context, Id int64) (models.Ina, error) {
 	var data models.Ina
-	result := db.Conn.Where("id", Id).First(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "GetById")).Where("id", Id).First(&data)
 	return data, result.Error
 }
 
 // GetByToken implements InaRepository.
-func (db *dbIna) GetByToken(DeviceToken string) ([]models.Ina, error) {
+func (db *dbIna) GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Ina, error) {
+	var data []models.Ina
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "GetByToken")).Where("device_token", DeviceToken).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// GetNewByToken implements InaRepository.
+func (db *dbIna) GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Ina, error) {
 	var data []models.Ina
-	result := db.Conn.Where("device_token", DeviceToken).Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "GetNewByToken")).Where("device_token = ?", DeviceToken).Order("id desc").Limit(1).Find(&data)
 	return data, result.Error
 }
 
+// GetNewByTokens implements InaRepository.
+// Data terbaru setiap perangkat diambil dalam satu query dengan subquery id
+// terbesar per device_token.
+func (db *dbIna) GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Ina, error) {
+	var data []models.Ina
+	latest := db.Conn.Model(&models.Ina{}).Select("MAX(id)").Where("device_token IN ?", DeviceTokens).Group("device_token")
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "GetNewByTokens")).Where("id IN (?)", latest).Find(&data)
+	return data, result.Error
+}
+
+// Export implements InaRepository.
+// Data dibaca memakai cursor Rows sehingga tidak dimuat seluruhnya ke memori.
+func (db *dbIna) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(ina models.Ina) error) error {
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "Export")).Model(&models.Ina{}).Where("created_at >= ? AND created_at < ?", Start, End).Scopes(helpers.QualityScope(Quality))
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+
+	rows, err := query.Order("created_at asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data models.Ina
+		if err := db.Conn.ScanRows(rows, &data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 // Update implements InaRepository.
-func (db *dbIna) Update(Id int64, ina models.Ina) error {
-	return db.Conn.Where("id", Id).Updates(ina).Error
+// Kolom nilai, quality dan raw_values selalu ditulis, termasuk raw_values yang
+// kosong, agar nilai mentah lama tidak tertinggal setelah data diubah.
+func (db *dbIna) Update(ctx context.Context, Id int64, ina models.Ina) error {
+	columns := []string{"quality", "raw_values"}
+	for _, field := range models.Fields {
+		columns = append(columns, field.Column)
+	}
+
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "ina", "Update")).Model(&models.Ina{}).Where("id", Id).Select(columns).Updates(ina).Error
 }
 
 type InaRepository interface {
-	Create(ina models.Ina) error
-	Update(Id int64, ina models.Ina) error
-	Delete(Id int64) error
-	GetById(Id int64) (models.Ina, error)
-	GetByToken(DeviceToken string) ([]models.Ina, error)
-	GetAll() ([]models.Ina, error)
+	Create(ctx context.Context, ina models.Ina) error
+	CreateBatch(ctx context.Context, ina []models.Ina, BatchSize int) error
+	Update(ctx context.Context, Id int64, ina models.Ina) error
+	Delete(ctx context.Context, Id int64) error
+	GetById(ctx context.Context, Id int64) (models.Ina, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Ina, error)
+	GetAll(ctx context.Context, Quality []string) ([]models.Ina, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Ina, error)
+	GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Ina, error)
+	GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Ina, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(ina models.Ina) error) error
 }
 
-func NewInaRepository(Conn *gorm.DB) InaRepository {
-	return &dbIna{Conn: Conn}
+func NewInaRepository(Conn *gorm.DB, logger *slog.Logger) InaRepository {
+	return &dbIna{Conn: logging.Session(Conn, logger.With("repository", "ina"))}
 }
@@ -1,125 +1,234 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	anomalyModels "iot-golang/internal/anomaly/models"
+	anomalyServices "iot-golang/internal/anomaly/services"
+	"iot-golang/internal/broadcast"
+	calibrationServices "iot-golang/internal/calibration/services"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
 	"iot-golang/internal/ina/models"
 	"iot-golang/internal/ina/repositories"
-	"log"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"iot-golang/internal/tracing"
+	"log/slog"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type inaService struct {
-	inaRepo repositories.InaRepository
+	logger             *slog.Logger
+	inaRepo            repositories.InaRepository
+	anomalyService     anomalyServices.AnomalyService
+	calibrationService calibrationServices.CalibrationService
 }
 
 // Create implements InaService.
-func (service *inaService) Create(ina models.Ina) helpers.Response {
+func (service *inaService) Create(ctx context.Context, ina models.Ina) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "InaService.Create")
+	defer span.End()
+
 	var response helpers.Response
-	if err := service.inaRepo.Create(ina); err != nil {
-		log.Println("ERROR: Gagal membuat data sensor baru, error :" + err.Error())
-		response.Status = 500
-		response.Messages = "Gagal membuat data sensor baru"
-	} else {
-		log.Println("SUCCESS: Berhasil membuat data sensor baru")
-		response.Status = 201
-		response.Messages = "Berhasil membuat data sensor baru"
-	}
-	return response
+	raw, err := service.calibrationService.Calibrator(ctx, "ina").Apply(ina.DeviceToken, time.Now(), &ina)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", ina.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+	if raw != nil {
+		ina.RawValues = raw
+		ina.Quality = helpers.AssessQuality(models.Fields, &ina).Quality
+	}
+
+	inspection := service.anomalyService.Inspect(ctx, "ina", ina.DeviceToken, ina.Quality, helpers.FieldValues(models.Fields, ina))
+	ina.AnomalyScore = inspection.Score
+
+	if err := service.inaRepo.Create(ctx, ina); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "device_token", ina.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspection)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "device_token", ina.DeviceToken)
+	metrics.RecordIngested("ina", ina.DeviceToken, 1)
+	broadcast.Publish("ina", ina.DeviceToken, ina)
+	response.SetMessage(ctx, i18n.SensorCreated)
+	return response, nil
+}
+
+// CreateBatch implements InaService.
+func (service *inaService) CreateBatch(ctx context.Context, ina []models.Ina) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "InaService.CreateBatch")
+	defer span.End()
+
+	var response helpers.Response
+	calibrator := service.calibrationService.Calibrator(ctx, "ina")
+	now := time.Now()
+	tokens := make([]string, len(ina))
+	readings := make([]anomalyModels.Reading, len(ina))
+	for i := range ina {
+		tokens[i] = ina[i].DeviceToken
+		// Data tanpa created_at akan diberi waktu sekarang oleh database
+		at := ina[i].CreatedAt
+		if at.IsZero() {
+			at = now
+		}
+		raw, err := calibrator.Apply(ina[i].DeviceToken, at, &ina[i])
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", ina[i].DeviceToken, logging.Error(err))
+			return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+		}
+		if raw != nil {
+			ina[i].RawValues = raw
+			ina[i].Quality = helpers.AssessQuality(models.Fields, &ina[i]).Quality
+		}
+		readings[i] = anomalyModels.Reading{DeviceToken: ina[i].DeviceToken, Quality: ina[i].Quality, Values: helpers.FieldValues(models.Fields, ina[i]), At: ina[i].CreatedAt}
+	}
+
+	inspections := service.anomalyService.InspectBatch(ctx, "ina", readings)
+	for i := range inspections {
+		ina[i].AnomalyScore = inspections[i].Score
+	}
+
+	if err := service.inaRepo.CreateBatch(ctx, ina, len(ina)); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "count", len(ina), "device_tokens", helpers.DistinctTokens(tokens), logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspections...)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "count", len(ina), "device_tokens", helpers.DistinctTokens(tokens))
+	for _, reading := range ina {
+		metrics.RecordIngested("ina", reading.DeviceToken, 1)
+		broadcast.Publish("ina", reading.DeviceToken, reading)
+	}
+	response.SetMessage(ctx, i18n.SensorCreated)
+
+	return response, nil
 }
 
 // Delete implements InaService.
-func (service *inaService) Delete(Id int64) helpers.Response {
+func (service *inaService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "InaService.Delete")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum dihapus
-	_, findErr := service.inaRepo.GetById(Id)
+	_, findErr := service.inaRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
 	}
 
 	// Data ditemukan, lakukan penghapusan
-	err := service.inaRepo.Delete(Id)
+	err := service.inaRepo.Delete(ctx, Id)
 	if err != nil {
-		log.Printf("ERROR: Gagal menghapus data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal menghapus data sensor dengan id : %d", Id)
-	} else {
-		// Jika penghapusan berhasil
-		log.Println("SUCCESS: Data sensor berhasil dihapus")
-		response.Status = 200
-		response.Messages = "Data sensor berhasil dihapus"
+		service.logger.ErrorContext(ctx, "Gagal menghapus data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorDeleteFailed, Id), err)
 	}
 
-	return response
+	// Jika penghapusan berhasil
+	service.logger.InfoContext(ctx, "Data sensor berhasil dihapus")
+	response.SetMessage(ctx, i18n.SensorDeleted)
+
+	return response, nil
 }
 
 // GetAll implements InaService.
-func (service *inaService) GetAll() helpers.Response {
+func (service *inaService) GetAll(ctx context.Context, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "InaService.GetAll")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.inaRepo.GetAll()
+	data, err := service.inaRepo.GetAll(ctx, Quality)
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil seluruh data sensor : error %v", err)
-		response.Status = 500
-		response.Messages = "Gagal mengambil seluruh data sensor"
-	} else {
-		log.Println("SUCCESS: Berhasil mengambil semua data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengambil semua data sensor"
-		response.Data = data
-	}
-	return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data sensor", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil semua data sensor")
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
+}
+
+// Query implements InaService.
+// Data yang kosong tetap dikembalikan sebagai daftar kosong.
+func (service *inaService) Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "InaService.Query")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.inaRepo.Query(ctx, Query)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", Query.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+	if data == nil {
+		data = []models.Ina{}
+	}
+
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
 }
 
 // GetById implements InaService.
-func (service *inaService) GetById(Id int64) helpers.Response {
+func (service *inaService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "InaService.GetById")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.inaRepo.GetById(Id)
+	data, err := service.inaRepo.GetById(ctx, Id)
 
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d", Id)
-			response.Status = 404
-			response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		} else {
-			log.Printf("ERROR: Gagal mengambil data sensor dengan id : %d, error: %v", Id, err)
-			response.Status = 500
-			response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan id : %d", Id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
 		}
-	} else {
-		// Konversi string ke tipe data float64
-		Tegangan, _ := strconv.ParseFloat(data.Tegangan, 64)
-		Arus, _ := strconv.ParseFloat(data.Arus, 64)
-		Daya, _ := strconv.ParseFloat(data.Daya, 64)
 
-		data.Tegangan = fmt.Sprintf("%.2f", Tegangan)
-		data.Arus = fmt.Sprintf("%.2f", Arus)
-		data.Daya = fmt.Sprintf("%.2f", Daya)
-
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Data = data
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
 	}
 
-	return response
+	// Konversi string ke tipe data float64
+	Tegangan, _ := strconv.ParseFloat(data.Tegangan, 64)
+	Arus, _ := strconv.ParseFloat(data.Arus, 64)
+	Daya, _ := strconv.ParseFloat(data.Daya, 64)
+
+	data.Tegangan = fmt.Sprintf("%.2f", Tegangan)
+	data.Arus = fmt.Sprintf("%.2f", Arus)
+	data.Daya = fmt.Sprintf("%.2f", Daya)
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "id", Id)
+	response.SetMessage(ctx, i18n.SensorFoundByID, Id)
+	response.Data = data
+
+	return response, nil
 }
 
 // GetByToken implements InaService.
-func (service *inaService) GetByToken(DeviceToken string) helpers.Response {
+func (service *inaService) GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "InaService.GetByToken")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.inaRepo.GetByToken(DeviceToken)
+	data, err := service.inaRepo.GetByToken(ctx, DeviceToken, Quality)
 
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil data sensor dengan token : %s, error: %v", DeviceToken, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan token : %s", DeviceToken)
-		return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByTokenFail, DeviceToken), err)
 	}
 
 	var foundData []models.Ina
@@ -139,57 +248,113 @@ func (service *inaService) GetByToken(DeviceToken string) helpers.Response {
 	}
 
 	if len(foundData) == 0 {
-		log.Printf("INFO: Tidak menemukan data sensor dengan token : %s", DeviceToken)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan token : %s", DeviceToken)
-	} else {
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Data = foundData
+		service.logger.InfoContext(ctx, "Tidak menemukan data sensor", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByToken, DeviceToken))
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorFoundByToken, DeviceToken)
+	response.Data = foundData
+
+	return response, nil
 }
 
 // Update implements InaService.
-func (service *inaService) Update(Id int64, ina models.Ina) helpers.Response {
+func (service *inaService) Update(ctx context.Context, Id int64, ina models.Ina) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "InaService.Update")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum di update
-	_, findErr := service.inaRepo.GetById(Id)
+	current, findErr := service.inaRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
+	}
+
+	// Nilai baru dikalibrasi dengan kalibrasi yang berlaku pada waktu data
+	// tersebut dibuat, sama seperti saat data disimpan pertama kali
+	raw, err := service.calibrationService.Calibrator(ctx, "ina").Apply(current.DeviceToken, current.CreatedAt, &ina)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", current.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+	ina.RawValues = raw
+	if raw != nil {
+		ina.Quality = helpers.AssessQuality(models.Fields, &ina).Quality
 	}
 
 	// Data ditemukan, lakukan update
-	err := service.inaRepo.Update(Id, ina)
+	err = service.inaRepo.Update(ctx, Id, ina)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengubah data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+
+	// Jika update data berhasil
+	service.logger.InfoContext(ctx, "Berhasil mengubah data sensor")
+	response.SetMessage(ctx, i18n.SensorUpdated)
+
+	return response, nil
+}
+
+// Export implements InaService.
+func (service *inaService) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "InaService.Export")
+	defer span.End()
+
+	// Writer baru dibuat setelah baris pertama terbaca sehingga kegagalan
+	// query tidak membuat header response terkirim lebih dulu.
+	var writer helpers.ExportWriter
+	open := func() (err error) {
+		if writer != nil {
+			return nil
+		}
+		if writer, err = helpers.NewExportWriter(Format, w, "ina"); err != nil {
+			return err
+		}
+		return writer.WriteRow(helpers.ExportHeaders(Units.Fields()))
+	}
+
+	count := 0
+	err := service.inaRepo.Export(ctx, DeviceToken, Start, End, Quality, func(data models.Ina) error {
+		if err := open(); err != nil {
+			return err
+		}
+		count++
+		Units.Apply(&data)
+		return writer.WriteRow([]string{strconv.FormatInt(data.Id, 10), data.DeviceToken, data.CreatedAt.Format(time.RFC3339), data.Tegangan, data.Arus, data.Daya, data.Quality})
+	})
 	if err != nil {
-		log.Printf("ERROR: Gagal mengubah data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengubah data sensor dengan id : %d", Id)
-	} else {
-		// Jika update data berhasil
-		log.Println("SUCCESS: Berhasil mengubah data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengubah data sensor"
+		service.logger.ErrorContext(ctx, "Gagal mengekspor data sensor", "device_token", DeviceToken, logging.Error(err))
+		return err
+	}
+	if err := open(); err != nil {
+		return err
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengekspor data sensor", "count", count, "device_token", DeviceToken)
+	return writer.Close()
 }
 
 type InaService interface {
-	Create(ina models.Ina) helpers.Response
-	Update(Id int64, ina models.Ina) helpers.Response
-	Delete(Id int64) helpers.Response
-	GetById(Id int64) helpers.Response
-	GetByToken(DeviceToken string) helpers.Response
-	GetAll() helpers.Response
+	Create(ctx context.Context, ina models.Ina) (helpers.Response, error)
+	CreateBatch(ctx context.Context, ina []models.Ina) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, ina models.Ina) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error)
+	GetAll(ctx context.Context, Quality []string) (helpers.Response, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error
 }
 
-func NewInaService(db *gorm.DB) InaService {
-	return &inaService{inaRepo: repositories.NewInaRepository(db)}
+func NewInaService(db *gorm.DB, logger *slog.Logger) InaService {
+	return &inaService{logger: logger.With("sensor", "ina"), inaRepo: repositories.NewInaRepository(db, logger), anomalyService: anomalyServices.NewAnomalyService(db, logger), calibrationService: calibrationServices.NewCalibrationService(db, logger)}
 }
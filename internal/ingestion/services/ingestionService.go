@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	beitianModels "iot-golang/internal/beitian/models"
+	beitianServices "iot-golang/internal/beitian/services"
+	bmpModels "iot-golang/internal/bmp/models"
+	bmpServices "iot-golang/internal/bmp/services"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	inaModels "iot-golang/internal/ina/models"
+	inaServices "iot-golang/internal/ina/services"
+	"iot-golang/internal/metrics"
+	pzemModels "iot-golang/internal/pzem/models"
+	pzemServices "iot-golang/internal/pzem/services"
+	"iot-golang/internal/sensors"
+	thigrowModels "iot-golang/internal/thigrow/models"
+	thigrowServices "iot-golang/internal/thigrow/services"
+	thmModels "iot-golang/internal/thm/models"
+	thmServices "iot-golang/internal/thm/services"
+	"iot-golang/internal/tracing"
+	"log/slog"
+
+	v1 "github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type ingestionService struct {
+	logger         *slog.Logger
+	beitianService beitianServices.BeitianService
+	bmpService     bmpServices.BmpService
+	inaService     inaServices.InaService
+	pzemService    pzemServices.PzemService
+	thigrowService thigrowServices.ThigrowService
+	thmService     thmServices.ThmService
+	validate       *v1.Validate
+}
+
+// Payload implements IngestionService.
+func (service *ingestionService) Payload(Sensor string) (interface{}, error) {
+	switch Sensor {
+	case "beitian":
+		return new(beitianModels.CreatePayload), nil
+	case "bmp":
+		return new(bmpModels.CreatePayload), nil
+	case "ina":
+		return new(inaModels.CreatePayload), nil
+	case "pzem":
+		return new(pzemModels.CreatePayload), nil
+	case "thigrow":
+		return new(thigrowModels.CreatePayload), nil
+	case "thm":
+		return new(thmModels.CreatePayload), nil
+	}
+
+	return nil, helpers.NotFound(i18n.Msg(i18n.SensorUnknown, Sensor))
+}
+
+// Create implements IngestionService.
+// Payload divalidasi dengan aturan CreatePayload dan rentang nilai field sensor
+// seperti route create REST, lalu disimpan lewat service sensor.
+func (service *ingestionService) Create(ctx context.Context, Sensor string, Payload interface{}) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "IngestionService.Create")
+	defer span.End()
+
+	var response helpers.Response
+	sensor, ok := sensors.Find(Sensor)
+	if !ok {
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorUnknown, Sensor))
+	}
+
+	if err := service.validate.Struct(Payload); err != nil {
+		errorList := helpers.ValidationErrorList(ctx, err, Payload)
+		metrics.RecordValidationRejections(Sensor, errorList)
+		service.logger.WarnContext(ctx, "Request di tolak", "sensor", Sensor, "errors", errorList)
+
+		return response, helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	report := helpers.AssessQuality(sensor.Fields, Payload)
+	if report.Rejected() {
+		errorList := report.ErrorList(ctx)
+		metrics.RecordValidationRejections(Sensor, errorList)
+		service.logger.WarnContext(ctx, "Data di luar rentang fisik", "sensor", Sensor, "errors", errorList)
+
+		return response, helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	switch payload := Payload.(type) {
+	case *beitianModels.CreatePayload:
+		data := payload.ToModel()
+		data.Quality = report.Quality
+		return service.beitianService.Create(ctx, data)
+	case *bmpModels.CreatePayload:
+		data := payload.ToModel()
+		data.Quality = report.Quality
+		return service.bmpService.Create(ctx, data)
+	case *inaModels.CreatePayload:
+		data := payload.ToModel()
+		data.Quality = report.Quality
+		return service.inaService.Create(ctx, data)
+	case *pzemModels.CreatePayload:
+		data := payload.ToModel()
+		data.Quality = report.Quality
+		return service.pzemService.Create(ctx, data)
+	case *thigrowModels.CreatePayload:
+		data := payload.ToModel()
+		data.Quality = report.Quality
+		return service.thigrowService.Create(ctx, data)
+	case *thmModels.CreatePayload:
+		data := payload.ToModel()
+		data.Quality = report.Quality
+		return service.thmService.Create(ctx, data)
+	}
+
+	service.logger.ErrorContext(ctx, "Payload tidak sesuai dengan jenis sensor", "sensor", Sensor)
+	return response, helpers.NotFound(i18n.Msg(i18n.SensorUnknown, Sensor))
+}
+
+// IngestionService menyimpan satu data sensor dari protokol selain REST
+// (gRPC dan CoAP) dengan validasi yang sama dengan route create.
+type IngestionService interface {
+	// Payload mengembalikan pointer CreatePayload kosong untuk jenis sensor.
+	Payload(Sensor string) (interface{}, error)
+	Create(ctx context.Context, Sensor string, Payload interface{}) (helpers.Response, error)
+}
+
+func NewIngestionService(db *gorm.DB, logger *slog.Logger) IngestionService {
+	return &ingestionService{
+		logger:         logger,
+		beitianService: beitianServices.NewBeitianService(db, logger),
+		bmpService:     bmpServices.NewBmpService(db, logger),
+		inaService:     inaServices.NewInaService(db, logger),
+		pzemService:    pzemServices.NewPzemService(db, logger),
+		thigrowService: thigrowServices.NewThigrowService(db, logger),
+		thmService:     thmServices.NewThmService(db, logger),
+		validate:       v1.New(),
+	}
+}
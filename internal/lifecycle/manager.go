@@ -0,0 +1,228 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iot-golang/internal/logging"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const defaultDrainTimeout = 15 * time.Second
+
+// Worker adalah proses background yang berjalan selama server hidup. Run harus
+// berhenti ketika context dibatalkan.
+type Worker interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// WorkerStatus adalah keadaan worker yang dikelola Manager.
+type WorkerStatus struct {
+	Name      string     `json:"name"`
+	Alive     bool       `json:"alive"`
+	StartedAt time.Time  `json:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+	// idle menandai hook yang hanya boleh berjalan setelah seluruh worker berhenti.
+	idle bool
+}
+
+// Manager menjalankan HTTP server beserta worker background dan mematikannya
+// secara berurutan ketika menerima SIGINT/SIGTERM: berhenti menerima koneksi,
+// menunggu request yang sedang berjalan, menghentikan worker, lalu menjalankan
+// hook shutdown (flush data dan menutup koneksi database). Setiap hook
+// mendapat batas waktu sendiri sehingga tidak memakai sisa waktu drain.
+type Manager struct {
+	logger       *slog.Logger
+	drainTimeout time.Duration
+	workers      []Worker
+	hooks        []shutdownHook
+
+	mutex    sync.Mutex
+	statuses map[string]*WorkerStatus
+}
+
+func NewManager(drainTimeout time.Duration, logger *slog.Logger) *Manager {
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	return &Manager{
+		logger:       logger.With("component", "lifecycle"),
+		drainTimeout: drainTimeout,
+		statuses:     map[string]*WorkerStatus{},
+	}
+}
+
+// AddWorker mendaftarkan worker yang dijalankan bersama server.
+func (manager *Manager) AddWorker(worker Worker) {
+	manager.workers = append(manager.workers, worker)
+}
+
+// OnShutdown mendaftarkan hook yang dijalankan setelah server dan worker
+// berhenti, sesuai urutan pendaftaran.
+func (manager *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	manager.hooks = append(manager.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// OnClose mendaftarkan hook yang menutup sumber daya yang dipakai worker,
+// misalnya pool database. Hook dilewati jika masih ada worker yang berjalan
+// setelah batas waktu drain agar transaksi worker tidak kehilangan koneksinya.
+func (manager *Manager) OnClose(name string, fn func(ctx context.Context) error) {
+	manager.hooks = append(manager.hooks, shutdownHook{name: name, fn: fn, idle: true})
+}
+
+// Workers mengembalikan salinan status seluruh worker.
+func (manager *Manager) Workers() []WorkerStatus {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	statuses := make([]WorkerStatus, 0, len(manager.workers))
+	for _, worker := range manager.workers {
+		if status, ok := manager.statuses[worker.Name()]; ok {
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses
+}
+
+// Run membuka listener pada address, menjalankan server dan worker, lalu
+// menunggu sinyal berhenti. Error dikembalikan jika server gagal dijalankan
+// atau proses shutdown tidak selesai dengan bersih.
+func (manager *Manager) Run(route *echo.Echo, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("gagal membuka listener %s: %w", address, err)
+	}
+	route.Listener = listener
+	manager.logger.Info("Server berjalan", "address", listener.Addr().String())
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	var workers sync.WaitGroup
+	for _, worker := range manager.workers {
+		workers.Add(1)
+		go manager.runWorker(workerCtx, worker, &workers)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- route.Start(address)
+	}()
+
+	var runErr error
+	select {
+	case <-signalCtx.Done():
+		manager.logger.Info("Sinyal berhenti diterima, menunggu request selesai", "timeout", manager.drainTimeout.String())
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			runErr = fmt.Errorf("server berhenti, error: %w", err)
+			manager.logger.Error("Server berhenti", logging.Error(err))
+		}
+	}
+	stop()
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), manager.drainTimeout)
+	defer cancelDrain()
+
+	if err := route.Shutdown(drainCtx); err != nil {
+		manager.logger.Error("Gagal menunggu request selesai", logging.Error(err))
+		runErr = errors.Join(runErr, err)
+	}
+
+	cancelWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	idle := true
+	select {
+	case <-done:
+	case <-drainCtx.Done():
+		idle = false
+		manager.logger.Error("Worker tidak berhenti sebelum batas waktu", "timeout", manager.drainTimeout.String(), "workers", manager.running())
+		runErr = errors.Join(runErr, drainCtx.Err())
+	}
+
+	for _, hook := range manager.hooks {
+		if hook.idle && !idle {
+			manager.logger.Warn("Shutdown dilewati karena worker masih berjalan", "hook", hook.name)
+			continue
+		}
+		if err := manager.runHook(hook); err != nil {
+			manager.logger.Error("Gagal menjalankan shutdown", "hook", hook.name, logging.Error(err))
+			runErr = errors.Join(runErr, err)
+			continue
+		}
+		manager.logger.Info("Shutdown selesai", "hook", hook.name)
+	}
+
+	if runErr == nil {
+		manager.logger.Info("Server berhenti dengan bersih")
+	}
+	return runErr
+}
+
+// runHook menjalankan hook dengan batas waktu sendiri.
+func (manager *Manager) runHook(hook shutdownHook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), manager.drainTimeout)
+	defer cancel()
+	return hook.fn(ctx)
+}
+
+// running mengembalikan nama worker yang belum berhenti.
+func (manager *Manager) running() []string {
+	var names []string
+	for _, status := range manager.Workers() {
+		if status.Alive {
+			names = append(names, status.Name)
+		}
+	}
+	return names
+}
+
+func (manager *Manager) runWorker(ctx context.Context, worker Worker, workers *sync.WaitGroup) {
+	defer workers.Done()
+
+	manager.mutex.Lock()
+	manager.statuses[worker.Name()] = &WorkerStatus{Name: worker.Name(), Alive: true, StartedAt: time.Now()}
+	manager.mutex.Unlock()
+
+	err := worker.Run(ctx)
+
+	stopped := time.Now()
+	manager.mutex.Lock()
+	status := manager.statuses[worker.Name()]
+	status.Alive = false
+	status.StoppedAt = &stopped
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	manager.mutex.Unlock()
+
+	if err != nil {
+		manager.logger.Error("Worker berhenti", "worker", worker.Name(), logging.Error(err))
+	} else {
+		manager.logger.Info("Worker berhenti", "worker", worker.Name())
+	}
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// gormAdapter meneruskan log GORM ke slog. Query yang gagal dicatat sebagai
+// error, query yang melebihi slowThreshold sebagai warning dan query lainnya
+// sebagai debug.
+type gormAdapter struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+}
+
+// NewGormLogger membuat logger GORM dari logger slog.
+func NewGormLogger(logger *slog.Logger, slowThreshold time.Duration) gormLogger.Interface {
+	return gormAdapter{logger: logger, slowThreshold: slowThreshold}
+}
+
+// LogMode diabaikan karena level diatur oleh logger slog.
+func (adapter gormAdapter) LogMode(gormLogger.LogLevel) gormLogger.Interface {
+	return adapter
+}
+
+func (adapter gormAdapter) Info(ctx context.Context, msg string, data ...interface{}) {
+	adapter.logger.InfoContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (adapter gormAdapter) Warn(ctx context.Context, msg string, data ...interface{}) {
+	adapter.logger.WarnContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (adapter gormAdapter) Error(ctx context.Context, msg string, data ...interface{}) {
+	adapter.logger.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (adapter gormAdapter) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	duration := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		adapter.logger.ErrorContext(ctx, "Query database gagal", "sql", sql, "rows", rows, Duration(duration), Error(err))
+	case adapter.slowThreshold > 0 && duration > adapter.slowThreshold:
+		sql, rows := fc()
+		adapter.logger.WarnContext(ctx, "Query database lambat", "sql", sql, "rows", rows, Duration(duration))
+	case adapter.logger.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		adapter.logger.DebugContext(ctx, "Query database", "sql", sql, "rows", rows, Duration(duration))
+	}
+}
+
+// Session mengembalikan koneksi database yang mencatat query memakai logger.
+func Session(db *gorm.DB, logger *slog.Logger) *gorm.DB {
+	return db.Session(&gorm.Session{Logger: NewGormLogger(logger, SlowQueryThreshold)})
+}
+
+// SlowQueryThreshold adalah batas durasi query yang dicatat sebagai query lambat.
+var SlowQueryThreshold = 200 * time.Millisecond
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey struct{}
+
+// WithRequestID menyimpan request ID ke context agar ikut tercatat di setiap log.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// RequestID mengembalikan request ID dari context, kosong jika tidak ada.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// contextHandler menambahkan request_id, trace_id dan span_id dari context ke
+// setiap record log.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (handler contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+
+	// log error menandai span yang sedang aktif sebagai gagal
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.SpanContext().TraceID().String()),
+			slog.String("span_id", span.SpanContext().SpanID().String()),
+		)
+		if record.Level >= slog.LevelError {
+			span.SetStatus(codes.Error, record.Message)
+		}
+	}
+	return handler.Handler.Handle(ctx, record)
+}
+
+func (handler contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{handler.Handler.WithAttrs(attrs)}
+}
+
+func (handler contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{handler.Handler.WithGroup(name)}
+}
+
+// ParseLevel membaca level log: debug, info, warn atau error. Default info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// New membuat logger dengan format json atau text.
+func New(w io.Writer, level string, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: ParseLevel(level)}
+
+	var handler slog.Handler
+	if strings.ToLower(format) == "text" {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// FromEnv membuat logger berdasarkan LOG_LEVEL dan LOG_FORMAT.
+func FromEnv() *slog.Logger {
+	return New(os.Stdout, os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
+}
+
+// Error adalah atribut error yang seragam untuk semua log.
+func Error(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+// Duration adalah atribut durasi yang seragam untuk semua log, dalam milidetik.
+func Duration(duration time.Duration) slog.Attr {
+	return slog.Float64("duration_ms", float64(duration.Microseconds())/1000)
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startKey = "metrics:start"
+
+type queryKey struct{}
+
+// query adalah label repository dan method untuk metrik query database.
+type query struct {
+	repository string
+	method     string
+}
+
+// WithQuery menandai ctx dengan nama repository dan method yang menjalankan
+// query, contoh WithQuery(ctx, "bmp", "GetAll"). Repository memakai ctx ini
+// pada WithContext sehingga plugin tidak perlu membaca call stack.
+func WithQuery(ctx context.Context, repository string, method string) context.Context {
+	return context.WithValue(ctx, queryKey{}, query{repository: repository, method: method})
+}
+
+// queryPlugin mencatat durasi setiap query GORM dengan label dari WithQuery.
+// Query tanpa label dicatat sebagai repository "other" dan method "unknown".
+type queryPlugin struct{}
+
+func (queryPlugin) Name() string {
+	return "metrics"
+}
+
+func (plugin queryPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registers := []error{
+		callback.Create().Before("gorm:create").Register("metrics:before_create", plugin.before),
+		callback.Create().After("gorm:create").Register("metrics:after_create", plugin.after("create")),
+		callback.Query().Before("gorm:query").Register("metrics:before_query", plugin.before),
+		callback.Query().After("gorm:query").Register("metrics:after_query", plugin.after("query")),
+		callback.Update().Before("gorm:update").Register("metrics:before_update", plugin.before),
+		callback.Update().After("gorm:update").Register("metrics:after_update", plugin.after("update")),
+		callback.Delete().Before("gorm:delete").Register("metrics:before_delete", plugin.before),
+		callback.Delete().After("gorm:delete").Register("metrics:after_delete", plugin.after("delete")),
+		callback.Row().Before("gorm:row").Register("metrics:before_row", plugin.before),
+		callback.Row().After("gorm:row").Register("metrics:after_row", plugin.after("row")),
+		callback.Raw().Before("gorm:raw").Register("metrics:before_raw", plugin.before),
+		callback.Raw().After("gorm:raw").Register("metrics:after_raw", plugin.after("raw")),
+	}
+
+	for _, err := range registers {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (queryPlugin) before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func (queryPlugin) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		// subquery dibangun dengan DryRun dan tidak dikirim ke database
+		if db.DryRun {
+			return
+		}
+
+		value, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		started, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		label := query{repository: "other", method: "unknown"}
+		if db.Statement.Context != nil {
+			if value, ok := db.Statement.Context.Value(queryKey{}).(query); ok {
+				label = value
+			}
+		}
+		DBQueryDuration.WithLabelValues(label.repository, label.method, operation).Observe(time.Since(started).Seconds())
+	}
+}
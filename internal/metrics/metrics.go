@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const namespace = "iot"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Jumlah request HTTP per route, method dan status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Durasi request HTTP per route dan method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	ReadingsIngested = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readings_ingested_total",
+		Help:      "Jumlah data sensor yang berhasil disimpan per jenis sensor dan device.",
+	}, []string{"sensor", "device_token"})
+
+	ValidationRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validation_rejections_total",
+		Help:      "Jumlah field yang ditolak validasi per jenis sensor dan field.",
+	}, []string{"sensor", "field"})
+
+	AnomaliesDetected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "anomalies_detected_total",
+		Help:      "Jumlah event anomali per jenis sensor dan field.",
+	}, []string{"sensor", "field"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Durasi query database per repository dan method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"repository", "method", "operation"})
+)
+
+// RecordIngested menambah jumlah data sensor yang berhasil disimpan.
+func RecordIngested(sensor string, deviceToken string, count int) {
+	ReadingsIngested.WithLabelValues(sensor, deviceToken).Add(float64(count))
+}
+
+// RecordValidationRejections mencatat setiap field pada daftar error validasi.
+func RecordValidationRejections(sensor string, errorList map[string]string) {
+	for field := range errorList {
+		ValidationRejections.WithLabelValues(sensor, field).Inc()
+	}
+}
+
+// RecordAnomaly menambah jumlah event anomali untuk satu field sensor.
+func RecordAnomaly(sensor string, field string) {
+	AnomaliesDetected.WithLabelValues(sensor, field).Inc()
+}
+
+// RegisterDB memasang pencatat durasi query dan statistik pool koneksi database.
+func RegisterDB(db *gorm.DB, name string) error {
+	if err := db.Use(queryPlugin{}); err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
+
+// Handler mengembalikan handler HTTP untuk endpoint /metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorHandler memetakan error dari controller ke response JSON yang seragam.
+// Request dengan path berawalan salah satu objectPrefixes, misalnya route API
+// v2, menerima error sebagai helpers.ErrorResponse untuk semua jenis error.
+func ErrorHandler(logger *slog.Logger, objectPrefixes ...string) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		for _, prefix := range objectPrefixes {
+			if strings.HasPrefix(c.Request().URL.Path, prefix) {
+				writeError(logger, c, err, func(object helpers.ErrorObject, validation bool) interface{} {
+					return helpers.ErrorResponse{Error: object}
+				})
+				return
+			}
+		}
+
+		writeError(logger, c, err, func(object helpers.ErrorObject, validation bool) interface{} {
+			if validation {
+				return helpers.ValidationResponse{Status: object.Status, Code: object.Code, Messages: object.Message, Errors: object.Details}
+			}
+			return helpers.Response{Status: object.Status, Code: object.Code, Messages: object.Message, Data: object.Details}
+		})
+	}
+}
+
+// writeError mengirim error sebagai JSON dengan bentuk body dari fungsi body.
+func writeError(logger *slog.Logger, c echo.Context, err error, body func(object helpers.ErrorObject, validation bool) interface{}) {
+	if c.Response().Committed {
+		logger.ErrorContext(c.Request().Context(), "Error setelah response dikirim", logging.Error(err))
+		return
+	}
+
+	object, validation := errorObject(err, i18n.Language(c.Request().Context()))
+	if object.Status >= http.StatusInternalServerError {
+		logger.ErrorContext(c.Request().Context(), "Request gagal", "status", object.Status, logging.Error(err))
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(object.Status)
+	} else {
+		err = c.JSON(object.Status, body(object, validation))
+	}
+	if err != nil {
+		logger.ErrorContext(c.Request().Context(), "Gagal mengirim response error", logging.Error(err))
+	}
+}
+
+// errorObject memetakan error ke status, kode, pesan dan detail error serta
+// menandai apakah error berasal dari validasi.
+func errorObject(err error, lang string) (helpers.ErrorObject, bool) {
+	var appError *helpers.Error
+	var httpError *echo.HTTPError
+	if errors.As(err, &appError) {
+		object := helpers.ErrorObject{Status: appError.Status(), Code: appError.Message.Code, Message: appError.Message.Translate(lang), Details: appError.Details}
+		return object, appError.Kind == helpers.KindValidation
+	}
+	if errors.As(err, &httpError) {
+		return statusObject(httpError.Code, lang, fmt.Sprint(httpError.Message)), false
+	}
+
+	status := helpers.ErrorStatus(err)
+	return statusObject(status, lang, http.StatusText(status)), false
+}
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            i18n.HTTPBadRequest,
+	http.StatusNotFound:              i18n.HTTPNotFound,
+	http.StatusMethodNotAllowed:      i18n.HTTPMethodNotAllowed,
+	http.StatusRequestEntityTooLarge: i18n.HTTPEntityTooLarge,
+	http.StatusUnsupportedMediaType:  i18n.HTTPUnsupportedMedia,
+	http.StatusTooManyRequests:       i18n.HTTPTooManyRequests,
+	http.StatusInternalServerError:   i18n.HTTPInternalError,
+	http.StatusServiceUnavailable:    i18n.HTTPServiceUnavailable,
+	http.StatusGatewayTimeout:        i18n.HTTPGatewayTimeout,
+}
+
+// statusObject menyusun error di luar service berdasarkan status HTTP. Status
+// tanpa kode katalog memakai pesan bawaan error.
+func statusObject(status int, lang string, fallback string) helpers.ErrorObject {
+	code, ok := statusCodes[status]
+	if !ok {
+		return helpers.ErrorObject{Status: status, Message: fallback}
+	}
+	return helpers.ErrorObject{Status: status, Code: code, Message: i18n.Msg(code).Translate(lang)}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"iot-golang/internal/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	acceptLanguageHeader  = "Accept-Language"
+	contentLanguageHeader = "Content-Language"
+)
+
+// Language memilih bahasa response dari parameter query lang atau header
+// Accept-Language, lalu menyimpannya ke context request.
+func Language() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			lang := i18n.Match(c.QueryParam("lang"), c.Request().Header.Get(acceptLanguageHeader))
+
+			c.Response().Header().Set(contentLanguageHeader, lang)
+			c.Response().Header().Add(echo.HeaderVary, acceptLanguageHeader)
+			c.SetRequest(c.Request().WithContext(i18n.WithLanguage(c.Request().Context(), lang)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/metrics"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics mencatat jumlah dan durasi request HTTP per route.
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			started := time.Now()
+			err := next(c)
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			status := c.Response().Status
+			var appError *helpers.Error
+			var httpError *echo.HTTPError
+			if errors.As(err, &appError) {
+				status = appError.Status()
+			} else if errors.As(err, &httpError) {
+				status = httpError.Code
+			} else if err != nil {
+				status = http.StatusInternalServerError
+			}
+
+			metrics.HTTPRequests.WithLabelValues(c.Request().Method, route, strconv.Itoa(status)).Inc()
+			metrics.HTTPDuration.WithLabelValues(c.Request().Method, route).Observe(time.Since(started).Seconds())
+			return err
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"iot-golang/internal/logging"
+	"log/slog"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// NewRequestID membuat request ID acak dalam bentuk hex.
+func NewRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// RequestID memakai header X-Request-ID dari client atau membuat ID baru,
+// mengirimkannya kembali di response dan menyimpannya ke context request.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(requestIDHeader)
+			if requestID == "" || len(requestID) > 128 {
+				requestID = NewRequestID()
+			}
+
+			c.Response().Header().Set(requestIDHeader, requestID)
+			c.SetRequest(c.Request().WithContext(logging.WithRequestID(c.Request().Context(), requestID)))
+			return next(c)
+		}
+	}
+}
+
+// AccessLog mencatat setiap request HTTP beserta status dan durasinya.
+func AccessLog(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			started := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			} else if status >= 400 {
+				level = slog.LevelWarn
+			}
+
+			logger.Log(c.Request().Context(), level, "Request HTTP",
+				"method", c.Request().Method,
+				"route", c.Path(),
+				"path", c.Request().URL.Path,
+				"status", status,
+				logging.Duration(time.Since(started)),
+				"remote_ip", c.RealIP(),
+			)
+			return nil
+		}
+	}
+}
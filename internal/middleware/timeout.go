@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RouteTimeout memberi batas waktu pada context request sehingga query GORM
+// yang memakai context tersebut dibatalkan saat melewati batas. Route yang
+// tidak terdaftar di overrides memakai timeout default.
+func RouteTimeout(defaultTimeout time.Duration, overrides map[string]time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			timeout := defaultTimeout
+			if override, ok := overrides[c.Path()]; ok {
+				timeout = override
+			}
+			if timeout <= 0 {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
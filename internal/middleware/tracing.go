@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"iot-golang/internal/tracing"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+const traceIDHeader = "X-Trace-ID"
+
+// Tracing membuat span untuk setiap request dan mengirim trace ID di header
+// response agar client bisa mencocokkan request dengan trace dan log.
+func Tracing(serviceName string) []echo.MiddlewareFunc {
+	traceID := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if id := tracing.TraceID(c.Request().Context()); id != "" {
+				c.Response().Header().Set(traceIDHeader, id)
+			}
+			return next(c)
+		}
+	}
+
+	return []echo.MiddlewareFunc{otelecho.Middleware(serviceName), traceID}
+}
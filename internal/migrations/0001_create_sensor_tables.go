@@ -0,0 +1,138 @@
+package migrations
+
+import (
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// Snapshot struktur tabel sensor saat migration ini dibuat. Jangan memakai
+// model dari package sensor karena model tersebut akan terus berubah.
+
+type sensorBeitianV1 struct {
+	Id          int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken string `gorm:"column:device_token;size:255"`
+	Latitude    string `gorm:"column:latitude;size:32"`
+	Longitude   string `gorm:"column:longitude;size:32"`
+	Battery     string `gorm:"column:battery;size:32"`
+}
+
+func (sensorBeitianV1) TableName() string { return "db_sensor_beitian220" }
+
+type sensorBmpV1 struct {
+	Id              int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken     string `gorm:"column:device_token;size:255"`
+	TekananUdara    string `gorm:"column:tekanan_udara;size:32"`
+	TinggiPermukaan string `gorm:"column:tinggi_permukaan;size:32"`
+	Battery         string `gorm:"column:battery;size:32"`
+}
+
+func (sensorBmpV1) TableName() string { return "db_sensor_bmp180" }
+
+type sensorInaV1 struct {
+	Id          int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken string `gorm:"column:device_token;size:255"`
+	Tegangan    string `gorm:"column:tegangan;size:32"`
+	Arus        string `gorm:"column:arus;size:32"`
+	Daya        string `gorm:"column:daya;size:32"`
+}
+
+func (sensorInaV1) TableName() string { return "db_sensor_ina219" }
+
+type sensorPzemV1 struct {
+	Id          int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken string `gorm:"column:device_token;size:255"`
+	Tegangan    string `gorm:"column:tegangan;size:32"`
+	Arus        string `gorm:"column:arus;size:32"`
+	Daya        string `gorm:"column:daya;size:32"`
+}
+
+func (sensorPzemV1) TableName() string { return "db_sensor_pzem" }
+
+type sensorThigrowV1 struct {
+	Id                int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken       string `gorm:"column:device_token;size:255"`
+	KelembabanTanahTh int32  `gorm:"column:kelembaban_tanah_th"`
+	KelembabanTanahSm int32  `gorm:"column:kelembaban_tanah_sm"`
+	KelembabanUdara   int32  `gorm:"column:kelembaban_udara"`
+	IntensitasCahaya  string `gorm:"column:i_cahaya;size:32"`
+	Battery           string `gorm:"column:battery;size:32"`
+	Temperature       string `gorm:"column:temperature;size:32"`
+	KadarGaram        string `gorm:"column:kadar_garam;size:32"`
+}
+
+func (sensorThigrowV1) TableName() string { return "db_sensor_thigrow" }
+
+type sensorThmV1 struct {
+	Id              int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken     string `gorm:"column:device_token;size:255"`
+	Temperature     string `gorm:"column:temperature;size:32"`
+	KelembabanUdara string `gorm:"column:kelembaban_udara;size:32"`
+	Battery         string `gorm:"column:battery;size:32"`
+}
+
+func (sensorThmV1) TableName() string { return "db_sensor_thm30d" }
+
+func sensorTablesV1() []interface{} {
+	return []interface{}{&sensorBeitianV1{}, &sensorBmpV1{}, &sensorInaV1{}, &sensorPzemV1{}, &sensorThigrowV1{}, &sensorThmV1{}}
+}
+
+// adoptedTableV1 mencatat tabel sensor yang sudah ada sebelum migration
+// berversi dipakai. Tabel tersebut hanya diadopsi oleh Up dan tidak dihapus
+// oleh Down karena berisi data yang tidak dibuat oleh migration ini.
+type adoptedTableV1 struct {
+	Name string `gorm:"column:name;primaryKey;size:64"`
+}
+
+func (adoptedTableV1) TableName() string { return "schema_adopted_tables" }
+
+func tableNameV1(model interface{}) string {
+	return model.(interface{ TableName() string }).TableName()
+}
+
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "create_sensor_tables",
+		Up: func(tx *gorm.DB) error {
+			if err := createTableIfMissing(tx, &adoptedTableV1{}); err != nil {
+				return err
+			}
+			for _, table := range sensorTablesV1() {
+				if !tx.Migrator().HasTable(table) {
+					if err := tx.Migrator().CreateTable(table); err != nil {
+						return err
+					}
+					continue
+				}
+
+				if err := tx.Save(&adoptedTableV1{Name: tableNameV1(table)}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			// Database yang dimigrasi sebelum tabel adopsi ada tidak diketahui
+			// asal tabel sensornya, sehingga rollback ditolak
+			if !tx.Migrator().HasTable(&adoptedTableV1{}) {
+				return errors.New("tabel sensor mungkin berisi data dari sebelum migration berversi, hapus tabel secara manual bila memang diperlukan")
+			}
+
+			var adopted []string
+			if err := tx.Model(&adoptedTableV1{}).Pluck("name", &adopted).Error; err != nil {
+				return err
+			}
+			for _, table := range sensorTablesV1() {
+				if slices.Contains(adopted, tableNameV1(table)) {
+					continue
+				}
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&adoptedTableV1{})
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sensorCreatedAtV2 dipakai dengan tx.Table() untuk menambah kolom created_at
+// pada setiap tabel sensor. Data lama dibiarkan NULL karena waktu aslinya tidak diketahui.
+type sensorCreatedAtV2 struct {
+	CreatedAt *time.Time `gorm:"column:created_at"`
+}
+
+var sensorTableNames = []string{
+	"db_sensor_beitian220",
+	"db_sensor_bmp180",
+	"db_sensor_ina219",
+	"db_sensor_pzem",
+	"db_sensor_thigrow",
+	"db_sensor_thm30d",
+}
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "add_sensor_created_at",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range sensorTableNames {
+				migrator := tx.Table(table).Migrator()
+				if migrator.HasColumn(&sensorCreatedAtV2{}, "created_at") {
+					continue
+				}
+				if err := migrator.AddColumn(&sensorCreatedAtV2{}, "CreatedAt"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range sensorTableNames {
+				migrator := tx.Table(table).Migrator()
+				if !migrator.HasColumn(&sensorCreatedAtV2{}, "created_at") {
+					continue
+				}
+				if err := migrator.DropColumn(&sensorCreatedAtV2{}, "created_at"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
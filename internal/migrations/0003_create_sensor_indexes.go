@@ -0,0 +1,32 @@
+package migrations
+
+import "gorm.io/gorm"
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "create_sensor_indexes",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range sensorTableNames {
+				if err := createIndexIfMissing(tx, table, "idx_"+table+"_device_token_created_at", "device_token, created_at"); err != nil {
+					return err
+				}
+				if err := createIndexIfMissing(tx, table, "idx_"+table+"_created_at", "created_at"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range sensorTableNames {
+				if err := dropIndexIfExists(tx, table, "idx_"+table+"_device_token_created_at"); err != nil {
+					return err
+				}
+				if err := dropIndexIfExists(tx, table, "idx_"+table+"_created_at"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type deviceV4 struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken string    `gorm:"column:device_token;size:255;uniqueIndex:idx_db_device_device_token"`
+	Name        string    `gorm:"column:name;size:255"`
+	Farm        string    `gorm:"column:farm;size:255"`
+	Latitude    string    `gorm:"column:latitude;size:32"`
+	Longitude   string    `gorm:"column:longitude;size:32"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (deviceV4) TableName() string { return "db_device" }
+
+type geofenceV4 struct {
+	Id        int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Farm      string    `gorm:"column:farm;size:255;index:idx_db_geofence_farm"`
+	Name      string    `gorm:"column:name;size:255"`
+	Polygon   string    `gorm:"column:polygon;type:text"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (geofenceV4) TableName() string { return "db_geofence" }
+
+type geofenceEventV4 struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	GeofenceId  int64     `gorm:"column:geofence_id"`
+	DeviceToken string    `gorm:"column:device_token;size:255;index:idx_db_geofence_event_device_token_created_at,priority:1"`
+	Event       string    `gorm:"column:event;size:16"`
+	Latitude    string    `gorm:"column:latitude;size:32"`
+	Longitude   string    `gorm:"column:longitude;size:32"`
+	CreatedAt   time.Time `gorm:"column:created_at;index:idx_db_geofence_event_device_token_created_at,priority:2"`
+}
+
+func (geofenceEventV4) TableName() string { return "db_geofence_event" }
+
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "create_device_geofence_tables",
+		Up: func(tx *gorm.DB) error {
+			return createTableIfMissing(tx, &deviceV4{}, &geofenceV4{}, &geofenceEventV4{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&geofenceEventV4{}, &geofenceV4{}, &deviceV4{})
+		},
+	})
+}
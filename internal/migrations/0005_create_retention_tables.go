@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type retentionPolicyV5 struct {
+	Id           int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor       string    `gorm:"column:sensor;size:32;uniqueIndex:idx_db_retention_policy_sensor"`
+	RawDays      int       `gorm:"column:raw_days"`
+	HourlyMonths int       `gorm:"column:hourly_months"`
+	DailyMonths  int       `gorm:"column:daily_months"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+}
+
+func (retentionPolicyV5) TableName() string { return "db_retention_policy" }
+
+type sensorRollupV5 struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor      string    `gorm:"column:sensor;size:32;uniqueIndex:idx_db_sensor_rollup_bucket,priority:1"`
+	DeviceToken string    `gorm:"column:device_token;size:255;uniqueIndex:idx_db_sensor_rollup_bucket,priority:2"`
+	Field       string    `gorm:"column:field;size:64;uniqueIndex:idx_db_sensor_rollup_bucket,priority:3"`
+	Resolution  string    `gorm:"column:resolution;size:8;uniqueIndex:idx_db_sensor_rollup_bucket,priority:4"`
+	BucketStart time.Time `gorm:"column:bucket_start;uniqueIndex:idx_db_sensor_rollup_bucket,priority:5"`
+	Count       int64     `gorm:"column:count"`
+	MinValue    float64   `gorm:"column:min_value"`
+	MaxValue    float64   `gorm:"column:max_value"`
+	AvgValue    float64   `gorm:"column:avg_value"`
+}
+
+func (sensorRollupV5) TableName() string { return "db_sensor_rollup" }
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "create_retention_tables",
+		Up: func(tx *gorm.DB) error {
+			return createTableIfMissing(tx, &retentionPolicyV5{}, &sensorRollupV5{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&sensorRollupV5{}, &retentionPolicyV5{})
+		},
+	})
+}
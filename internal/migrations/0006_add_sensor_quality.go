@@ -0,0 +1,45 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// sensorQualityV6 dipakai dengan tx.Table() untuk menambah kolom quality pada
+// setiap tabel sensor. Data lama dianggap good karena belum pernah dinilai.
+type sensorQualityV6 struct {
+	Quality string `gorm:"column:quality;size:8;not null;default:good"`
+}
+
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "add_sensor_quality",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range sensorTableNames {
+				migrator := tx.Table(table).Migrator()
+				if !migrator.HasColumn(&sensorQualityV6{}, "quality") {
+					if err := migrator.AddColumn(&sensorQualityV6{}, "Quality"); err != nil {
+						return err
+					}
+				}
+				if err := createIndexIfMissing(tx, table, "idx_"+table+"_quality", "quality"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range sensorTableNames {
+				if err := dropIndexIfExists(tx, table, "idx_"+table+"_quality"); err != nil {
+					return err
+				}
+				migrator := tx.Table(table).Migrator()
+				if !migrator.HasColumn(&sensorQualityV6{}, "quality") {
+					continue
+				}
+				if err := migrator.DropColumn(&sensorQualityV6{}, "quality"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
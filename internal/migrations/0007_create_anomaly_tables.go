@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sensorAnomalyScoreV7 dipakai dengan tx.Table() untuk menambah kolom
+// anomaly_score pada setiap tabel sensor. Data lama bernilai 0.
+type sensorAnomalyScoreV7 struct {
+	AnomalyScore float64 `gorm:"column:anomaly_score;not null;default:0"`
+}
+
+type anomalyEventV7 struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor      string    `gorm:"column:sensor;size:32"`
+	DeviceToken string    `gorm:"column:device_token;size:255;index:idx_db_anomaly_event_device_token_created_at,priority:1"`
+	Field       string    `gorm:"column:field;size:64"`
+	Value       float64   `gorm:"column:value"`
+	Score       float64   `gorm:"column:score"`
+	Method      string    `gorm:"column:method;size:16"`
+	CreatedAt   time.Time `gorm:"column:created_at;index:idx_db_anomaly_event_device_token_created_at,priority:2"`
+}
+
+func (anomalyEventV7) TableName() string { return "db_anomaly_event" }
+
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "create_anomaly_tables",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range sensorTableNames {
+				migrator := tx.Table(table).Migrator()
+				if migrator.HasColumn(&sensorAnomalyScoreV7{}, "anomaly_score") {
+					continue
+				}
+				if err := migrator.AddColumn(&sensorAnomalyScoreV7{}, "AnomalyScore"); err != nil {
+					return err
+				}
+			}
+			return createTableIfMissing(tx, &anomalyEventV7{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&anomalyEventV7{}); err != nil {
+				return err
+			}
+			for _, table := range sensorTableNames {
+				migrator := tx.Table(table).Migrator()
+				if !migrator.HasColumn(&sensorAnomalyScoreV7{}, "anomaly_score") {
+					continue
+				}
+				if err := migrator.DropColumn(&sensorAnomalyScoreV7{}, "anomaly_score"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
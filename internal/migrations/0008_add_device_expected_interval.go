@@ -0,0 +1,30 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// deviceExpectedIntervalV8 menambah interval pengiriman yang diharapkan dari
+// setiap perangkat dalam detik. Nilai 0 berarti memakai interval default.
+type deviceExpectedIntervalV8 struct {
+	ExpectedInterval int64 `gorm:"column:expected_interval;not null;default:0"`
+}
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "add_device_expected_interval",
+		Up: func(tx *gorm.DB) error {
+			migrator := tx.Table("db_device").Migrator()
+			if migrator.HasColumn(&deviceExpectedIntervalV8{}, "expected_interval") {
+				return nil
+			}
+			return migrator.AddColumn(&deviceExpectedIntervalV8{}, "ExpectedInterval")
+		},
+		Down: func(tx *gorm.DB) error {
+			migrator := tx.Table("db_device").Migrator()
+			if !migrator.HasColumn(&deviceExpectedIntervalV8{}, "expected_interval") {
+				return nil
+			}
+			return migrator.DropColumn(&deviceExpectedIntervalV8{}, "expected_interval")
+		},
+	})
+}
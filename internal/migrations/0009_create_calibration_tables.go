@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sensorRawValuesV9 dipakai dengan tx.Table() untuk menambah kolom raw_values
+// pada setiap tabel sensor. Kolom berisi JSON nilai asli field yang dikalibrasi.
+type sensorRawValuesV9 struct {
+	RawValues string `gorm:"column:raw_values;type:text"`
+}
+
+type calibrationV9 struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor      string    `gorm:"column:sensor;size:32;uniqueIndex:idx_db_calibration_key,priority:1"`
+	DeviceToken string    `gorm:"column:device_token;size:255;uniqueIndex:idx_db_calibration_key,priority:2"`
+	Field       string    `gorm:"column:field;size:64;uniqueIndex:idx_db_calibration_key,priority:3"`
+	Offset      float64   `gorm:"column:offset;not null;default:0"`
+	Gain        float64   `gorm:"column:gain;not null;default:1"`
+	ValidFrom   time.Time `gorm:"column:valid_from;uniqueIndex:idx_db_calibration_key,priority:4"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+func (calibrationV9) TableName() string { return "db_calibration" }
+
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "create_calibration_tables",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range sensorTableNames {
+				migrator := tx.Table(table).Migrator()
+				if migrator.HasColumn(&sensorRawValuesV9{}, "raw_values") {
+					continue
+				}
+				if err := migrator.AddColumn(&sensorRawValuesV9{}, "RawValues"); err != nil {
+					return err
+				}
+			}
+			return createTableIfMissing(tx, &calibrationV9{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&calibrationV9{}); err != nil {
+				return err
+			}
+			for _, table := range sensorTableNames {
+				migrator := tx.Table(table).Migrator()
+				if !migrator.HasColumn(&sensorRawValuesV9{}, "raw_values") {
+					continue
+				}
+				if err := migrator.DropColumn(&sensorRawValuesV9{}, "raw_values"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
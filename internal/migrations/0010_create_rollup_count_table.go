@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// rollupCountV10 menyimpan jumlah data mentah yang diterima per bucket,
+// termasuk data bad yang tidak ikut dihitung pada agregat rollup.
+type rollupCountV10 struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor      string    `gorm:"column:sensor;size:32;uniqueIndex:idx_db_sensor_rollup_count_bucket,priority:1"`
+	DeviceToken string    `gorm:"column:device_token;size:255;uniqueIndex:idx_db_sensor_rollup_count_bucket,priority:2"`
+	Resolution  string    `gorm:"column:resolution;size:8;uniqueIndex:idx_db_sensor_rollup_count_bucket,priority:3"`
+	BucketStart time.Time `gorm:"column:bucket_start;uniqueIndex:idx_db_sensor_rollup_count_bucket,priority:4"`
+	Count       int64     `gorm:"column:count"`
+}
+
+func (rollupCountV10) TableName() string { return "db_sensor_rollup_count" }
+
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "create_rollup_count_table",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&rollupCountV10{}) {
+				return nil
+			}
+			if err := tx.Migrator().CreateTable(&rollupCountV10{}); err != nil {
+				return err
+			}
+
+			// Bucket yang sudah di-rollup hanya memiliki count data yang bukan bad
+			return tx.Exec(`INSERT INTO db_sensor_rollup_count (sensor, device_token, resolution, bucket_start, count)
+				SELECT sensor, device_token, resolution, bucket_start, MAX(count) FROM db_sensor_rollup
+				GROUP BY sensor, device_token, resolution, bucket_start`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&rollupCountV10{})
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sensorQuarantineV11 menyimpan data sensor tanpa created_at yang dipindahkan
+// oleh job retensi. Data tersebut tidak dapat di-rollup maupun dihapus
+// berdasarkan umur sehingga disimpan utuh dalam bentuk JSON.
+type sensorQuarantineV11 struct {
+	Id            int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor        string    `gorm:"column:sensor;size:32;index:idx_db_sensor_quarantine_sensor"`
+	SourceId      int64     `gorm:"column:source_id"`
+	Data          string    `gorm:"column:data"`
+	QuarantinedAt time.Time `gorm:"column:quarantined_at"`
+}
+
+func (sensorQuarantineV11) TableName() string { return "db_sensor_quarantine" }
+
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "create_sensor_quarantine_table",
+		Up: func(tx *gorm.DB) error {
+			return createTableIfMissing(tx, &sensorQuarantineV11{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&sensorQuarantineV11{})
+		},
+	})
+}
@@ -0,0 +1,211 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration adalah satu versi perubahan skema database beserta langkah rollback-nya.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration mencatat versi migration yang sudah dijalankan.
+type SchemaMigration struct {
+	Version   int64     `json:"version" gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `json:"name" gorm:"column:name;size:255"`
+	AppliedAt time.Time `json:"applied_at" gorm:"column:applied_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+var registry []Migration
+
+// register dipanggil dari init() pada setiap file migration.
+func register(migration Migration) {
+	registry = append(registry, migration)
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Version < registry[j].Version })
+}
+
+// All mengembalikan seluruh migration yang terdaftar, terurut berdasarkan versi.
+func All() []Migration {
+	return registry
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	logger     *slog.Logger
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB, logger *slog.Logger) *Migrator {
+	return &Migrator{db: db, logger: logger.With("component", "migration"), migrations: All()}
+}
+
+func (migrator *Migrator) ensureTable() error {
+	if migrator.db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil
+	}
+	return migrator.db.Migrator().CreateTable(&SchemaMigration{})
+}
+
+// applied membaca versi yang sudah dijalankan tanpa mengubah skema. Tabel
+// schema_migrations yang belum ada berarti belum ada migration yang dijalankan.
+func (migrator *Migrator) applied(ctx context.Context) (map[int64]SchemaMigration, error) {
+	db := migrator.db.WithContext(ctx)
+	applied := make(map[int64]SchemaMigration)
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+
+	var rows []SchemaMigration
+	if err := db.Order("version asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Pending mengembalikan migration yang belum dijalankan. Pending hanya membaca
+// database sehingga aman dipanggil dari readiness probe.
+func (migrator *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range migrator.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up menjalankan seluruh migration yang belum dijalankan secara berurutan.
+func (migrator *Migrator) Up() ([]Migration, error) {
+	if err := migrator.ensureTable(); err != nil {
+		return nil, err
+	}
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		err := migrator.db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s gagal: %w", migration.Version, migration.Name, err)
+		}
+
+		migrator.logger.Info("Migration berhasil dijalankan", "version", migration.Version, "name", migration.Name)
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down melakukan rollback sejumlah steps migration terakhir.
+func (migrator *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := migrator.applied(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrator.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrator.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := migrator.db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{Version: migration.Version}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback migration %d_%s gagal: %w", migration.Version, migration.Name, err)
+		}
+
+		migrator.logger.Info("Rollback migration berhasil", "version", migration.Version, "name", migration.Name)
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Status mengembalikan status setiap migration yang terdaftar.
+func (migrator *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var status []MigrationStatus
+	for _, migration := range migrator.migrations {
+		item := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			item.Applied = true
+			item.AppliedAt = &row.AppliedAt
+		}
+		status = append(status, item)
+	}
+	return status, nil
+}
+
+// createTableIfMissing membuat tabel hanya jika belum ada, karena tabel sensor
+// lama sudah dibuat manual sebelum ada migration.
+func createTableIfMissing(tx *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		if tx.Migrator().HasTable(model) {
+			continue
+		}
+		if err := tx.Migrator().CreateTable(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createIndexIfMissing(tx *gorm.DB, table string, name string, columns string) error {
+	if tx.Migrator().HasIndex(table, name) {
+		return nil
+	}
+	return tx.Exec(fmt.Sprintf("CREATE INDEX %s ON %s (%s)", name, table, columns)).Error
+}
+
+func dropIndexIfExists(tx *gorm.DB, table string, name string) error {
+	if !tx.Migrator().HasIndex(table, name) {
+		return nil
+	}
+	return tx.Migrator().DropIndex(table, name)
+}
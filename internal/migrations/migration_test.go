@@ -0,0 +1,224 @@
+package migrations
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB membuka database SQLite di memori. Pool dibatasi satu koneksi
+// karena setiap koneksi :memory: memiliki database sendiri.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard, TranslateError: true})
+	if err != nil {
+		t.Fatalf("gagal membuka database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("gagal mengambil pool database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	return db
+}
+
+func assertApplied(t *testing.T, migrator *Migrator, applied bool) {
+	t.Helper()
+
+	status, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(status) != len(All()) {
+		t.Fatalf("Status berisi %d migration, seharusnya %d", len(status), len(All()))
+	}
+	for _, item := range status {
+		if item.Applied != applied {
+			t.Errorf("migration %d_%s applied = %v, seharusnya %v", item.Version, item.Name, item.Applied, applied)
+		}
+		if applied && item.AppliedAt == nil {
+			t.Errorf("migration %d_%s tidak memiliki applied_at", item.Version, item.Name)
+		}
+	}
+
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	want := 0
+	if !applied {
+		want = len(All())
+	}
+	if len(pending) != want {
+		t.Errorf("Pending berisi %d migration, seharusnya %d", len(pending), want)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	for i, migration := range All() {
+		if migration.Version != int64(i+1) {
+			t.Errorf("migration ke-%d memiliki versi %d, seharusnya %d", i, migration.Version, i+1)
+		}
+		if migration.Up == nil || migration.Down == nil {
+			t.Errorf("migration %d_%s tidak memiliki Up atau Down", migration.Version, migration.Name)
+		}
+	}
+	if len(All()) < 9 {
+		t.Errorf("hanya %d migration terdaftar, seharusnya minimal 9", len(All()))
+	}
+}
+
+func TestPendingReadOnly(t *testing.T) {
+	db := openTestDB(t)
+
+	pending, err := NewMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil))).Pending(context.Background())
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != len(All()) {
+		t.Errorf("Pending berisi %d migration, seharusnya %d", len(pending), len(All()))
+	}
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		t.Error("Pending membuat tabel schema_migrations")
+	}
+}
+
+func TestUpDownUp(t *testing.T) {
+	db := openTestDB(t)
+	migrator := NewMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	done, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(done) != len(All()) {
+		t.Fatalf("Up menjalankan %d migration, seharusnya %d", len(done), len(All()))
+	}
+	assertApplied(t, migrator, true)
+
+	done, err = migrator.Up()
+	if err != nil {
+		t.Fatalf("Up kedua: %v", err)
+	}
+	if len(done) != 0 {
+		t.Errorf("Up kedua menjalankan %d migration, seharusnya 0", len(done))
+	}
+
+	done, err = migrator.Down(len(All()))
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(done) != len(All()) {
+		t.Fatalf("Down melakukan rollback %d migration, seharusnya %d", len(done), len(All()))
+	}
+	for i, migration := range done {
+		if want := All()[len(All())-1-i].Version; migration.Version != want {
+			t.Errorf("rollback ke-%d adalah versi %d, seharusnya %d", i, migration.Version, want)
+		}
+	}
+	assertApplied(t, migrator, false)
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("GetTables: %v", err)
+	}
+	for _, table := range tables {
+		if table != (SchemaMigration{}).TableName() && !strings.HasPrefix(table, "sqlite_") {
+			t.Errorf("tabel %s masih ada setelah Down", table)
+		}
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up setelah Down: %v", err)
+	}
+	assertApplied(t, migrator, true)
+}
+
+func TestDownSteps(t *testing.T) {
+	migrator := NewMigrator(openTestDB(t), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	done, err := migrator.Down(2)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(done) != 2 {
+		t.Fatalf("Down melakukan rollback %d migration, seharusnya 2", len(done))
+	}
+
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 2 || pending[0].Version != done[1].Version || pending[1].Version != done[0].Version {
+		t.Errorf("Pending berisi %d migration, seharusnya dua migration terakhir", len(pending))
+	}
+}
+
+// TestDownKeepsAdoptedTables memastikan tabel sensor yang sudah ada sebelum
+// migration berversi tidak ikut dihapus saat rollback sampai versi 0.
+func TestDownKeepsAdoptedTables(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.Migrator().CreateTable(&sensorBmpV1{}); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	if err := db.Create(&sensorBmpV1{DeviceToken: "dev1", TekananUdara: "1000", TinggiPermukaan: "10", Battery: "4"}).Error; err != nil {
+		t.Fatalf("gagal menyimpan data sensor: %v", err)
+	}
+
+	migrator := NewMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if _, err := migrator.Down(len(All())); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	assertApplied(t, migrator, false)
+
+	if !db.Migrator().HasTable(&sensorBmpV1{}) {
+		t.Fatal("tabel sensor yang diadopsi terhapus oleh Down")
+	}
+	var count int64
+	if err := db.Model(&sensorBmpV1{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("tabel sensor berisi %d data (error %v), seharusnya 1", count, err)
+	}
+	if db.Migrator().HasTable(&sensorThmV1{}) {
+		t.Error("tabel sensor yang dibuat oleh Up masih ada setelah Down")
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up setelah Down: %v", err)
+	}
+	assertApplied(t, migrator, true)
+}
+
+// TestDownRefusesUntrackedTables memastikan rollback versi 1 ditolak pada
+// database yang dimigrasi sebelum tabel adopsi dicatat.
+func TestDownRefusesUntrackedTables(t *testing.T) {
+	db := openTestDB(t)
+	migrator := NewMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := db.Migrator().DropTable(&adoptedTableV1{}); err != nil {
+		t.Fatalf("DropTable: %v", err)
+	}
+
+	if _, err := migrator.Down(len(All())); err == nil {
+		t.Fatal("Down menghapus tabel sensor tanpa catatan adopsi")
+	}
+	if !db.Migrator().HasTable(&sensorBmpV1{}) {
+		t.Error("tabel sensor terhapus walaupun rollback ditolak")
+	}
+}
@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"fmt"
+	"iot-golang/config"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// CreateHypertables mengubah tabel sensor menjadi hypertable TimescaleDB.
+// Hypertable mensyaratkan kolom waktu NOT NULL dan termasuk dalam primary key,
+// sehingga data lama tanpa created_at ditempatkan pada epoch 0.
+// Fungsi ini idempotent dan hanya berjalan jika DB_TIMESCALE aktif.
+func CreateHypertables(db *gorm.DB, logger *slog.Logger) error {
+	if !config.TimescaleEnabled(db) {
+		return nil
+	}
+
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS timescaledb").Error; err != nil {
+		return err
+	}
+
+	for _, table := range sensorTableNames {
+		var isHypertable bool
+		if err := db.Raw("SELECT EXISTS (SELECT 1 FROM timescaledb_information.hypertables WHERE hypertable_name = ?)", table).Scan(&isHypertable).Error; err != nil {
+			return err
+		}
+		if isHypertable {
+			continue
+		}
+
+		statements := []string{
+			fmt.Sprintf("UPDATE %s SET created_at = to_timestamp(0) WHERE created_at IS NULL", table),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN created_at SET NOT NULL", table),
+			fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s_pkey", table, table),
+			fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (id, created_at)", table),
+			fmt.Sprintf("SELECT create_hypertable('%s', 'created_at', if_not_exists => TRUE, migrate_data => TRUE)", table),
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("gagal membuat hypertable %s: %w", table, err)
+		}
+
+		logger.Info("Tabel berhasil diubah menjadi hypertable", "component", "migration", "table", table)
+	}
+
+	return nil
+}
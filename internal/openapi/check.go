@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Divergence berisi perbedaan antara route yang terdaftar di echo dan
+// Operations. Undocumented adalah route tanpa dokumentasi, Unregistered adalah
+// operasi di spesifikasi yang tidak punya route.
+type Divergence struct {
+	Undocumented []string
+	Unregistered []string
+}
+
+// Empty bernilai true bila route dan spesifikasi sama persis.
+func (divergence Divergence) Empty() bool {
+	return len(divergence.Undocumented) == 0 && len(divergence.Unregistered) == 0
+}
+
+func (divergence Divergence) Error() string {
+	var parts []string
+	if len(divergence.Undocumented) > 0 {
+		parts = append(parts, "route tanpa dokumentasi OpenAPI: "+strings.Join(divergence.Undocumented, ", "))
+	}
+	if len(divergence.Unregistered) > 0 {
+		parts = append(parts, "operasi OpenAPI tanpa route: "+strings.Join(divergence.Unregistered, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Check membandingkan route echo dengan Operations. Route bawaan echo untuk
+// middleware group tidak ikut dibandingkan.
+func Check(routes []*echo.Route) Divergence {
+	registered := map[string]bool{}
+	for _, route := range routes {
+		if route.Method == echo.RouteNotFound {
+			continue
+		}
+		registered[routeKey(route.Method, route.Path)] = true
+	}
+
+	documented := map[string]bool{}
+	for _, operation := range Operations {
+		documented[routeKey(operation.Method, operation.Path)] = true
+	}
+
+	var divergence Divergence
+	for key := range registered {
+		if !documented[key] {
+			divergence.Undocumented = append(divergence.Undocumented, key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			divergence.Unregistered = append(divergence.Unregistered, key)
+		}
+	}
+	sort.Strings(divergence.Undocumented)
+	sort.Strings(divergence.Unregistered)
+	return divergence
+}
+
+// routeKey menyamakan bentuk route, path group echo tidak selalu diawali "/".
+func routeKey(method, path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s %s", method, path)
+}
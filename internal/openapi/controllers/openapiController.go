@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"encoding/json"
+	"iot-golang/config"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/openapi"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OpenAPIController struct {
+	document []byte
+}
+
+func (controller OpenAPIController) Spec(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, controller.document)
+}
+
+func (controller OpenAPIController) Docs(c echo.Context) error {
+	return c.HTML(http.StatusOK, openapi.SwaggerUI("/openapi.json"))
+}
+
+func NewOpenAPIController(logger *slog.Logger) OpenAPIController {
+	// Spesifikasi tidak berubah selama aplikasi berjalan, cukup disusun sekali
+	document, err := json.Marshal(openapi.Build(config.Version))
+	if err != nil {
+		logger.Error("Gagal menyusun spesifikasi OpenAPI", logging.Error(err))
+	}
+
+	controller := OpenAPIController{
+		document: document,
+	}
+
+	return controller
+}
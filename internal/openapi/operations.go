@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	anomalyModels "iot-golang/internal/anomaly/models"
+	beitianModels "iot-golang/internal/beitian/models"
+	bmpModels "iot-golang/internal/bmp/models"
+	calibrationModels "iot-golang/internal/calibration/models"
+	completenessModels "iot-golang/internal/completeness/models"
+	deviceModels "iot-golang/internal/device/models"
+	geofenceModels "iot-golang/internal/geofence/models"
+	graphModels "iot-golang/internal/graph/models"
+	healthModels "iot-golang/internal/health/models"
+	"iot-golang/internal/helpers"
+	importerModels "iot-golang/internal/importer/models"
+	inaModels "iot-golang/internal/ina/models"
+	pzemModels "iot-golang/internal/pzem/models"
+	readingsModels "iot-golang/internal/readings/models"
+	retentionModels "iot-golang/internal/retention/models"
+	thigrowModels "iot-golang/internal/thigrow/models"
+	thmModels "iot-golang/internal/thm/models"
+	"net/http"
+)
+
+const (
+	apiPrefix   = "/api/iot-sf/"
+	apiV2Prefix = "/api/v2/"
+)
+
+// Query adalah query parameter yang dibaca controller.
+type Query struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Operation menjelaskan satu route yang didaftarkan di cmd/app/main.go. Body
+// berisi struct payload controller, Data berisi isi field data pada envelope
+// helpers.Response dan Raw dipakai untuk route yang tidak memakai envelope.
+type Operation struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Status   int
+	Query    []Query
+	Body     interface{}
+	Upload   bool
+	Data     interface{}
+	Raw      interface{}
+	Produces string
+	Errors   []int
+}
+
+var (
+	queryId          = Query{Name: "id", Description: "id data", Required: true}
+	queryDeviceToken = Query{Name: "device_token", Description: "token perangkat", Required: true}
+	queryUnits       = Query{Name: "units", Description: "daftar satuan tujuan dipisah koma, misalnya kPa,°F"}
+	queryQuality     = Query{Name: "quality", Description: "daftar kualitas dipisah koma: good, suspect, bad"}
+	queryStart       = Query{Name: "start", Description: "awal rentang waktu (RFC3339 atau YYYY-MM-DD)"}
+	queryEnd         = Query{Name: "end", Description: "akhir rentang waktu, tidak termasuk (RFC3339 atau YYYY-MM-DD sampai akhir hari)"}
+	queryFormat      = Query{Name: "format", Description: "format ekspor: csv atau xlsx"}
+	querySensor      = Query{Name: "sensor", Description: "nama sensor: beitian, bmp, ina, pzem, thigrow, thm", Required: true}
+)
+
+// sensorOperations berisi route CRUD, pembacaan dan ekspor yang sama untuk
+// setiap sensor.
+func sensorOperations(sensor string, model, list, createPayload, updatePayload interface{}) []Operation {
+	prefix := apiPrefix + sensor + "/"
+	return []Operation{
+		{Method: http.MethodPost, Path: prefix + "create", Tag: sensor, Summary: "Kirim data sensor " + sensor, Status: http.StatusCreated, Body: createPayload},
+		{Method: http.MethodGet, Path: prefix + "get_all", Tag: sensor, Summary: "Ambil seluruh data sensor " + sensor, Query: []Query{queryUnits, queryQuality}, Data: list},
+		{Method: http.MethodGet, Path: prefix + "detail", Tag: sensor, Summary: "Ambil data sensor " + sensor + " berdasarkan id", Query: []Query{queryId, queryUnits}, Data: model, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: prefix + "detail/token", Tag: sensor, Summary: "Ambil data sensor " + sensor + " berdasarkan token perangkat", Query: []Query{queryDeviceToken, queryUnits, queryQuality}, Data: list, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: prefix + "export", Tag: sensor, Summary: "Ekspor data mentah sensor " + sensor + ", data yang sudah dihapus job retensi hanya tersedia lewat history", Query: []Query{queryFormat, {Name: "device_token", Description: "token perangkat"}, queryStart, queryEnd, queryQuality, queryUnits}, Produces: "text/csv"},
+		{Method: http.MethodPut, Path: prefix + "update/:id", Tag: sensor, Summary: "Ubah data sensor " + sensor, Body: updatePayload, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: prefix + "delete/:id", Tag: sensor, Summary: "Hapus data sensor " + sensor, Errors: []int{http.StatusNotFound}},
+	}
+}
+
+// Operations berisi seluruh route aplikasi. Route baru di main.go wajib
+// ditambahkan di sini, perbedaan keduanya dilaporkan oleh Check.
+var Operations = concat(
+	[]Operation{
+		{Method: http.MethodGet, Path: "/metrics", Tag: "operasional", Summary: "Metrics Prometheus", Produces: "text/plain"},
+		{Method: http.MethodGet, Path: "/healthz", Tag: "operasional", Summary: "Liveness probe", Data: map[string]string{}},
+		{Method: http.MethodGet, Path: "/readyz", Tag: "operasional", Summary: "Readiness probe", Data: healthModels.Readiness{}, Errors: []int{http.StatusServiceUnavailable}},
+		{Method: http.MethodGet, Path: "/debug/info", Tag: "operasional", Summary: "Informasi build, pool koneksi dan jumlah data", Data: healthModels.Info{}},
+		{Method: http.MethodGet, Path: "/openapi.json", Tag: "operasional", Summary: "Spesifikasi OpenAPI", Raw: map[string]interface{}{}},
+		{Method: http.MethodGet, Path: "/docs", Tag: "operasional", Summary: "Swagger UI", Produces: "text/html"},
+	},
+	sensorOperations("beitian", beitianModels.Beitian{}, []beitianModels.Beitian{}, beitianModels.CreatePayload{}, beitianModels.UpdatePayload{}),
+	[]Operation{
+		{Method: http.MethodGet, Path: apiPrefix + "beitian/detail/new/token", Tag: "beitian", Summary: "Ambil posisi terbaru perangkat beitian", Query: []Query{queryDeviceToken, queryUnits}, Data: beitianModels.Beitian{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: apiPrefix + "beitian/track", Tag: "beitian", Summary: "Ambil lintasan perangkat beitian dalam GeoJSON", Query: []Query{queryDeviceToken, queryStart, queryEnd, queryQuality}, Data: helpers.GeoJSONFeatureCollection{}},
+
+		{Method: http.MethodPost, Path: apiPrefix + "geofence/create", Tag: "geofence", Summary: "Buat geofence", Status: http.StatusCreated, Body: geofenceModels.Payload{}},
+		{Method: http.MethodGet, Path: apiPrefix + "geofence/get_all", Tag: "geofence", Summary: "Ambil seluruh geofence", Query: []Query{{Name: "farm", Description: "nama kebun"}}, Data: []geofenceModels.Geofence{}},
+		{Method: http.MethodGet, Path: apiPrefix + "geofence/detail", Tag: "geofence", Summary: "Ambil geofence berdasarkan id", Query: []Query{queryId}, Data: geofenceModels.Geofence{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: apiPrefix + "geofence/events", Tag: "geofence", Summary: "Ambil event masuk dan keluar geofence", Query: []Query{queryDeviceToken}, Data: []geofenceModels.GeofenceEvent{}},
+		{Method: http.MethodPut, Path: apiPrefix + "geofence/update/:id", Tag: "geofence", Summary: "Ubah geofence", Body: geofenceModels.Payload{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: apiPrefix + "geofence/delete/:id", Tag: "geofence", Summary: "Hapus geofence", Errors: []int{http.StatusNotFound}},
+
+		{Method: http.MethodPost, Path: apiPrefix + "device/create", Tag: "device", Summary: "Daftarkan perangkat", Status: http.StatusCreated, Body: deviceModels.CreatePayload{}},
+		{Method: http.MethodGet, Path: apiPrefix + "device/get_all", Tag: "device", Summary: "Ambil seluruh perangkat", Data: []deviceModels.Device{}},
+		{Method: http.MethodGet, Path: apiPrefix + "device/detail", Tag: "device", Summary: "Ambil perangkat berdasarkan id", Query: []Query{queryId}, Data: deviceModels.Device{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodPut, Path: apiPrefix + "device/update/:id", Tag: "device", Summary: "Ubah perangkat", Body: deviceModels.UpdatePayload{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: apiPrefix + "device/delete/:id", Tag: "device", Summary: "Hapus perangkat", Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: apiPrefix + "map/devices", Tag: "device", Summary: "Ambil posisi dan pembacaan terakhir perangkat dalam GeoJSON", Data: helpers.GeoJSONFeatureCollection{}},
+	},
+	sensorOperations("bmp", bmpModels.Bmp{}, []bmpModels.Bmp{}, bmpModels.CreatePayload{}, bmpModels.UpdatePayload{}),
+	sensorOperations("ina", inaModels.Ina{}, []inaModels.Ina{}, inaModels.CreatePayload{}, inaModels.UpdatePayload{}),
+	sensorOperations("pzem", pzemModels.Pzem{}, []pzemModels.Pzem{}, pzemModels.CreatePayload{}, pzemModels.UpdatePayload{}),
+	sensorOperations("thigrow", thigrowModels.Thigrow{}, []thigrowModels.Thigrow{}, thigrowModels.CreatePayload{}, thigrowModels.UpdatePayload{}),
+	sensorOperations("thm", thmModels.Thm{}, []thmModels.Thm{}, thmModels.CreatePayload{}, thmModels.UpdatePayload{}),
+	[]Operation{
+		{Method: http.MethodGet, Path: apiPrefix + "anomaly/events", Tag: "anomaly", Summary: "Ambil event anomali", Query: []Query{{Name: "device_token", Description: "token perangkat"}, {Name: "sensor", Description: "nama sensor"}, queryStart, queryEnd, queryUnits}, Data: []anomalyModels.AnomalyEvent{}},
+
+		{Method: http.MethodPost, Path: apiPrefix + "calibration/create", Tag: "calibration", Summary: "Simpan kalibrasi perangkat", Status: http.StatusCreated, Body: calibrationModels.CreatePayload{}},
+		{Method: http.MethodGet, Path: apiPrefix + "calibration/get_all", Tag: "calibration", Summary: "Ambil seluruh kalibrasi", Query: []Query{{Name: "sensor", Description: "nama sensor"}, {Name: "device_token", Description: "token perangkat"}}, Data: []calibrationModels.Calibration{}},
+		{Method: http.MethodGet, Path: apiPrefix + "calibration/detail", Tag: "calibration", Summary: "Ambil kalibrasi berdasarkan id", Query: []Query{queryId}, Data: calibrationModels.Calibration{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodPut, Path: apiPrefix + "calibration/update/:id", Tag: "calibration", Summary: "Ubah kalibrasi", Body: calibrationModels.UpdatePayload{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: apiPrefix + "calibration/delete/:id", Tag: "calibration", Summary: "Hapus kalibrasi", Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodPost, Path: apiPrefix + "calibration/recompute", Tag: "calibration", Summary: "Hitung ulang data lama dengan kalibrasi yang berlaku", Body: calibrationModels.RecomputePayload{}, Data: calibrationModels.RecomputeReport{}},
+
+		{Method: http.MethodGet, Path: apiPrefix + "completeness", Tag: "completeness", Summary: "Laporan kelengkapan data dan celah per hari, memakai jumlah data pada rollup untuk rentang yang data mentahnya sudah dihapus", Query: []Query{querySensor, {Name: "device_token", Description: "token perangkat"}, queryStart, queryEnd}, Data: []completenessModels.DailyReport{}},
+
+		{Method: http.MethodPost, Path: apiPrefix + "graphql", Tag: "graphql", Summary: "Query GraphQL atas perangkat dan data sensor, schema ada di internal/graph/schema.graphql", Body: graphModels.QueryPayload{}, Raw: map[string]interface{}{}},
+
+		{Method: http.MethodPost, Path: apiPrefix + "import/:sensor", Tag: "import", Summary: "Import data historis dari CSV", Query: []Query{{Name: "map", Description: "pemetaan kolom CSV dengan format kolom_csv=field,kolom_lain=field"}}, Upload: true, Data: importerModels.ImportReport{}},
+
+		{Method: http.MethodGet, Path: apiPrefix + "retention/policy", Tag: "retention", Summary: "Ambil kebijakan retensi seluruh sensor", Data: []retentionModels.RetentionPolicy{}},
+		{Method: http.MethodPut, Path: apiPrefix + "retention/policy/:sensor", Tag: "retention", Summary: "Simpan kebijakan retensi sensor", Body: retentionModels.PolicyPayload{}, Data: retentionModels.RetentionPolicy{}},
+		{Method: http.MethodGet, Path: apiPrefix + "retention/status", Tag: "retention", Summary: "Status job retensi, termasuk jumlah data tanpa created_at yang dipindahkan ke karantina", Data: retentionModels.JobStatus{}},
+		{Method: http.MethodPost, Path: apiPrefix + "retention/run", Tag: "retention", Summary: "Jalankan job retensi sekarang", Status: http.StatusAccepted, Errors: []int{http.StatusConflict}},
+		{Method: http.MethodGet, Path: apiPrefix + "history", Tag: "retention", Summary: "Riwayat data mentah, dilanjutkan rollup per jam lalu per hari untuk rentang yang data mentahnya sudah dihapus", Query: []Query{querySensor, queryDeviceToken, queryStart, queryEnd, queryUnits}, Data: []retentionModels.HistoryPoint{}},
+	},
+	readingsOperations,
+)
+
+// readingsOperations berisi route API v2. Body dan data mengikuti sensor pada
+// parameter type, schema thm dipakai sebagai contoh. Data v2 membawa
+// reading_id yang dipakai route /readings/:id.
+var readingsOperations = []Operation{
+	{Method: http.MethodGet, Path: apiV2Prefix + "sensors/:type/readings", Tag: "v2", Summary: "Ambil data sensor, difilter dengan token perangkat bila ada", Query: []Query{{Name: "device_token", Description: "token perangkat"}, queryQuality, queryUnits}, Data: []readingsModels.Reading{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodPost, Path: apiV2Prefix + "sensors/:type/readings", Tag: "v2", Summary: "Kirim data sensor", Status: http.StatusCreated, Body: thmModels.CreatePayload{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: apiV2Prefix + "sensors/:type/readings/:id", Tag: "v2", Summary: "Ambil satu data sensor", Query: []Query{queryUnits}, Data: readingsModels.Reading{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodPatch, Path: apiV2Prefix + "sensors/:type/readings/:id", Tag: "v2", Summary: "Ubah sebagian field data sensor", Body: map[string]interface{}{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodDelete, Path: apiV2Prefix + "sensors/:type/readings/:id", Tag: "v2", Summary: "Hapus data sensor", Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: apiV2Prefix + "readings/:id", Tag: "v2", Summary: "Ambil satu data sensor dengan reading_id, misalnya bmp-42", Query: []Query{queryUnits}, Data: readingsModels.Reading{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodPatch, Path: apiV2Prefix + "readings/:id", Tag: "v2", Summary: "Ubah sebagian field data sensor dengan reading_id", Body: map[string]interface{}{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodDelete, Path: apiV2Prefix + "readings/:id", Tag: "v2", Summary: "Hapus data sensor dengan reading_id", Errors: []int{http.StatusNotFound}},
+}
+
+func concat(groups ...[]Operation) []Operation {
+	var operations []Operation
+	for _, group := range groups {
+		operations = append(operations, group...)
+	}
+	return operations
+}
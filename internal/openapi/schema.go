@@ -0,0 +1,193 @@
+package openapi
+
+import (
+	"iot-golang/internal/helpers"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema adalah subset JSON Schema yang dipakai OpenAPI 3.0.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// generator menyimpan schema struct bernama di components agar setiap struct
+// cukup ditulis sekali dan dirujuk lewat $ref.
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func newGenerator() *generator {
+	return &generator{schemas: map[string]*Schema{}}
+}
+
+// envelope membungkus schema data dengan helpers.Response.
+func (generator *generator) envelope(data interface{}) *Schema {
+	response := generator.schemaOf(helpers.Response{})
+	if data == nil {
+		return response
+	}
+	return &Schema{AllOf: []*Schema{response, {Type: "object", Properties: map[string]*Schema{"data": generator.schemaOf(data)}}}}
+}
+
+func (generator *generator) schemaOf(value interface{}) *Schema {
+	return generator.schema(reflect.TypeOf(value))
+}
+
+func (generator *generator) schema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := generator.schema(t.Elem())
+		if schema.Ref != "" {
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		if t.PkgPath() == "time" && t.Name() == "Duration" {
+			return &Schema{Type: "integer", Format: "int64", Description: "durasi dalam nanodetik"}
+		}
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: generator.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: generator.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		return generator.ref(t)
+	}
+
+	// interface{} dan tipe lain dibiarkan bebas
+	return &Schema{}
+}
+
+// ref mendaftarkan struct ke components dan mengembalikan rujukannya. Struct
+// tanpa nama ditulis langsung di tempat.
+func (generator *generator) ref(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		generator.fields(t, schema)
+		return schema
+	}
+
+	name := schemaName(t)
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := generator.schemas[name]; ok {
+		return ref
+	}
+
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	generator.schemas[name] = schema
+	generator.fields(t, schema)
+	return ref
+}
+
+func (generator *generator) fields(t reflect.Type, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			generator.fields(field.Type, schema)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := generator.schema(field.Type)
+		if applyValidation(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyValidation menerjemahkan tag validate yang dikenal ke schema dan
+// mengembalikan true bila field wajib diisi.
+func applyValidation(schema *Schema, tag string) bool {
+	required := false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			// aturan setelah dive berlaku untuk elemen slice
+			if schema.Items != nil {
+				applyValidation(schema.Items, strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "required":
+			required = true
+		case "numeric":
+			schema.Format = "decimal"
+			schema.Description = "angka desimal dalam bentuk string"
+		case "min", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			switch schema.Type {
+			case "array":
+				schema.MinItems = &n
+				if name == "len" {
+					schema.MaxItems = &n
+				}
+			case "integer", "number":
+				minimum := float64(n)
+				schema.Minimum = &minimum
+			}
+		}
+	}
+	return required
+}
+
+// schemaName memberi nama komponen dari paket dan nama struct, misalnya
+// thm/models.CreatePayload menjadi ThmCreatePayload.
+func schemaName(t reflect.Type) string {
+	segments := strings.Split(t.PkgPath(), "/")
+	pkg := segments[len(segments)-1]
+	if pkg == "models" && len(segments) > 1 {
+		pkg = segments[len(segments)-2]
+	}
+
+	prefix := strings.ToUpper(pkg[:1]) + pkg[1:]
+	if strings.HasPrefix(t.Name(), prefix) || pkg == "helpers" {
+		return t.Name()
+	}
+	return prefix + t.Name()
+}
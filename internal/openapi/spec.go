@@ -0,0 +1,204 @@
+package openapi
+
+import (
+	"iot-golang/internal/helpers"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Document adalah dokumen OpenAPI 3 yang dikirim ke Swagger UI.
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Tags       []Tag                           `json:"tags,omitempty"`
+	Paths      map[string]map[string]*Endpoint `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Endpoint adalah satu operasi pada path OpenAPI.
+type Endpoint struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	OperationID string               `json:"operationId"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Build menyusun dokumen OpenAPI dari daftar Operations. Schema payload dan
+// data response dibaca dari struct Go lewat reflection.
+func Build(version string) *Document {
+	generator := newGenerator()
+	document := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "IoT Smart Farming API",
+			Description: "API penerimaan dan pembacaan data sensor IoT smart farming.",
+			Version:     version,
+		},
+		Paths: map[string]map[string]*Endpoint{},
+	}
+
+	tags := map[string]bool{}
+	for _, operation := range Operations {
+		path := specPath(operation.Path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = map[string]*Endpoint{}
+		}
+		document.Paths[path][strings.ToLower(operation.Method)] = operation.endpoint(generator)
+
+		if !tags[operation.Tag] {
+			tags[operation.Tag] = true
+			document.Tags = append(document.Tags, Tag{Name: operation.Tag})
+		}
+	}
+	sort.Slice(document.Tags, func(i, j int) bool { return document.Tags[i].Name < document.Tags[j].Name })
+
+	document.Components.Schemas = generator.schemas
+	return document
+}
+
+// endpoint menyusun operasi OpenAPI beserta envelope response sukses dan error.
+func (operation Operation) endpoint(generator *generator) *Endpoint {
+	endpoint := &Endpoint{
+		Tags:        []string{operation.Tag},
+		Summary:     operation.Summary,
+		OperationID: operationID(operation.Method, operation.Path),
+		Responses:   map[string]*Response{},
+	}
+
+	for _, segment := range strings.Split(operation.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			endpoint.Parameters = append(endpoint.Parameters, Parameter{Name: segment[1:], In: "path", Required: true, Schema: pathSchema(segment[1:])})
+		}
+	}
+	for _, query := range operation.Query {
+		endpoint.Parameters = append(endpoint.Parameters, Parameter{Name: query.Name, In: "query", Description: query.Description, Required: query.Required, Schema: &Schema{Type: "string"}})
+	}
+
+	if operation.Body != nil {
+		endpoint.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: generator.schemaOf(operation.Body)}},
+		}
+	}
+	if operation.Upload {
+		endpoint.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				"multipart/form-data": {Schema: &Schema{Type: "object", Required: []string{"file"}, Properties: map[string]*Schema{"file": {Type: "string", Format: "binary"}}}},
+				"text/csv":            {Schema: &Schema{Type: "string"}},
+			},
+		}
+	}
+
+	status := operation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	switch {
+	case operation.Produces != "":
+		success.Content = map[string]*MediaType{operation.Produces: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	case operation.Raw != nil:
+		success.Content = jsonContent(generator.schemaOf(operation.Raw))
+	default:
+		success.Content = jsonContent(generator.envelope(operation.Data))
+	}
+	endpoint.Responses[strconv.Itoa(status)] = success
+
+	// API v2 memakai satu bentuk objek error untuk semua status
+	validationError, otherError := generator.schemaOf(helpers.ValidationResponse{}), generator.schemaOf(helpers.Response{})
+	if strings.HasPrefix(operation.Path, apiV2Prefix) {
+		validationError = generator.schemaOf(helpers.ErrorResponse{})
+		otherError = validationError
+	}
+
+	if operation.Body != nil || operation.Upload || len(operation.Query) > 0 || strings.Contains(operation.Path, ":") {
+		endpoint.Responses["400"] = &Response{Description: http.StatusText(http.StatusBadRequest), Content: jsonContent(validationError)}
+	}
+	for _, code := range operation.Errors {
+		endpoint.Responses[strconv.Itoa(code)] = &Response{Description: http.StatusText(code), Content: jsonContent(otherError)}
+	}
+	if operation.Raw == nil {
+		endpoint.Responses["500"] = &Response{Description: http.StatusText(http.StatusInternalServerError), Content: jsonContent(otherError)}
+	}
+
+	return endpoint
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+// specPath mengubah path echo (/update/:id) menjadi path OpenAPI (/update/{id}).
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathSchema(name string) *Schema {
+	if name == "id" {
+		return &Schema{Type: "integer", Format: "int64"}
+	}
+	return &Schema{Type: "string"}
+}
+
+// operationID menyusun id operasi yang stabil dari method dan path, misalnya
+// GET /api/iot-sf/thm/detail/token menjadi getThmDetailToken.
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	if strings.HasPrefix(path, apiV2Prefix) {
+		path = "v2/" + strings.TrimPrefix(path, apiV2Prefix)
+	}
+	path = strings.TrimPrefix(path, apiPrefix)
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '_' || r == '-' || r == '.' }) {
+		segment = strings.TrimPrefix(segment, ":")
+		id += strings.ToUpper(segment[:1]) + segment[1:]
+	}
+	return id
+}
@@ -0,0 +1,29 @@
+package openapi
+
+import "fmt"
+
+// SwaggerUIVersion adalah versi swagger-ui-dist yang dimuat dari CDN.
+var SwaggerUIVersion = "5.17.14"
+
+// SwaggerUI menyusun halaman Swagger UI yang membaca spesifikasi dari specURL.
+func SwaggerUI(specURL string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="id">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>IoT Smart Farming API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@%[1]s/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@%[1]s/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: %[2]q, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`, SwaggerUIVersion, specURL)
+}
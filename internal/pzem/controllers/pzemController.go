@@ -1,12 +1,14 @@
 package controllers
 
 import (
-	"fmt"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/pzem/models"
 	"iot-golang/internal/pzem/services"
+	"log/slog"
 	"net/http"
-	"reflect"
 	"strconv"
 
 	v1 "github.com/go-playground/validator/v10"
@@ -15,21 +17,13 @@ import (
 )
 
 type PzemController struct {
+	logger      *slog.Logger
 	pzemService services.PzemService
 	validate    v1.Validate
 }
 
-var httpStatus int
-
 func (controller PzemController) Create(c echo.Context) error {
-	type payload struct {
-		DeviceToken string `json:"device_token" validate:"required"`
-		Tegangan    string `json:"tegangan" validate:"required"`
-		Arus        string `json:"arus" validate:"required"`
-		Daya        string `json:"daya" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.CreatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
 		return err
@@ -37,46 +31,35 @@ func (controller PzemController) Create(c echo.Context) error {
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
-
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+		metrics.RecordValidationRejections("pzem", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
-	result := controller.pzemService.Create(models.Pzem{DeviceToken: payloadValidator.DeviceToken, Tegangan: payloadValidator.Tegangan, Arus: payloadValidator.Arus, Daya: payloadValidator.Daya})
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("pzem", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-	if result.Status == 201 {
-		httpStatus = http.StatusCreated
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	reading := payloadValidator.ToModel()
+	reading.Quality = report.Quality
+	result, err := controller.pzemService.Create(c.Request().Context(), reading)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusCreated)
 }
 
 func (controller PzemController) Update(c echo.Context) error {
-	type payload struct {
-		Tegangan string `json:"tegangan" validate:"required"`
-		Arus     string `json:"arus" validate:"required"`
-		Daya     string `json:"daya" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.UpdatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
 		return err
@@ -84,94 +67,140 @@ func (controller PzemController) Update(c echo.Context) error {
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
 
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		metrics.RecordValidationRejections("pzem", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
+
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("pzem", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
 	idPzem, _ := strconv.Atoi(c.Param("id"))
-	result := controller.pzemService.Update(int64(idPzem), models.Pzem{Tegangan: payloadValidator.Tegangan, Arus: payloadValidator.Arus, Daya: payloadValidator.Daya})
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.pzemService.Update(c.Request().Context(), int64(idPzem), models.Pzem{Tegangan: payloadValidator.Tegangan, Arus: payloadValidator.Arus, Daya: payloadValidator.Daya, Quality: report.Quality})
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller PzemController) Delete(c echo.Context) error {
 	idPzem, _ := strconv.Atoi(c.Param("id"))
-	result := controller.pzemService.Delete(int64(idPzem))
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.pzemService.Delete(c.Request().Context(), int64(idPzem))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller PzemController) GetAll(c echo.Context) error {
-	result := controller.pzemService.GetAll()
-	return c.JSON(http.StatusOK, result)
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	result, err := controller.pzemService.GetAll(c.Request().Context(), quality)
+	if err != nil {
+		return err
+	}
+
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller PzemController) GetById(c echo.Context) error {
-	idPzem, _ := strconv.Atoi(c.QueryParam("id"))
-	result := controller.pzemService.GetById(int64(idPzem))
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	idPzem, _ := strconv.Atoi(c.QueryParam("id"))
+	result, err := controller.pzemService.GetById(c.Request().Context(), int64(idPzem))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller PzemController) GetByToken(c echo.Context) error {
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
 	idTokenPzem := c.QueryParam("device_token")
-	result := controller.pzemService.GetByToken(idTokenPzem)
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.pzemService.GetByToken(c.Request().Context(), idTokenPzem, quality)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller PzemController) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = helpers.ExportCSV
+	}
+
+	contentType, err := helpers.ExportContentType(format)
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"format": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	response := helpers.NewExportResponse(c.Response(), contentType, helpers.ExportFilename("pzem", start, end, format))
+	err = controller.pzemService.Export(c.Request().Context(), c.QueryParam("device_token"), start, end, quality, units, format, response)
+	if err != nil && c.Response().Committed {
+		// Status 200 sudah terkirim, koneksi diputus agar client tidak
+		// menganggap file yang terpotong sebagai file lengkap.
+		controller.logger.ErrorContext(c.Request().Context(), "Ekspor terhenti setelah response dikirim", logging.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+	return err
 }
 
-func NewPzemController(db *gorm.DB) PzemController {
-	service := services.NewPzemService(db)
+func NewPzemController(db *gorm.DB, logger *slog.Logger) PzemController {
+	service := services.NewPzemService(db, logger)
 	controller := PzemController{
+		logger:      logger.With("sensor", "pzem"),
 		pzemService: service,
 		validate:    *v1.New(),
 	}
@@ -1,13 +1,30 @@
 package models
 
+import (
+	"iot-golang/internal/helpers"
+	"time"
+)
+
 type Pzem struct {
-	Id          int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	DeviceToken string `json:"device_token" gorm:"column:device_token"`
-	Tegangan    string `json:"tegangan" gorm:"column:tegangan"`
-	Arus        string `json:"arus" gorm:"column:arus"`
-	Daya        string `json:"daya" gorm:"column:daya"`
+	Id           int64             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken  string            `json:"device_token" gorm:"column:device_token"`
+	Tegangan     string            `json:"tegangan" gorm:"column:tegangan"`
+	Arus         string            `json:"arus" gorm:"column:arus"`
+	Daya         string            `json:"daya" gorm:"column:daya"`
+	Quality      string            `json:"quality" gorm:"column:quality;default:good"`
+	AnomalyScore float64           `json:"anomaly_score" gorm:"column:anomaly_score;default:0"`
+	RawValues    map[string]string `json:"raw_values,omitempty" gorm:"column:raw_values;serializer:json"`
+	CreatedAt    time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 }
 
 func (Pzem) TableName() string {
 	return "db_sensor_pzem"
 }
+
+// Fields berisi metadata field sensor pzem beserta satuan, rentang nilai dan
+// batas perubahan per menitnya.
+var Fields = []helpers.Field{
+	{Name: "tegangan", Column: "tegangan", Unit: "V", Range: helpers.Between(0, 300).Suspect(80, 260), MaxRate: 50},
+	{Name: "arus", Column: "arus", Unit: "A", Range: helpers.Between(0, 100)},
+	{Name: "daya", Column: "daya", Unit: "W", Range: helpers.Between(0, 23000)},
+}
@@ -0,0 +1,26 @@
+package models
+
+// CreatePayload adalah payload untuk membuat data sensor pzem baru.
+// Aturan validasi yang sama dipakai oleh endpoint create dan import CSV.
+type CreatePayload struct {
+	DeviceToken string `json:"device_token" validate:"required"`
+	Tegangan    string `json:"tegangan" validate:"required,numeric"`
+	Arus        string `json:"arus" validate:"required,numeric"`
+	Daya        string `json:"daya" validate:"required,numeric"`
+}
+
+func (payload CreatePayload) ToModel() Pzem {
+	return Pzem{
+		DeviceToken: payload.DeviceToken,
+		Tegangan:    payload.Tegangan,
+		Arus:        payload.Arus,
+		Daya:        payload.Daya,
+	}
+}
+
+// UpdatePayload adalah payload untuk mengubah data sensor pzem.
+type UpdatePayload struct {
+	Tegangan string `json:"tegangan" validate:"required,numeric"`
+	Arus     string `json:"arus" validate:"required,numeric"`
+	Daya     string `json:"daya" validate:"required,numeric"`
+}
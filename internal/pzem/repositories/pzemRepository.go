@@ -1,7 +1,13 @@
 package repositories
 
 import (
+	"context"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/pzem/models"
+	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,50 +17,118 @@ type dbPzem struct {
 }
 
 // Create implements PzemRepository.
-func (db *dbPzem) Create(pzem models.Pzem) error {
-	return db.Conn.Create(&pzem).Error
+func (db *dbPzem) Create(ctx context.Context, pzem models.Pzem) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "Create")).Create(&pzem).Error
+}
+
+// CreateBatch implements PzemRepository.
+func (db *dbPzem) CreateBatch(ctx context.Context, pzem []models.Pzem, BatchSize int) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "CreateBatch")).CreateInBatches(&pzem, BatchSize).Error
 }
 
 // Delete implements PzemRepository.
-func (db *dbPzem) Delete(Id int64) error {
-	return db.Conn.Delete(&models.Pzem{Id: Id}).Error
+func (db *dbPzem) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "Delete")).Delete(&models.Pzem{Id: Id}).Error
 }
 
 // GetAll implements PzemRepository.
-func (db *dbPzem) GetAll() ([]models.Pzem, error) {
+func (db *dbPzem) GetAll(ctx context.Context, Quality []string) ([]models.Pzem, error) {
+	var data []models.Pzem
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "GetAll")).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// Query implements PzemRepository.
+func (db *dbPzem) Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Pzem, error) {
 	var data []models.Pzem
-	result := db.Conn.Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "Query")).Scopes(Query.Scope).Find(&data)
 	return data, result.Error
 }
 
 // GetById implements PzemRepository.
-func (db *dbPzem) GetById(Id int64) (models.Pzem, error) {
+func (db *dbPzem) GetById(ctx context.Context, Id int64) (models.Pzem, error) {
 	var data models.Pzem
-	result := db.Conn.Where("id", Id).First(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "GetById")).Where("id", Id).First(&data)
 	return data, result.Error
 }
 
 // GetByToken implements PzemRepository.
-func (db *dbPzem) GetByToken(DeviceToken string) ([]models.Pzem, error) {
+func (db *dbPzem) GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Pzem, error) {
+	var data []models.Pzem
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "GetByToken")).Where("device_token", DeviceToken).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// GetNewByToken implements PzemRepository.
+func (db *dbPzem) GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Pzem, error) {
 	var data []models.Pzem
-	result := db.Conn.Where("device_token", DeviceToken).Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "GetNewByToken")).Where("device_token = ?", DeviceToken).Order("id desc").Limit(1).Find(&data)
 	return data, result.Error
 }
 
+// GetNewByTokens implements PzemRepository.
+// Data terbaru setiap perangkat diambil dalam satu query dengan subquery id
+// terbesar per device_token.
+func (db *dbPzem) GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Pzem, error) {
+	var data []models.Pzem
+	latest := db.Conn.Model(&models.Pzem{}).Select("MAX(id)").Where("device_token IN ?", DeviceTokens).Group("device_token")
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "GetNewByTokens")).Where("id IN (?)", latest).Find(&data)
+	return data, result.Error
+}
+
+// Export implements PzemRepository.
+// Data dibaca memakai cursor Rows sehingga tidak dimuat seluruhnya ke memori.
+func (db *dbPzem) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(pzem models.Pzem) error) error {
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "Export")).Model(&models.Pzem{}).Where("created_at >= ? AND created_at < ?", Start, End).Scopes(helpers.QualityScope(Quality))
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+
+	rows, err := query.Order("created_at asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data models.Pzem
+		if err := db.Conn.ScanRows(rows, &data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 // Update implements PzemRepository.
-func (db *dbPzem) Update(Id int64, pzem models.Pzem) error {
-	return db.Conn.Where("id", Id).Updates(pzem).Error
+// Kolom nilai, quality dan raw_values selalu ditulis, termasuk raw_values yang
+// kosong, agar nilai mentah lama tidak tertinggal setelah data diubah.
+func (db *dbPzem) Update(ctx context.Context, Id int64, pzem models.Pzem) error {
+	columns := []string{"quality", "raw_values"}
+	for _, field := range models.Fields {
+		columns = append(columns, field.Column)
+	}
+
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "pzem", "Update")).Model(&models.Pzem{}).Where("id", Id).Select(columns).Updates(pzem).Error
 }
 
 type PzemRepository interface {
-	Create(pzem models.Pzem) error
-	Update(Id int64, pzem models.Pzem) error
-	Delete(Id int64) error
-	GetById(Id int64) (models.Pzem, error)
-	GetByToken(DeviceToken string) ([]models.Pzem, error)
-	GetAll() ([]models.Pzem, error)
+	Create(ctx context.Context, pzem models.Pzem) error
+	CreateBatch(ctx context.Context, pzem []models.Pzem, BatchSize int) error
+	Update(ctx context.Context, Id int64, pzem models.Pzem) error
+	Delete(ctx context.Context, Id int64) error
+	GetById(ctx context.Context, Id int64) (models.Pzem, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Pzem, error)
+	GetAll(ctx context.Context, Quality []string) ([]models.Pzem, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Pzem, error)
+	GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Pzem, error)
+	GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Pzem, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(pzem models.Pzem) error) error
 }
 
-func NewPzemRepository(Conn *gorm.DB) PzemRepository {
-	return &dbPzem{Conn: Conn}
+func NewPzemRepository(Conn *gorm.DB, logger *slog.Logger) PzemRepository {
+	return &dbPzem{Conn: logging.Session(Conn, logger.With("repository", "pzem"))}
 }
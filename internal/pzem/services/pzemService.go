@@ -1,125 +1,234 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	anomalyModels "iot-golang/internal/anomaly/models"
+	anomalyServices "iot-golang/internal/anomaly/services"
+	"iot-golang/internal/broadcast"
+	calibrationServices "iot-golang/internal/calibration/services"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/pzem/models"
 	"iot-golang/internal/pzem/repositories"
-	"log"
+	"iot-golang/internal/tracing"
+	"log/slog"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type pzemService struct {
-	pzemRepo repositories.PzemRepository
+	logger             *slog.Logger
+	pzemRepo           repositories.PzemRepository
+	anomalyService     anomalyServices.AnomalyService
+	calibrationService calibrationServices.CalibrationService
 }
 
 // Create implements PzemService.
-func (service *pzemService) Create(pzem models.Pzem) helpers.Response {
+func (service *pzemService) Create(ctx context.Context, pzem models.Pzem) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "PzemService.Create")
+	defer span.End()
+
 	var response helpers.Response
-	if err := service.pzemRepo.Create(pzem); err != nil {
-		log.Println("ERROR: Gagal membuat data sensor baru, error :" + err.Error())
-		response.Status = 500
-		response.Messages = "Gagal membuat data sensor baru"
-	} else {
-		log.Println("SUCCESS: Berhasil membuat data sensor baru")
-		response.Status = 201
-		response.Messages = "Berhasil membuat data sensor baru"
-	}
-	return response
+	raw, err := service.calibrationService.Calibrator(ctx, "pzem").Apply(pzem.DeviceToken, time.Now(), &pzem)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", pzem.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+	if raw != nil {
+		pzem.RawValues = raw
+		pzem.Quality = helpers.AssessQuality(models.Fields, &pzem).Quality
+	}
+
+	inspection := service.anomalyService.Inspect(ctx, "pzem", pzem.DeviceToken, pzem.Quality, helpers.FieldValues(models.Fields, pzem))
+	pzem.AnomalyScore = inspection.Score
+
+	if err := service.pzemRepo.Create(ctx, pzem); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "device_token", pzem.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspection)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "device_token", pzem.DeviceToken)
+	metrics.RecordIngested("pzem", pzem.DeviceToken, 1)
+	broadcast.Publish("pzem", pzem.DeviceToken, pzem)
+	response.SetMessage(ctx, i18n.SensorCreated)
+	return response, nil
+}
+
+// CreateBatch implements PzemService.
+func (service *pzemService) CreateBatch(ctx context.Context, pzem []models.Pzem) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "PzemService.CreateBatch")
+	defer span.End()
+
+	var response helpers.Response
+	calibrator := service.calibrationService.Calibrator(ctx, "pzem")
+	now := time.Now()
+	tokens := make([]string, len(pzem))
+	readings := make([]anomalyModels.Reading, len(pzem))
+	for i := range pzem {
+		tokens[i] = pzem[i].DeviceToken
+		// Data tanpa created_at akan diberi waktu sekarang oleh database
+		at := pzem[i].CreatedAt
+		if at.IsZero() {
+			at = now
+		}
+		raw, err := calibrator.Apply(pzem[i].DeviceToken, at, &pzem[i])
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", pzem[i].DeviceToken, logging.Error(err))
+			return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+		}
+		if raw != nil {
+			pzem[i].RawValues = raw
+			pzem[i].Quality = helpers.AssessQuality(models.Fields, &pzem[i]).Quality
+		}
+		readings[i] = anomalyModels.Reading{DeviceToken: pzem[i].DeviceToken, Quality: pzem[i].Quality, Values: helpers.FieldValues(models.Fields, pzem[i]), At: pzem[i].CreatedAt}
+	}
+
+	inspections := service.anomalyService.InspectBatch(ctx, "pzem", readings)
+	for i := range inspections {
+		pzem[i].AnomalyScore = inspections[i].Score
+	}
+
+	if err := service.pzemRepo.CreateBatch(ctx, pzem, len(pzem)); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "count", len(pzem), "device_tokens", helpers.DistinctTokens(tokens), logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspections...)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "count", len(pzem), "device_tokens", helpers.DistinctTokens(tokens))
+	for _, reading := range pzem {
+		metrics.RecordIngested("pzem", reading.DeviceToken, 1)
+		broadcast.Publish("pzem", reading.DeviceToken, reading)
+	}
+	response.SetMessage(ctx, i18n.SensorCreated)
+
+	return response, nil
 }
 
 // Delete implements PzemService.
-func (service *pzemService) Delete(Id int64) helpers.Response {
+func (service *pzemService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "PzemService.Delete")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum dihapus
-	_, findErr := service.pzemRepo.GetById(Id)
+	_, findErr := service.pzemRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
 	}
 
 	// Data ditemukan, lakukan penghapusan
-	err := service.pzemRepo.Delete(Id)
+	err := service.pzemRepo.Delete(ctx, Id)
 	if err != nil {
-		log.Printf("ERROR: Gagal menghapus data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal menghapus data sensor dengan id : %d", Id)
-	} else {
-		// Jika penghapusan berhasil
-		log.Println("SUCCESS: Data sensor berhasil dihapus")
-		response.Status = 200
-		response.Messages = "Data sensor berhasil dihapus"
+		service.logger.ErrorContext(ctx, "Gagal menghapus data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorDeleteFailed, Id), err)
 	}
 
-	return response
+	// Jika penghapusan berhasil
+	service.logger.InfoContext(ctx, "Data sensor berhasil dihapus")
+	response.SetMessage(ctx, i18n.SensorDeleted)
+
+	return response, nil
 }
 
 // GetAll implements PzemService.
-func (service *pzemService) GetAll() helpers.Response {
+func (service *pzemService) GetAll(ctx context.Context, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "PzemService.GetAll")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.pzemRepo.GetAll()
+	data, err := service.pzemRepo.GetAll(ctx, Quality)
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil seluruh data sensor : error %v", err)
-		response.Status = 500
-		response.Messages = "Gagal mengambil seluruh data sensor"
-	} else {
-		log.Println("SUCCESS: Berhasil mengambil semua data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengambil semua data sensor"
-		response.Data = data
-	}
-	return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data sensor", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil semua data sensor")
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
+}
+
+// Query implements PzemService.
+// Data yang kosong tetap dikembalikan sebagai daftar kosong.
+func (service *pzemService) Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "PzemService.Query")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.pzemRepo.Query(ctx, Query)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", Query.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+	if data == nil {
+		data = []models.Pzem{}
+	}
+
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
 }
 
 // GetById implements PzemService.
-func (service *pzemService) GetById(Id int64) helpers.Response {
+func (service *pzemService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "PzemService.GetById")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.pzemRepo.GetById(Id)
+	data, err := service.pzemRepo.GetById(ctx, Id)
 
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d", Id)
-			response.Status = 404
-			response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		} else {
-			log.Printf("ERROR: Gagal mengambil data sensor dengan id : %d, error: %v", Id, err)
-			response.Status = 500
-			response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan id : %d", Id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
 		}
-	} else {
-		// Konversi string ke tipe data float64
-		Tegangan, _ := strconv.ParseFloat(data.Tegangan, 64)
-		Arus, _ := strconv.ParseFloat(data.Arus, 64)
-		Daya, _ := strconv.ParseFloat(data.Daya, 64)
 
-		data.Tegangan = fmt.Sprintf("%.2f", Tegangan)
-		data.Arus = fmt.Sprintf("%.2f", Arus)
-		data.Daya = fmt.Sprintf("%.2f", Daya)
-
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Data = data
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
 	}
 
-	return response
+	// Konversi string ke tipe data float64
+	Tegangan, _ := strconv.ParseFloat(data.Tegangan, 64)
+	Arus, _ := strconv.ParseFloat(data.Arus, 64)
+	Daya, _ := strconv.ParseFloat(data.Daya, 64)
+
+	data.Tegangan = fmt.Sprintf("%.2f", Tegangan)
+	data.Arus = fmt.Sprintf("%.2f", Arus)
+	data.Daya = fmt.Sprintf("%.2f", Daya)
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "id", Id)
+	response.SetMessage(ctx, i18n.SensorFoundByID, Id)
+	response.Data = data
+
+	return response, nil
 }
 
 // GetByToken implements PzemService.
-func (service *pzemService) GetByToken(DeviceToken string) helpers.Response {
+func (service *pzemService) GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "PzemService.GetByToken")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.pzemRepo.GetByToken(DeviceToken)
+	data, err := service.pzemRepo.GetByToken(ctx, DeviceToken, Quality)
 
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil data sensor dengan token : %s, error: %v", DeviceToken, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan token : %s", DeviceToken)
-		return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByTokenFail, DeviceToken), err)
 	}
 
 	var foundData []models.Pzem
@@ -139,57 +248,113 @@ func (service *pzemService) GetByToken(DeviceToken string) helpers.Response {
 	}
 
 	if len(foundData) == 0 {
-		log.Printf("INFO: Tidak menemukan data sensor dengan token : %s", DeviceToken)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan token : %s", DeviceToken)
-	} else {
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Data = foundData
+		service.logger.InfoContext(ctx, "Tidak menemukan data sensor", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByToken, DeviceToken))
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorFoundByToken, DeviceToken)
+	response.Data = foundData
+
+	return response, nil
 }
 
 // Update implements PzemService.
-func (service *pzemService) Update(Id int64, pzem models.Pzem) helpers.Response {
+func (service *pzemService) Update(ctx context.Context, Id int64, pzem models.Pzem) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "PzemService.Update")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum di update
-	_, findErr := service.pzemRepo.GetById(Id)
+	current, findErr := service.pzemRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
+	}
+
+	// Nilai baru dikalibrasi dengan kalibrasi yang berlaku pada waktu data
+	// tersebut dibuat, sama seperti saat data disimpan pertama kali
+	raw, err := service.calibrationService.Calibrator(ctx, "pzem").Apply(current.DeviceToken, current.CreatedAt, &pzem)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", current.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+	pzem.RawValues = raw
+	if raw != nil {
+		pzem.Quality = helpers.AssessQuality(models.Fields, &pzem).Quality
 	}
 
 	// Data ditemukan, lakukan update
-	err := service.pzemRepo.Update(Id, pzem)
+	err = service.pzemRepo.Update(ctx, Id, pzem)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengubah data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+
+	// Jika update data berhasil
+	service.logger.InfoContext(ctx, "Berhasil mengubah data sensor")
+	response.SetMessage(ctx, i18n.SensorUpdated)
+
+	return response, nil
+}
+
+// Export implements PzemService.
+func (service *pzemService) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "PzemService.Export")
+	defer span.End()
+
+	// Writer baru dibuat setelah baris pertama terbaca sehingga kegagalan
+	// query tidak membuat header response terkirim lebih dulu.
+	var writer helpers.ExportWriter
+	open := func() (err error) {
+		if writer != nil {
+			return nil
+		}
+		if writer, err = helpers.NewExportWriter(Format, w, "pzem"); err != nil {
+			return err
+		}
+		return writer.WriteRow(helpers.ExportHeaders(Units.Fields()))
+	}
+
+	count := 0
+	err := service.pzemRepo.Export(ctx, DeviceToken, Start, End, Quality, func(data models.Pzem) error {
+		if err := open(); err != nil {
+			return err
+		}
+		count++
+		Units.Apply(&data)
+		return writer.WriteRow([]string{strconv.FormatInt(data.Id, 10), data.DeviceToken, data.CreatedAt.Format(time.RFC3339), data.Tegangan, data.Arus, data.Daya, data.Quality})
+	})
 	if err != nil {
-		log.Printf("ERROR: Gagal mengubah data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengubah data sensor dengan id : %d", Id)
-	} else {
-		// Jika update data berhasil
-		log.Println("SUCCESS: Berhasil mengubah data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengubah data sensor"
+		service.logger.ErrorContext(ctx, "Gagal mengekspor data sensor", "device_token", DeviceToken, logging.Error(err))
+		return err
+	}
+	if err := open(); err != nil {
+		return err
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengekspor data sensor", "count", count, "device_token", DeviceToken)
+	return writer.Close()
 }
 
 type PzemService interface {
-	Create(pzem models.Pzem) helpers.Response
-	Update(Id int64, pzem models.Pzem) helpers.Response
-	Delete(Id int64) helpers.Response
-	GetById(Id int64) helpers.Response
-	GetByToken(DeviceToken string) helpers.Response
-	GetAll() helpers.Response
+	Create(ctx context.Context, pzem models.Pzem) (helpers.Response, error)
+	CreateBatch(ctx context.Context, pzem []models.Pzem) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, pzem models.Pzem) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error)
+	GetAll(ctx context.Context, Quality []string) (helpers.Response, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error
 }
 
-func NewPzemService(db *gorm.DB) PzemService {
-	return &pzemService{pzemRepo: repositories.NewPzemRepository(db)}
+func NewPzemService(db *gorm.DB, logger *slog.Logger) PzemService {
+	return &pzemService{logger: logger.With("sensor", "pzem"), pzemRepo: repositories.NewPzemRepository(db, logger), anomalyService: anomalyServices.NewAnomalyService(db, logger), calibrationService: calibrationServices.NewCalibrationService(db, logger)}
 }
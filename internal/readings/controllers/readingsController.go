@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/readings/models"
+	"iot-golang/internal/readings/services"
+	"iot-golang/internal/sensors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// SensorController adalah handler API v1 yang dimiliki setiap controller
+// sensor. Route v2 meneruskan request ke handler ini agar validasi, kualitas
+// data dan kalibrasi tetap berada di satu tempat.
+type SensorController interface {
+	Create(c echo.Context) error
+	GetAll(c echo.Context) error
+	GetById(c echo.Context) error
+	GetByToken(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type ReadingsController struct {
+	readingsService services.ReadingsService
+	controllers     map[string]SensorController
+}
+
+func (controller ReadingsController) List(c echo.Context) error {
+	sensor, sensorController, err := controller.sensor(c)
+	if err != nil {
+		return err
+	}
+
+	if c.QueryParam("device_token") == "" {
+		return withReadingIds(c, sensor.Name, sensorController.GetAll)
+	}
+
+	// Perangkat tanpa data adalah daftar kosong, bukan resource yang tidak ada
+	err = withReadingIds(c, sensor.Name, sensorController.GetByToken)
+	var appError *helpers.Error
+	if errors.As(err, &appError) && appError.Kind == helpers.KindNotFound {
+		response := helpers.Response{Data: []interface{}{}}
+		response.SetMessage(c.Request().Context(), i18n.SensorListed)
+		return response.JSON(c, http.StatusOK)
+	}
+
+	return err
+}
+
+func (controller ReadingsController) Create(c echo.Context) error {
+	_, sensorController, err := controller.sensor(c)
+	if err != nil {
+		return err
+	}
+
+	return sensorController.Create(c)
+}
+
+func (controller ReadingsController) Get(c echo.Context) error {
+	sensor, sensorController, err := controller.sensor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := readingId(c)
+	if err != nil {
+		return err
+	}
+
+	// Handler v1 membaca id dari query string
+	request := c.Request().Clone(c.Request().Context())
+	query := request.URL.Query()
+	query.Set("id", strconv.FormatInt(id, 10))
+	request.URL.RawQuery = query.Encode()
+
+	return withReadingIds(c, sensor.Name, func(c echo.Context) error {
+		return sensorController.GetById(forward(c, request, id))
+	})
+}
+
+func (controller ReadingsController) Update(c echo.Context) error {
+	sensor, sensorController, err := controller.sensor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := readingId(c)
+	if err != nil {
+		return err
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request().Body).Decode(&patch); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	merged, err := controller.readingsService.Merge(c.Request().Context(), sensor, id, patch)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+
+	// Hasil penggabungan dikirim ke handler update v1 sebagai body lengkap
+	request := c.Request().Clone(c.Request().Context())
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.ContentLength = int64(len(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	return sensorController.Update(forward(c, request, id))
+}
+
+func (controller ReadingsController) Delete(c echo.Context) error {
+	_, sensorController, err := controller.sensor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := readingId(c)
+	if err != nil {
+		return err
+	}
+
+	return sensorController.Delete(forward(c, c.Request(), id))
+}
+
+// GetReading, UpdateReading dan DeleteReading melayani route /readings/:id
+// dengan reading_id yang dikirim pada response v2, misalnya bmp-42.
+func (controller ReadingsController) GetReading(c echo.Context) error {
+	if err := splitReadingId(c); err != nil {
+		return err
+	}
+
+	return controller.Get(c)
+}
+
+func (controller ReadingsController) UpdateReading(c echo.Context) error {
+	if err := splitReadingId(c); err != nil {
+		return err
+	}
+
+	return controller.Update(c)
+}
+
+func (controller ReadingsController) DeleteReading(c echo.Context) error {
+	if err := splitReadingId(c); err != nil {
+		return err
+	}
+
+	return controller.Delete(c)
+}
+
+// splitReadingId memecah id <sensor>-<id> menjadi parameter path type dan id
+// agar handler per sensor dapat dipakai tanpa perubahan.
+func splitReadingId(c echo.Context) error {
+	sensor, id, ok := models.SplitReadingId(c.Param("id"))
+	if !ok {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"id": i18n.T(c.Request().Context(), i18n.FieldReadingId, "id")})
+	}
+
+	c.SetParamNames("type", "id")
+	c.SetParamValues(sensor, id)
+	return nil
+}
+
+// sensor mencari jenis sensor dari parameter path type.
+func (controller ReadingsController) sensor(c echo.Context) (sensors.Sensor, SensorController, error) {
+	sensor, ok := sensors.Find(c.Param("type"))
+	sensorController, registered := controller.controllers[sensor.Name]
+	if !ok || !registered {
+		return sensor, nil, helpers.NotFound(i18n.Msg(i18n.SensorUnknown, c.Param("type")))
+	}
+
+	return sensor, sensorController, nil
+}
+
+// readingId membaca id data dari path. Berbeda dengan v1, id yang bukan
+// bilangan bulat positif ditolak dengan status 400.
+func readingId(c echo.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"id": i18n.T(c.Request().Context(), i18n.FieldInteger, "id")})
+	}
+
+	return id, nil
+}
+
+// withReadingIds menjalankan handler v1 lalu menambahkan reading_id pada
+// setiap data di response sukses, sehingga client bisa memakai route
+// /readings/:id.
+func withReadingIds(c echo.Context, sensor string, handler echo.HandlerFunc) error {
+	response := c.Response()
+	recorder := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+	c.SetResponse(echo.NewResponse(recorder, c.Echo()))
+	err := handler(c)
+	c.SetResponse(response)
+	if err != nil {
+		return err
+	}
+
+	body := recorder.body.Bytes()
+	if recorder.status < http.StatusMultipleChoices {
+		if body, err = addReadingIds(body, sensor); err != nil {
+			return err
+		}
+	}
+
+	for key, values := range recorder.header {
+		response.Header()[key] = values
+	}
+	response.WriteHeader(recorder.status)
+	_, err = response.Write(body)
+	return err
+}
+
+// addReadingIds menambahkan reading_id pada data di envelope helpers.Response,
+// baik berupa satu data maupun daftar data.
+func addReadingIds(body []byte, sensor string) ([]byte, error) {
+	var envelope struct {
+		helpers.Response
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+
+	data := bytes.TrimSpace(envelope.Data)
+	var err error
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		for i := range items {
+			if items[i], err = addReadingId(items[i], sensor); err != nil {
+				return nil, err
+			}
+		}
+		data, err = json.Marshal(items)
+	case bytes.HasPrefix(data, []byte("{")):
+		data, err = addReadingId(data, sensor)
+	default:
+		return body, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := envelope.Response
+	response.Data = json.RawMessage(data)
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, '\n'), nil
+}
+
+// addReadingId menambahkan reading_id sebagai field pertama satu data.
+func addReadingId(item json.RawMessage, sensor string) (json.RawMessage, error) {
+	var reading struct {
+		Id int64 `json:"id"`
+	}
+	if err := json.Unmarshal(item, &reading); err != nil {
+		return nil, err
+	}
+	if reading.Id == 0 {
+		return item, nil
+	}
+
+	readingId, err := json.Marshal(models.ReadingId(sensor, reading.Id))
+	if err != nil {
+		return nil, err
+	}
+	withId := append([]byte(`{"reading_id":`), readingId...)
+	withId = append(withId, ',')
+	return append(withId, bytes.TrimSpace(item)[1:]...), nil
+}
+
+// responseRecorder menampung response handler v1 agar bisa diubah sebelum
+// dikirim ke client.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (recorder *responseRecorder) Header() http.Header {
+	return recorder.header
+}
+
+func (recorder *responseRecorder) Write(b []byte) (int, error) {
+	return recorder.body.Write(b)
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	recorder.status = status
+}
+
+// forward menyiapkan context echo baru untuk handler v1 dengan request dan
+// parameter path id. Response tetap ditulis ke response asli.
+func forward(c echo.Context, request *http.Request, id int64) echo.Context {
+	ctx := c.Echo().NewContext(request, c.Response())
+	ctx.SetPath(c.Path())
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.FormatInt(id, 10))
+	return ctx
+}
+
+func NewReadingsController(db *gorm.DB, controllers map[string]SensorController, logger *slog.Logger) ReadingsController {
+	controller := ReadingsController{
+		readingsService: services.NewReadingsService(db, logger),
+		controllers:     controllers,
+	}
+
+	return controller
+}
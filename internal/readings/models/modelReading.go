@@ -0,0 +1,26 @@
+package models
+
+import (
+	thmModels "iot-golang/internal/thm/models"
+	"strconv"
+	"strings"
+)
+
+// Reading adalah bentuk data sensor pada response API v2, yaitu model sensor
+// ditambah ReadingId. Schema thm dipakai sebagai contoh pada dokumentasi.
+type Reading struct {
+	ReadingId string `json:"reading_id"`
+	thmModels.Thm
+}
+
+// ReadingId mengembalikan id data untuk route /readings/:id dengan format
+// <sensor>-<id>, karena id data hanya unik di dalam tabel satu sensor.
+func ReadingId(Sensor string, Id int64) string {
+	return Sensor + "-" + strconv.FormatInt(Id, 10)
+}
+
+// SplitReadingId memecah id <sensor>-<id> menjadi nama sensor dan id data.
+func SplitReadingId(ReadingId string) (string, string, bool) {
+	sensor, id, ok := strings.Cut(ReadingId, "-")
+	return sensor, id, ok && sensor != ""
+}
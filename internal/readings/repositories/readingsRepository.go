@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type dbReadings struct {
+	Conn *gorm.DB
+}
+
+// GetValues implements ReadingsRepository.
+func (db *dbReadings) GetValues(ctx context.Context, Table string, Columns []string, Id int64) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "readings", "GetValues")).Table(Table).Select(Columns).Where("id = ?", Id).Take(&data)
+	return data, result.Error
+}
+
+type ReadingsRepository interface {
+	GetValues(ctx context.Context, Table string, Columns []string, Id int64) (map[string]interface{}, error)
+}
+
+func NewReadingsRepository(Conn *gorm.DB, logger *slog.Logger) ReadingsRepository {
+	return &dbReadings{Conn: logging.Session(Conn, logger.With("repository", "readings"))}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/readings/repositories"
+	"iot-golang/internal/sensors"
+	"iot-golang/internal/tracing"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type readingsService struct {
+	logger       *slog.Logger
+	readingsRepo repositories.ReadingsRepository
+}
+
+// Merge implements ReadingsService.
+// Field yang tidak dikirim pada Patch diisi dengan nilai yang tersimpan,
+// sehingga hasilnya bisa divalidasi ulang dengan aturan update yang lengkap.
+// Field yang sudah dikalibrasi diisi dengan nilai mentahnya karena update
+// mengkalibrasi ulang seluruh nilai.
+func (service *readingsService) Merge(ctx context.Context, Sensor sensors.Sensor, Id int64, Patch map[string]json.RawMessage) (map[string]interface{}, error) {
+	ctx, span := tracing.Start(ctx, "ReadingsService.Merge")
+	defer span.End()
+
+	columns := []string{"raw_values"}
+	known := make(map[string]bool, len(Sensor.Fields))
+	for _, field := range Sensor.Fields {
+		columns = append(columns, field.Column)
+		known[field.Name] = true
+	}
+
+	errorList := make(map[string]string)
+	for name := range Patch {
+		if !known[name] {
+			errorList[name] = i18n.T(ctx, i18n.FieldInvalid, name)
+		}
+	}
+	if len(errorList) > 0 {
+		return nil, helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	values, err := service.readingsRepo.GetValues(ctx, Sensor.Table, columns, Id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "sensor", Sensor.Name, "id", Id)
+		return nil, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+	}
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "sensor", Sensor.Name, "id", Id, logging.Error(err))
+		return nil, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
+	}
+
+	raw, err := helpers.RawValues(values["raw_values"])
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membaca nilai mentah data sensor", "sensor", Sensor.Name, "id", Id, logging.Error(err))
+		return nil, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
+	}
+
+	merged := make(map[string]interface{}, len(Sensor.Fields))
+	for _, field := range Sensor.Fields {
+		merged[field.Name] = values[field.Column]
+		if value, ok := raw[field.Name]; ok {
+			merged[field.Name] = value
+			// raw_values menyimpan teks, field bilangan bulat dikirim sebagai angka
+			if field.Integer {
+				number, ok := helpers.ToFloat(value)
+				if !ok {
+					err := fmt.Errorf("nilai mentah %s %q bukan angka", field.Name, value)
+					service.logger.ErrorContext(ctx, "Gagal membaca nilai mentah data sensor", "sensor", Sensor.Name, "id", Id, logging.Error(err))
+					return nil, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
+				}
+				merged[field.Name] = field.Value(number)
+			}
+		}
+		if value, ok := Patch[field.Name]; ok {
+			merged[field.Name] = value
+		}
+	}
+	return merged, nil
+}
+
+type ReadingsService interface {
+	Merge(ctx context.Context, Sensor sensors.Sensor, Id int64, Patch map[string]json.RawMessage) (map[string]interface{}, error)
+}
+
+func NewReadingsService(db *gorm.DB, logger *slog.Logger) ReadingsService {
+	return &readingsService{logger: logger, readingsRepo: repositories.NewReadingsRepository(db, logger)}
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/retention/models"
+	"iot-golang/internal/retention/services"
+	"iot-golang/internal/sensors"
+	"log/slog"
+	"net/http"
+
+	v1 "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type RetentionController struct {
+	retentionService services.RetentionService
+	retentionJob     *services.Job
+	validate         v1.Validate
+}
+
+func (controller RetentionController) GetPolicies(c echo.Context) error {
+	result, err := controller.retentionService.GetPolicies(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller RetentionController) SavePolicy(c echo.Context) error {
+	payloadValidator := new(models.PolicyPayload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
+	}
+
+	err := controller.validate.Struct(payloadValidator)
+	if err != nil {
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator, helpers.ValidationMessages{"min": i18n.FieldNotNegative})
+
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	result, err := controller.retentionService.SavePolicy(c.Request().Context(), models.RetentionPolicy{Sensor: c.Param("sensor"), RawDays: payloadValidator.RawDays, HourlyMonths: payloadValidator.HourlyMonths, DailyMonths: payloadValidator.DailyMonths})
+	if err != nil {
+		return err
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller RetentionController) GetStatus(c echo.Context) error {
+	response := helpers.Response{Data: controller.retentionJob.Status()}
+	response.SetMessage(c.Request().Context(), i18n.RetentionStatus)
+	return response.JSON(c, http.StatusOK)
+}
+
+func (controller RetentionController) Run(c echo.Context) error {
+	if !controller.retentionJob.Trigger() {
+		return helpers.Conflict(i18n.Msg(i18n.RetentionRunning), nil)
+	}
+
+	var response helpers.Response
+	response.SetMessage(c.Request().Context(), i18n.RetentionTriggered)
+	return response.JSON(c, http.StatusAccepted)
+}
+
+func (controller RetentionController) GetHistory(c echo.Context) error {
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	// Sensor yang tidak dikenali ditolak oleh service, satuan cukup dibaca dari field yang ada
+	sensor, _ := sensors.Find(c.QueryParam("sensor"))
+	units, err := helpers.ParseUnits(sensor.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	result, err := controller.retentionService.GetHistory(c.Request().Context(), c.QueryParam("sensor"), c.QueryParam("device_token"), start, end)
+	if err != nil {
+		return err
+	}
+
+	result.Units = units.Map()
+	if points, ok := result.Data.([]models.HistoryPoint); ok {
+		for _, point := range points {
+			units.ConvertValues(point.Values)
+			units.ConvertValues(point.Min)
+			units.ConvertValues(point.Max)
+		}
+	}
+
+	return result.JSON(c, http.StatusOK)
+}
+
+func NewRetentionController(db *gorm.DB, job *services.Job, logger *slog.Logger) RetentionController {
+	controller := RetentionController{
+		retentionService: services.NewRetentionService(db, logger),
+		retentionJob:     job,
+		validate:         *v1.New(),
+	}
+
+	return controller
+}
@@ -0,0 +1,131 @@
+package models
+
+import "time"
+
+const (
+	ResolutionRaw  = "raw"
+	ResolutionHour = "hour"
+	ResolutionDay  = "day"
+)
+
+// RetentionPolicy mengatur lama penyimpanan data mentah dan rollup per jenis sensor.
+// Nilai 0 berarti data disimpan selamanya.
+type RetentionPolicy struct {
+	Id           int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor       string    `json:"sensor" gorm:"column:sensor"`
+	RawDays      int       `json:"raw_days" gorm:"column:raw_days"`
+	HourlyMonths int       `json:"hourly_months" gorm:"column:hourly_months"`
+	DailyMonths  int       `json:"daily_months" gorm:"column:daily_months"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (RetentionPolicy) TableName() string {
+	return "db_retention_policy"
+}
+
+// RawCutoff mengembalikan batas waktu data mentah. Batas dibulatkan ke awal
+// hari UTC, sama seperti bucket rollup, agar bucket jam dan hari selalu lengkap
+// saat data mentahnya dihapus.
+func (policy RetentionPolicy) RawCutoff(now time.Time) (time.Time, bool) {
+	if policy.RawDays <= 0 {
+		return time.Time{}, false
+	}
+	return now.UTC().AddDate(0, 0, -policy.RawDays).Truncate(24 * time.Hour), true
+}
+
+// HourlyCutoff mengembalikan batas waktu rollup per jam.
+func (policy RetentionPolicy) HourlyCutoff(now time.Time) (time.Time, bool) {
+	if policy.HourlyMonths <= 0 {
+		return time.Time{}, false
+	}
+	return now.UTC().AddDate(0, -policy.HourlyMonths, 0).Truncate(24 * time.Hour), true
+}
+
+// DailyCutoff mengembalikan batas waktu rollup per hari.
+func (policy RetentionPolicy) DailyCutoff(now time.Time) (time.Time, bool) {
+	if policy.DailyMonths <= 0 {
+		return time.Time{}, false
+	}
+	return now.UTC().AddDate(0, -policy.DailyMonths, 0).Truncate(24 * time.Hour), true
+}
+
+// Rollup menyimpan agregat satu field sensor untuk satu bucket waktu.
+type Rollup struct {
+	Id          int64     `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor      string    `json:"sensor" gorm:"column:sensor"`
+	DeviceToken string    `json:"device_token" gorm:"column:device_token"`
+	Field       string    `json:"field" gorm:"column:field"`
+	Resolution  string    `json:"resolution" gorm:"column:resolution"`
+	BucketStart time.Time `json:"bucket_start" gorm:"column:bucket_start"`
+	Count       int64     `json:"count" gorm:"column:count"`
+	MinValue    float64   `json:"min" gorm:"column:min_value"`
+	MaxValue    float64   `json:"max" gorm:"column:max_value"`
+	AvgValue    float64   `json:"avg" gorm:"column:avg_value"`
+}
+
+func (Rollup) TableName() string {
+	return "db_sensor_rollup"
+}
+
+// RollupCount menyimpan jumlah data mentah yang diterima satu perangkat pada
+// satu bucket waktu, termasuk data bad yang tidak ikut dihitung pada Rollup.
+// Dipakai laporan kelengkapan data setelah data mentah dihapus.
+type RollupCount struct {
+	Id          int64     `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor      string    `json:"sensor" gorm:"column:sensor"`
+	DeviceToken string    `json:"device_token" gorm:"column:device_token"`
+	Resolution  string    `json:"resolution" gorm:"column:resolution"`
+	BucketStart time.Time `json:"bucket_start" gorm:"column:bucket_start"`
+	Count       int64     `json:"count" gorm:"column:count"`
+}
+
+func (RollupCount) TableName() string {
+	return "db_sensor_rollup_count"
+}
+
+// Quarantine menyimpan satu data sensor tanpa created_at. Data tersebut tidak
+// masuk ke rentang waktu mana pun sehingga dipindahkan dari tabel sensor agar
+// tidak tersimpan selamanya. Data berisi seluruh kolom dalam bentuk JSON.
+type Quarantine struct {
+	Id            int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Sensor        string    `json:"sensor" gorm:"column:sensor"`
+	SourceId      int64     `json:"source_id" gorm:"column:source_id"`
+	Data          string    `json:"data" gorm:"column:data"`
+	QuarantinedAt time.Time `json:"quarantined_at" gorm:"column:quarantined_at"`
+}
+
+func (Quarantine) TableName() string {
+	return "db_sensor_quarantine"
+}
+
+// JobStatus berisi status terakhir job retention.
+type JobStatus struct {
+	Running        bool             `json:"running"`
+	Runs           int              `json:"runs"`
+	LastStartedAt  *time.Time       `json:"last_started_at"`
+	LastFinishedAt *time.Time       `json:"last_finished_at"`
+	LastDuration   string           `json:"last_duration"`
+	LastError      string           `json:"last_error"`
+	NextRunAt      *time.Time       `json:"next_run_at"`
+	Sensors        map[string]Stats `json:"sensors"`
+}
+
+// Stats berisi jumlah data yang diproses untuk satu sensor pada run terakhir.
+type Stats struct {
+	RollupRows    int64 `json:"rollup_rows"`
+	RawDeleted    int64 `json:"raw_deleted"`
+	Quarantined   int64 `json:"quarantined"`
+	HourlyDeleted int64 `json:"hourly_deleted"`
+	DailyDeleted  int64 `json:"daily_deleted"`
+}
+
+// HistoryPoint adalah satu titik data riwayat, baik dari data mentah maupun rollup.
+type HistoryPoint struct {
+	Time        time.Time          `json:"time"`
+	DeviceToken string             `json:"device_token"`
+	Resolution  string             `json:"resolution"`
+	Count       int64              `json:"count"`
+	Values      map[string]float64 `json:"values"`
+	Min         map[string]float64 `json:"min,omitempty"`
+	Max         map[string]float64 `json:"max,omitempty"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// PolicyPayload adalah payload untuk menyimpan kebijakan retensi satu sensor.
+type PolicyPayload struct {
+	RawDays      int `json:"raw_days" validate:"min=0"`
+	HourlyMonths int `json:"hourly_months" validate:"min=0"`
+	DailyMonths  int `json:"daily_months" validate:"min=0"`
+}
@@ -0,0 +1,332 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"iot-golang/config"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
+	"iot-golang/internal/retention/models"
+	"iot-golang/internal/sensors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const rollupBatchSize = 500
+
+type dbRetention struct {
+	Conn *gorm.DB
+}
+
+type rollupCountRow struct {
+	DeviceToken string `gorm:"column:device_token"`
+	Bucket      int64  `gorm:"column:bucket"`
+	Count       int64  `gorm:"column:count"`
+}
+
+type rollupRow struct {
+	DeviceToken string  `gorm:"column:device_token"`
+	Bucket      int64   `gorm:"column:bucket"`
+	Count       int64   `gorm:"column:count"`
+	MinValue    float64 `gorm:"column:min_value"`
+	MaxValue    float64 `gorm:"column:max_value"`
+	AvgValue    float64 `gorm:"column:avg_value"`
+}
+
+// GetPolicies implements RetentionRepository.
+func (db *dbRetention) GetPolicies(ctx context.Context) ([]models.RetentionPolicy, error) {
+	var data []models.RetentionPolicy
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "retention", "GetPolicies")).Find(&data)
+	return data, result.Error
+}
+
+// SavePolicy implements RetentionRepository.
+func (db *dbRetention) SavePolicy(ctx context.Context, policy models.RetentionPolicy) error {
+	return config.Upsert(db.Conn.WithContext(metrics.WithQuery(ctx, "retention", "SavePolicy")), &policy, []string{"sensor"}, []string{"raw_days", "hourly_months", "daily_months", "updated_at"})
+}
+
+// rollupMerge menggabungkan agregat baru dengan bucket rollup yang sudah ada,
+// misalnya dari data import yang masuk setelah bucket tersebut di-rollup.
+// Rata-rata dihitung ulang dengan bobot count, count diperbarui paling akhir
+// karena dipakai oleh ekspresi avg_value.
+func rollupMerge(tx *gorm.DB) []clause.Assignment {
+	table := models.Rollup{}.TableName()
+	old := func(column string) string { return table + "." + column }
+	incoming := func(column string) string { return config.Excluded(tx, column) }
+
+	avg := fmt.Sprintf("(%s * %s + %s * %s) / (%s + %s)", old("avg_value"), old("count"), incoming("avg_value"), incoming("count"), old("count"), incoming("count"))
+	return []clause.Assignment{
+		{Column: clause.Column{Name: "avg_value"}, Value: clause.Expr{SQL: avg}},
+		{Column: clause.Column{Name: "min_value"}, Value: clause.Expr{SQL: config.Least(tx, old("min_value"), incoming("min_value"))}},
+		{Column: clause.Column{Name: "max_value"}, Value: clause.Expr{SQL: config.Greatest(tx, old("max_value"), incoming("max_value"))}},
+		{Column: clause.Column{Name: "count"}, Value: clause.Expr{SQL: fmt.Sprintf("%s + %s", old("count"), incoming("count"))}},
+	}
+}
+
+// rollup menghitung agregat setiap field dari data mentah sebelum batas waktu
+// lalu menyimpannya ke tabel rollup. Data berkualitas bad tidak ikut dihitung
+// agar nilai yang mustahil tidak merusak agregat. Bucket yang sudah ada
+// digabung dengan agregat baru, bukan ditimpa.
+func (db *dbRetention) rollup(tx *gorm.DB, sensor sensors.Sensor, resolution string, interval time.Duration, before time.Time) (int64, error) {
+	var total int64
+	bucket := config.TimeBucket(tx, "created_at", interval)
+
+	for _, field := range sensor.Fields {
+		value := config.CastNumeric(tx, field.Column)
+		selectSQL := fmt.Sprintf("device_token, %s AS bucket, COUNT(%s) AS count, MIN(%s) AS min_value, MAX(%s) AS max_value, AVG(%s) AS avg_value", bucket, value, value, value, value)
+
+		var rows []rollupRow
+		err := tx.Table(sensor.Table).Select(selectSQL).Where("created_at < ? AND quality <> ?", before, helpers.QualityBad).Group("device_token, bucket").Scan(&rows).Error
+		if err != nil {
+			return total, err
+		}
+
+		var rollups []models.Rollup
+		for _, row := range rows {
+			if row.Count == 0 {
+				continue
+			}
+			rollups = append(rollups, models.Rollup{
+				Sensor:      sensor.Name,
+				DeviceToken: row.DeviceToken,
+				Field:       field.Name,
+				Resolution:  resolution,
+				BucketStart: time.Unix(row.Bucket, 0).UTC(),
+				Count:       row.Count,
+				MinValue:    row.MinValue,
+				MaxValue:    row.MaxValue,
+				AvgValue:    row.AvgValue,
+			})
+		}
+
+		for start := 0; start < len(rollups); start += rollupBatchSize {
+			end := start + rollupBatchSize
+			if end > len(rollups) {
+				end = len(rollups)
+			}
+			batch := rollups[start:end]
+			err := config.UpsertExpr(tx, &batch, []string{"sensor", "device_token", "field", "resolution", "bucket_start"}, rollupMerge(tx))
+			if err != nil {
+				return total, err
+			}
+		}
+		total += int64(len(rollups))
+	}
+
+	return total, nil
+}
+
+// rollupCount menyimpan jumlah seluruh data mentah per bucket sebelum batas
+// waktu, termasuk data bad. Bucket yang sudah ada ditambah dengan jumlah baru.
+func (db *dbRetention) rollupCount(tx *gorm.DB, sensor sensors.Sensor, resolution string, interval time.Duration, before time.Time) error {
+	bucket := config.TimeBucket(tx, "created_at", interval)
+
+	var rows []rollupCountRow
+	err := tx.Table(sensor.Table).Select(fmt.Sprintf("device_token, %s AS bucket, COUNT(*) AS count", bucket)).Where("created_at < ?", before).Group("device_token, bucket").Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	counts := make([]models.RollupCount, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, models.RollupCount{
+			Sensor:      sensor.Name,
+			DeviceToken: row.DeviceToken,
+			Resolution:  resolution,
+			BucketStart: time.Unix(row.Bucket, 0).UTC(),
+			Count:       row.Count,
+		})
+	}
+
+	table := models.RollupCount{}.TableName()
+	merge := []clause.Assignment{{Column: clause.Column{Name: "count"}, Value: clause.Expr{SQL: fmt.Sprintf("%s.count + %s", table, config.Excluded(tx, "count"))}}}
+	for start := 0; start < len(counts); start += rollupBatchSize {
+		end := start + rollupBatchSize
+		if end > len(counts) {
+			end = len(counts)
+		}
+		batch := counts[start:end]
+		if err := config.UpsertExpr(tx, &batch, []string{"sensor", "device_token", "resolution", "bucket_start"}, merge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RollupAndDelete implements RetentionRepository.
+// Rollup per jam dan per hari dihitung lalu data mentah dihapus dalam satu transaksi
+// sehingga data tidak hilang jika salah satu langkah gagal.
+func (db *dbRetention) RollupAndDelete(ctx context.Context, sensor sensors.Sensor, before time.Time) (int64, int64, error) {
+	var rolled, deleted int64
+
+	err := db.Conn.WithContext(metrics.WithQuery(ctx, "retention", "RollupAndDelete")).Transaction(func(tx *gorm.DB) error {
+		hourly, err := db.rollup(tx, sensor, models.ResolutionHour, time.Hour, before)
+		if err != nil {
+			return err
+		}
+		daily, err := db.rollup(tx, sensor, models.ResolutionDay, 24*time.Hour, before)
+		if err != nil {
+			return err
+		}
+		if err := db.rollupCount(tx, sensor, models.ResolutionHour, time.Hour, before); err != nil {
+			return err
+		}
+		if err := db.rollupCount(tx, sensor, models.ResolutionDay, 24*time.Hour, before); err != nil {
+			return err
+		}
+		rolled = hourly + daily
+
+		result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE created_at < ?", sensor.Table), before)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+
+	return rolled, deleted, err
+}
+
+// QuarantineUndated implements RetentionRepository.
+// Data tanpa created_at tidak pernah memenuhi syarat rollup maupun batas
+// retensi, sehingga dipindahkan ke tabel karantina dalam satu transaksi.
+func (db *dbRetention) QuarantineUndated(ctx context.Context, sensor sensors.Sensor, now time.Time) (int64, error) {
+	var moved int64
+
+	err := db.Conn.WithContext(metrics.WithQuery(ctx, "retention", "QuarantineUndated")).Transaction(func(tx *gorm.DB) error {
+		var rows []map[string]interface{}
+		if err := tx.Table(sensor.Table).Where("created_at IS NULL").Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		quarantines := make([]models.Quarantine, 0, len(rows))
+		ids := make([]int64, 0, len(rows))
+		for _, row := range rows {
+			id, ok := helpers.ToFloat(row["id"])
+			if !ok {
+				return fmt.Errorf("id data %v tidak dapat dibaca", row["id"])
+			}
+			// Driver dapat mengembalikan kolom teks sebagai []byte yang
+			// akan menjadi base64 bila langsung di-encode
+			for column, value := range row {
+				if value, ok := value.([]byte); ok {
+					row[column] = string(value)
+				}
+			}
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			quarantines = append(quarantines, models.Quarantine{Sensor: sensor.Name, SourceId: int64(id), Data: string(data), QuarantinedAt: now})
+			ids = append(ids, int64(id))
+		}
+
+		if err := tx.CreateInBatches(&quarantines, rollupBatchSize).Error; err != nil {
+			return err
+		}
+		for start := 0; start < len(ids); start += rollupBatchSize {
+			end := start + rollupBatchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE created_at IS NULL AND id IN ?", sensor.Table), ids[start:end])
+			if result.Error != nil {
+				return result.Error
+			}
+			moved += result.RowsAffected
+		}
+		return nil
+	})
+
+	return moved, err
+}
+
+// DeleteRollups implements RetentionRepository.
+// Jumlah data per bucket ikut dihapus bersama rollup pada resolusi yang sama.
+func (db *dbRetention) DeleteRollups(ctx context.Context, Sensor string, Resolution string, before time.Time) (int64, error) {
+	var deleted int64
+	err := db.Conn.WithContext(metrics.WithQuery(ctx, "retention", "DeleteRollups")).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("sensor = ? AND resolution = ? AND bucket_start < ?", Sensor, Resolution, before).Delete(&models.Rollup{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return tx.Where("sensor = ? AND resolution = ? AND bucket_start < ?", Sensor, Resolution, before).Delete(&models.RollupCount{}).Error
+	})
+	return deleted, err
+}
+
+// GetRollups implements RetentionRepository.
+func (db *dbRetention) GetRollups(ctx context.Context, Sensor string, DeviceToken string, Resolution string, Start time.Time, End time.Time) ([]models.Rollup, error) {
+	var data []models.Rollup
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "retention", "GetRollups")).Where("sensor = ? AND resolution = ? AND bucket_start >= ? AND bucket_start < ?", Sensor, Resolution, Start, End)
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+	result := query.Order("bucket_start asc").Find(&data)
+	return data, result.Error
+}
+
+// GetRaw implements RetentionRepository.
+func (db *dbRetention) GetRaw(ctx context.Context, sensor sensors.Sensor, DeviceToken string, Start time.Time, End time.Time) ([]models.HistoryPoint, error) {
+	selectSQL := "created_at, device_token"
+	for _, field := range sensor.Fields {
+		selectSQL += fmt.Sprintf(", %s AS %s", config.CastNumeric(db.Conn, field.Column), field.Name)
+	}
+
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "retention", "GetRaw")).Table(sensor.Table).Select(selectSQL).Where("created_at >= ? AND created_at < ?", Start, End)
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+
+	rows, err := query.Order("created_at asc").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []models.HistoryPoint
+	for rows.Next() {
+		var createdAt sql.NullTime
+		var deviceToken string
+		values := make([]sql.NullFloat64, len(sensor.Fields))
+
+		dest := []interface{}{&createdAt, &deviceToken}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		point := models.HistoryPoint{Time: createdAt.Time, DeviceToken: deviceToken, Resolution: models.ResolutionRaw, Count: 1, Values: map[string]float64{}}
+		for i, field := range sensor.Fields {
+			if values[i].Valid {
+				point.Values[field.Name] = values[i].Float64
+			}
+		}
+		data = append(data, point)
+	}
+
+	return data, rows.Err()
+}
+
+type RetentionRepository interface {
+	GetPolicies(ctx context.Context) ([]models.RetentionPolicy, error)
+	SavePolicy(ctx context.Context, policy models.RetentionPolicy) error
+	RollupAndDelete(ctx context.Context, sensor sensors.Sensor, before time.Time) (int64, int64, error)
+	QuarantineUndated(ctx context.Context, sensor sensors.Sensor, now time.Time) (int64, error)
+	DeleteRollups(ctx context.Context, Sensor string, Resolution string, before time.Time) (int64, error)
+	GetRollups(ctx context.Context, Sensor string, DeviceToken string, Resolution string, Start time.Time, End time.Time) ([]models.Rollup, error)
+	GetRaw(ctx context.Context, sensor sensors.Sensor, DeviceToken string, Start time.Time, End time.Time) ([]models.HistoryPoint, error)
+}
+
+func NewRetentionRepository(Conn *gorm.DB, logger *slog.Logger) RetentionRepository {
+	return &dbRetention{Conn: logging.Session(Conn, logger.With("repository", "retention"))}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/retention/models"
+	"iot-golang/internal/tracing"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultJobInterval = time.Hour
+
+// Job menjalankan kebijakan retensi secara berkala di background.
+type Job struct {
+	logger   *slog.Logger
+	service  RetentionService
+	interval time.Duration
+	trigger  chan struct{}
+
+	mutex  sync.Mutex
+	status models.JobStatus
+}
+
+func NewJob(db *gorm.DB, logger *slog.Logger) *Job {
+	interval, err := time.ParseDuration(os.Getenv("RETENTION_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = defaultJobInterval
+	}
+
+	return &Job{
+		logger:   logger.With("component", "retention"),
+		service:  NewRetentionService(db, logger),
+		interval: interval,
+		trigger:  make(chan struct{}, 1),
+	}
+}
+
+// Name implements lifecycle.Worker.
+func (job *Job) Name() string {
+	return "retention"
+}
+
+// Run menjalankan job sampai context dibatalkan. Rollup yang sedang berjalan
+// tetap diselesaikan agar data tidak terhapus setengah jalan saat shutdown.
+func (job *Job) Run(ctx context.Context) error {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		next := time.Now().Add(job.interval)
+		job.mutex.Lock()
+		job.status.NextRunAt = &next
+		job.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-job.trigger:
+		}
+
+		job.runOnce(context.WithoutCancel(ctx))
+	}
+}
+
+// Trigger meminta job berjalan segera. Mengembalikan false jika job sedang
+// berjalan atau sudah ada permintaan yang menunggu.
+func (job *Job) Trigger() bool {
+	job.mutex.Lock()
+	running := job.status.Running
+	job.mutex.Unlock()
+	if running {
+		return false
+	}
+
+	select {
+	case job.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status mengembalikan salinan status job.
+func (job *Job) Status() models.JobStatus {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	return job.status
+}
+
+func (job *Job) runOnce(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "RetentionJob.Run")
+	defer span.End()
+
+	started := time.Now()
+	job.mutex.Lock()
+	job.status.Running = true
+	job.status.LastStartedAt = &started
+	job.mutex.Unlock()
+
+	stats, err := job.service.Apply(ctx, started)
+
+	finished := time.Now()
+	job.mutex.Lock()
+	job.status.Running = false
+	job.status.Runs++
+	job.status.LastFinishedAt = &finished
+	job.status.LastDuration = finished.Sub(started).String()
+	job.status.Sensors = stats
+	job.status.LastError = ""
+	if err != nil {
+		job.status.LastError = err.Error()
+	}
+	job.mutex.Unlock()
+
+	if err != nil {
+		job.logger.ErrorContext(ctx, "Job retensi gagal", logging.Duration(finished.Sub(started)), logging.Error(err))
+	} else {
+		job.logger.InfoContext(ctx, "Job retensi selesai", logging.Duration(finished.Sub(started)))
+	}
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/retention/models"
+	"iot-golang/internal/retention/repositories"
+	"iot-golang/internal/sensors"
+	"iot-golang/internal/tracing"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type retentionService struct {
+	logger        *slog.Logger
+	retentionRepo repositories.RetentionRepository
+	defaults      models.RetentionPolicy
+}
+
+func envInt(key string) int {
+	value, _ := strconv.Atoi(os.Getenv(key))
+	return value
+}
+
+// policies mengembalikan kebijakan setiap sensor, sensor tanpa kebijakan
+// tersimpan memakai nilai default dari environment.
+func (service *retentionService) policies(ctx context.Context) (map[string]models.RetentionPolicy, error) {
+	stored, err := service.retentionRepo.GetPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make(map[string]models.RetentionPolicy)
+	for _, sensor := range sensors.All {
+		policy := service.defaults
+		policy.Sensor = sensor.Name
+		policies[sensor.Name] = policy
+	}
+	for _, policy := range stored {
+		policies[policy.Sensor] = policy
+	}
+	return policies, nil
+}
+
+// GetPolicies implements RetentionService.
+func (service *retentionService) GetPolicies(ctx context.Context) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "RetentionService.GetPolicies")
+	defer span.End()
+
+	var response helpers.Response
+	policies, err := service.policies(ctx)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kebijakan retensi", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.RetentionPoliciesFailed), err)
+	}
+
+	var data []models.RetentionPolicy
+	for _, sensor := range sensors.All {
+		data = append(data, policies[sensor.Name])
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil kebijakan retensi")
+	response.SetMessage(ctx, i18n.RetentionPoliciesFound)
+	response.Data = data
+	return response, nil
+}
+
+// Policy implements RetentionService.
+func (service *retentionService) Policy(ctx context.Context, Sensor string) (models.RetentionPolicy, error) {
+	policies, err := service.policies(ctx)
+	if err != nil {
+		return models.RetentionPolicy{}, err
+	}
+	return policies[Sensor], nil
+}
+
+// SavePolicy implements RetentionService.
+func (service *retentionService) SavePolicy(ctx context.Context, policy models.RetentionPolicy) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "RetentionService.SavePolicy")
+	defer span.End()
+
+	var response helpers.Response
+
+	if _, ok := sensors.Find(policy.Sensor); !ok {
+		service.logger.ErrorContext(ctx, "Jenis sensor tidak dikenali", "sensor", policy.Sensor)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorUnknown, policy.Sensor))
+	}
+
+	if err := service.retentionRepo.SavePolicy(ctx, policy); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal menyimpan kebijakan retensi sensor", "sensor", policy.Sensor, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.RetentionPolicyFailed, policy.Sensor), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil menyimpan kebijakan retensi sensor", "sensor", policy.Sensor)
+	response.SetMessage(ctx, i18n.RetentionPolicySaved, policy.Sensor)
+	response.Data = policy
+
+	return response, nil
+}
+
+// Apply implements RetentionService.
+// Data mentah yang melewati batas retensi dirangkum menjadi rollup per jam dan
+// per hari sebelum dihapus, lalu rollup yang melewati batasnya ikut dihapus.
+// Data mentah tanpa created_at dipindahkan ke db_sensor_quarantine karena
+// tidak dapat di-rollup maupun dihapus berdasarkan umur.
+func (service *retentionService) Apply(ctx context.Context, now time.Time) (map[string]models.Stats, error) {
+	ctx, span := tracing.Start(ctx, "RetentionService.Apply")
+	defer span.End()
+
+	policies, err := service.policies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]models.Stats)
+	for _, sensor := range sensors.All {
+		policy := policies[sensor.Name]
+		var stats models.Stats
+
+		if cutoff, ok := policy.RawCutoff(now); ok {
+			if stats.RollupRows, stats.RawDeleted, err = service.retentionRepo.RollupAndDelete(ctx, sensor, cutoff); err != nil {
+				return result, fmt.Errorf("rollup sensor %s gagal: %w", sensor.Name, err)
+			}
+			if stats.Quarantined, err = service.retentionRepo.QuarantineUndated(ctx, sensor, now); err != nil {
+				return result, fmt.Errorf("karantina data tanpa waktu sensor %s gagal: %w", sensor.Name, err)
+			}
+			if stats.Quarantined > 0 {
+				service.logger.WarnContext(ctx, "Data sensor tanpa created_at dipindahkan ke karantina", "sensor", sensor.Name, "count", stats.Quarantined)
+			}
+		}
+		if cutoff, ok := policy.HourlyCutoff(now); ok {
+			if stats.HourlyDeleted, err = service.retentionRepo.DeleteRollups(ctx, sensor.Name, models.ResolutionHour, cutoff); err != nil {
+				return result, fmt.Errorf("hapus rollup jam sensor %s gagal: %w", sensor.Name, err)
+			}
+		}
+		if cutoff, ok := policy.DailyCutoff(now); ok {
+			if stats.DailyDeleted, err = service.retentionRepo.DeleteRollups(ctx, sensor.Name, models.ResolutionDay, cutoff); err != nil {
+				return result, fmt.Errorf("hapus rollup hari sensor %s gagal: %w", sensor.Name, err)
+			}
+		}
+
+		result[sensor.Name] = stats
+	}
+
+	return result, nil
+}
+
+// GetHistory implements RetentionService.
+// Rentang waktu yang masih memiliki data mentah dibaca dari tabel sensor,
+// rentang yang lebih lama dibaca dari rollup per jam lalu rollup per hari.
+func (service *retentionService) GetHistory(ctx context.Context, Sensor string, DeviceToken string, Start time.Time, End time.Time) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "RetentionService.GetHistory")
+	defer span.End()
+
+	var response helpers.Response
+
+	sensor, ok := sensors.Find(Sensor)
+	if !ok {
+		service.logger.ErrorContext(ctx, "Jenis sensor tidak dikenali", "sensor", Sensor)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorUnknown, Sensor))
+	}
+
+	policies, err := service.policies(ctx)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kebijakan retensi", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorHistoryFailed, Sensor), err)
+	}
+
+	policy := policies[Sensor]
+	now := time.Now()
+
+	var points []models.HistoryPoint
+	rawStart := Start
+	if rawCutoff, ok := policy.RawCutoff(now); ok && Start.Before(rawCutoff) {
+		rawStart = rawCutoff
+
+		hourStart := Start
+		if hourlyCutoff, ok := policy.HourlyCutoff(now); ok && Start.Before(hourlyCutoff) {
+			hourStart = hourlyCutoff
+
+			daily, err := service.retentionRepo.GetRollups(ctx, Sensor, DeviceToken, models.ResolutionDay, Start, minTime(End, hourlyCutoff))
+			if err != nil {
+				return service.historyError(ctx, Sensor, err)
+			}
+			points = append(points, groupRollups(daily)...)
+		}
+
+		if hourStart.Before(End) {
+			hourly, err := service.retentionRepo.GetRollups(ctx, Sensor, DeviceToken, models.ResolutionHour, hourStart, minTime(End, rawCutoff))
+			if err != nil {
+				return service.historyError(ctx, Sensor, err)
+			}
+			points = append(points, groupRollups(hourly)...)
+		}
+	}
+
+	if rawStart.Before(End) {
+		raw, err := service.retentionRepo.GetRaw(ctx, sensor, DeviceToken, rawStart, End)
+		if err != nil {
+			return service.historyError(ctx, Sensor, err)
+		}
+		points = append(points, raw...)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil riwayat sensor", "sensor", Sensor, "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorHistoryFound, Sensor)
+	response.Data = points
+	return response, nil
+}
+
+func (service *retentionService) historyError(ctx context.Context, Sensor string, err error) (helpers.Response, error) {
+	service.logger.ErrorContext(ctx, "Gagal mengambil riwayat sensor", "sensor", Sensor, logging.Error(err))
+	return helpers.Response{}, helpers.Internal(i18n.Msg(i18n.SensorHistoryFailed, Sensor), err)
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// groupRollups menggabungkan rollup per field menjadi satu titik per perangkat dan bucket.
+func groupRollups(rollups []models.Rollup) []models.HistoryPoint {
+	type key struct {
+		deviceToken string
+		bucket      int64
+	}
+
+	index := make(map[key]int)
+	var points []models.HistoryPoint
+	for _, rollup := range rollups {
+		k := key{rollup.DeviceToken, rollup.BucketStart.Unix()}
+		i, ok := index[k]
+		if !ok {
+			i = len(points)
+			index[k] = i
+			points = append(points, models.HistoryPoint{
+				Time:        rollup.BucketStart,
+				DeviceToken: rollup.DeviceToken,
+				Resolution:  rollup.Resolution,
+				Values:      map[string]float64{},
+				Min:         map[string]float64{},
+				Max:         map[string]float64{},
+			})
+		}
+
+		points[i].Values[rollup.Field] = rollup.AvgValue
+		points[i].Min[rollup.Field] = rollup.MinValue
+		points[i].Max[rollup.Field] = rollup.MaxValue
+		if rollup.Count > points[i].Count {
+			points[i].Count = rollup.Count
+		}
+	}
+
+	sort.SliceStable(points, func(a, b int) bool { return points[a].Time.Before(points[b].Time) })
+	return points
+}
+
+type RetentionService interface {
+	GetPolicies(ctx context.Context) (helpers.Response, error)
+	Policy(ctx context.Context, Sensor string) (models.RetentionPolicy, error)
+	SavePolicy(ctx context.Context, policy models.RetentionPolicy) (helpers.Response, error)
+	Apply(ctx context.Context, now time.Time) (map[string]models.Stats, error)
+	GetHistory(ctx context.Context, Sensor string, DeviceToken string, Start time.Time, End time.Time) (helpers.Response, error)
+}
+
+func NewRetentionService(db *gorm.DB, logger *slog.Logger) RetentionService {
+	return &retentionService{
+		logger:        logger.With("component", "retention"),
+		retentionRepo: repositories.NewRetentionRepository(db, logger),
+		defaults: models.RetentionPolicy{
+			RawDays:      envInt("RETENTION_RAW_DAYS"),
+			HourlyMonths: envInt("RETENTION_HOURLY_MONTHS"),
+			DailyMonths:  envInt("RETENTION_DAILY_MONTHS"),
+		},
+	}
+}
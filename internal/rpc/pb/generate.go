@@ -0,0 +1,5 @@
+// Package pb berisi kode Go hasil generate dari iot.proto. Jalankan
+// go generate setelah mengubah iot.proto.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative iot.proto
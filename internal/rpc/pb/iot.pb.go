@@ -0,0 +1,1482 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        (unknown)
+// source: iot.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Reading adalah satu data sensor dari salah satu jenis sensor.
+type Reading struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Sensor:
+	//	*Reading_Beitian
+	//	*Reading_Bmp
+	//	*Reading_Ina
+	//	*Reading_Pzem
+	//	*Reading_Thigrow
+	//	*Reading_Thm
+	Sensor isReading_Sensor `protobuf_oneof:"sensor"`
+}
+
+func (x *Reading) Reset() {
+	*x = Reading{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Reading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reading) ProtoMessage() {}
+
+func (x *Reading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reading.ProtoReflect.Descriptor instead.
+func (*Reading) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *Reading) GetSensor() isReading_Sensor {
+	if m != nil {
+		return m.Sensor
+	}
+	return nil
+}
+
+func (x *Reading) GetBeitian() *BeitianReading {
+	if x, ok := x.GetSensor().(*Reading_Beitian); ok {
+		return x.Beitian
+	}
+	return nil
+}
+
+func (x *Reading) GetBmp() *BmpReading {
+	if x, ok := x.GetSensor().(*Reading_Bmp); ok {
+		return x.Bmp
+	}
+	return nil
+}
+
+func (x *Reading) GetIna() *InaReading {
+	if x, ok := x.GetSensor().(*Reading_Ina); ok {
+		return x.Ina
+	}
+	return nil
+}
+
+func (x *Reading) GetPzem() *PzemReading {
+	if x, ok := x.GetSensor().(*Reading_Pzem); ok {
+		return x.Pzem
+	}
+	return nil
+}
+
+func (x *Reading) GetThigrow() *ThigrowReading {
+	if x, ok := x.GetSensor().(*Reading_Thigrow); ok {
+		return x.Thigrow
+	}
+	return nil
+}
+
+func (x *Reading) GetThm() *ThmReading {
+	if x, ok := x.GetSensor().(*Reading_Thm); ok {
+		return x.Thm
+	}
+	return nil
+}
+
+type isReading_Sensor interface {
+	isReading_Sensor()
+}
+
+type Reading_Beitian struct {
+	Beitian *BeitianReading `protobuf:"bytes,1,opt,name=beitian,proto3,oneof"`
+}
+
+type Reading_Bmp struct {
+	Bmp *BmpReading `protobuf:"bytes,2,opt,name=bmp,proto3,oneof"`
+}
+
+type Reading_Ina struct {
+	Ina *InaReading `protobuf:"bytes,3,opt,name=ina,proto3,oneof"`
+}
+
+type Reading_Pzem struct {
+	Pzem *PzemReading `protobuf:"bytes,4,opt,name=pzem,proto3,oneof"`
+}
+
+type Reading_Thigrow struct {
+	Thigrow *ThigrowReading `protobuf:"bytes,5,opt,name=thigrow,proto3,oneof"`
+}
+
+type Reading_Thm struct {
+	Thm *ThmReading `protobuf:"bytes,6,opt,name=thm,proto3,oneof"`
+}
+
+func (*Reading_Beitian) isReading_Sensor() {}
+
+func (*Reading_Bmp) isReading_Sensor() {}
+
+func (*Reading_Ina) isReading_Sensor() {}
+
+func (*Reading_Pzem) isReading_Sensor() {}
+
+func (*Reading_Thigrow) isReading_Sensor() {}
+
+func (*Reading_Thm) isReading_Sensor() {}
+
+// Metadata diisi oleh server pada ReadingEvent dan diabaikan saat ingestion.
+type Metadata struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Quality      string                 `protobuf:"bytes,1,opt,name=quality,proto3" json:"quality,omitempty"`
+	AnomalyScore float64                `protobuf:"fixed64,2,opt,name=anomaly_score,json=anomalyScore,proto3" json:"anomaly_score,omitempty"`
+	CreatedAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Metadata) GetQuality() string {
+	if x != nil {
+		return x.Quality
+	}
+	return ""
+}
+
+func (x *Metadata) GetAnomalyScore() float64 {
+	if x != nil {
+		return x.AnomalyScore
+	}
+	return 0
+}
+
+func (x *Metadata) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type BeitianReading struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceToken string    `protobuf:"bytes,1,opt,name=device_token,json=deviceToken,proto3" json:"device_token,omitempty"`
+	Latitude    *float64  `protobuf:"fixed64,2,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`
+	Longitude   *float64  `protobuf:"fixed64,3,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	Battery     *float64  `protobuf:"fixed64,4,opt,name=battery,proto3,oneof" json:"battery,omitempty"`
+	Metadata    *Metadata `protobuf:"bytes,15,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (x *BeitianReading) Reset() {
+	*x = BeitianReading{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BeitianReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeitianReading) ProtoMessage() {}
+
+func (x *BeitianReading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BeitianReading.ProtoReflect.Descriptor instead.
+func (*BeitianReading) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BeitianReading) GetDeviceToken() string {
+	if x != nil {
+		return x.DeviceToken
+	}
+	return ""
+}
+
+func (x *BeitianReading) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *BeitianReading) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *BeitianReading) GetBattery() float64 {
+	if x != nil && x.Battery != nil {
+		return *x.Battery
+	}
+	return 0
+}
+
+func (x *BeitianReading) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type BmpReading struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceToken     string    `protobuf:"bytes,1,opt,name=device_token,json=deviceToken,proto3" json:"device_token,omitempty"`
+	TekananUdara    *float64  `protobuf:"fixed64,2,opt,name=tekanan_udara,json=tekananUdara,proto3,oneof" json:"tekanan_udara,omitempty"`
+	TinggiPermukaan *float64  `protobuf:"fixed64,3,opt,name=tinggi_permukaan,json=tinggiPermukaan,proto3,oneof" json:"tinggi_permukaan,omitempty"`
+	Battery         *float64  `protobuf:"fixed64,4,opt,name=battery,proto3,oneof" json:"battery,omitempty"`
+	Metadata        *Metadata `protobuf:"bytes,15,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (x *BmpReading) Reset() {
+	*x = BmpReading{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BmpReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BmpReading) ProtoMessage() {}
+
+func (x *BmpReading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BmpReading.ProtoReflect.Descriptor instead.
+func (*BmpReading) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BmpReading) GetDeviceToken() string {
+	if x != nil {
+		return x.DeviceToken
+	}
+	return ""
+}
+
+func (x *BmpReading) GetTekananUdara() float64 {
+	if x != nil && x.TekananUdara != nil {
+		return *x.TekananUdara
+	}
+	return 0
+}
+
+func (x *BmpReading) GetTinggiPermukaan() float64 {
+	if x != nil && x.TinggiPermukaan != nil {
+		return *x.TinggiPermukaan
+	}
+	return 0
+}
+
+func (x *BmpReading) GetBattery() float64 {
+	if x != nil && x.Battery != nil {
+		return *x.Battery
+	}
+	return 0
+}
+
+func (x *BmpReading) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type InaReading struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceToken string    `protobuf:"bytes,1,opt,name=device_token,json=deviceToken,proto3" json:"device_token,omitempty"`
+	Tegangan    *float64  `protobuf:"fixed64,2,opt,name=tegangan,proto3,oneof" json:"tegangan,omitempty"`
+	Arus        *float64  `protobuf:"fixed64,3,opt,name=arus,proto3,oneof" json:"arus,omitempty"`
+	Daya        *float64  `protobuf:"fixed64,4,opt,name=daya,proto3,oneof" json:"daya,omitempty"`
+	Metadata    *Metadata `protobuf:"bytes,15,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (x *InaReading) Reset() {
+	*x = InaReading{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InaReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InaReading) ProtoMessage() {}
+
+func (x *InaReading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InaReading.ProtoReflect.Descriptor instead.
+func (*InaReading) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *InaReading) GetDeviceToken() string {
+	if x != nil {
+		return x.DeviceToken
+	}
+	return ""
+}
+
+func (x *InaReading) GetTegangan() float64 {
+	if x != nil && x.Tegangan != nil {
+		return *x.Tegangan
+	}
+	return 0
+}
+
+func (x *InaReading) GetArus() float64 {
+	if x != nil && x.Arus != nil {
+		return *x.Arus
+	}
+	return 0
+}
+
+func (x *InaReading) GetDaya() float64 {
+	if x != nil && x.Daya != nil {
+		return *x.Daya
+	}
+	return 0
+}
+
+func (x *InaReading) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type PzemReading struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceToken string    `protobuf:"bytes,1,opt,name=device_token,json=deviceToken,proto3" json:"device_token,omitempty"`
+	Tegangan    *float64  `protobuf:"fixed64,2,opt,name=tegangan,proto3,oneof" json:"tegangan,omitempty"`
+	Arus        *float64  `protobuf:"fixed64,3,opt,name=arus,proto3,oneof" json:"arus,omitempty"`
+	Daya        *float64  `protobuf:"fixed64,4,opt,name=daya,proto3,oneof" json:"daya,omitempty"`
+	Metadata    *Metadata `protobuf:"bytes,15,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (x *PzemReading) Reset() {
+	*x = PzemReading{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PzemReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PzemReading) ProtoMessage() {}
+
+func (x *PzemReading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PzemReading.ProtoReflect.Descriptor instead.
+func (*PzemReading) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PzemReading) GetDeviceToken() string {
+	if x != nil {
+		return x.DeviceToken
+	}
+	return ""
+}
+
+func (x *PzemReading) GetTegangan() float64 {
+	if x != nil && x.Tegangan != nil {
+		return *x.Tegangan
+	}
+	return 0
+}
+
+func (x *PzemReading) GetArus() float64 {
+	if x != nil && x.Arus != nil {
+		return *x.Arus
+	}
+	return 0
+}
+
+func (x *PzemReading) GetDaya() float64 {
+	if x != nil && x.Daya != nil {
+		return *x.Daya
+	}
+	return 0
+}
+
+func (x *PzemReading) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ThigrowReading struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceToken       string    `protobuf:"bytes,1,opt,name=device_token,json=deviceToken,proto3" json:"device_token,omitempty"`
+	KelembabanTanahTh int32     `protobuf:"varint,2,opt,name=kelembaban_tanah_th,json=kelembabanTanahTh,proto3" json:"kelembaban_tanah_th,omitempty"`
+	KelembabanTanahSm int32     `protobuf:"varint,3,opt,name=kelembaban_tanah_sm,json=kelembabanTanahSm,proto3" json:"kelembaban_tanah_sm,omitempty"`
+	KelembabanUdara   int32     `protobuf:"varint,4,opt,name=kelembaban_udara,json=kelembabanUdara,proto3" json:"kelembaban_udara,omitempty"`
+	IntensitasCahaya  *float64  `protobuf:"fixed64,5,opt,name=intensitas_cahaya,json=intensitasCahaya,proto3,oneof" json:"intensitas_cahaya,omitempty"`
+	Battery           *float64  `protobuf:"fixed64,6,opt,name=battery,proto3,oneof" json:"battery,omitempty"`
+	Temperature       *float64  `protobuf:"fixed64,7,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+	KadarGaram        *float64  `protobuf:"fixed64,8,opt,name=kadar_garam,json=kadarGaram,proto3,oneof" json:"kadar_garam,omitempty"`
+	Metadata          *Metadata `protobuf:"bytes,15,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (x *ThigrowReading) Reset() {
+	*x = ThigrowReading{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThigrowReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThigrowReading) ProtoMessage() {}
+
+func (x *ThigrowReading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThigrowReading.ProtoReflect.Descriptor instead.
+func (*ThigrowReading) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ThigrowReading) GetDeviceToken() string {
+	if x != nil {
+		return x.DeviceToken
+	}
+	return ""
+}
+
+func (x *ThigrowReading) GetKelembabanTanahTh() int32 {
+	if x != nil {
+		return x.KelembabanTanahTh
+	}
+	return 0
+}
+
+func (x *ThigrowReading) GetKelembabanTanahSm() int32 {
+	if x != nil {
+		return x.KelembabanTanahSm
+	}
+	return 0
+}
+
+func (x *ThigrowReading) GetKelembabanUdara() int32 {
+	if x != nil {
+		return x.KelembabanUdara
+	}
+	return 0
+}
+
+func (x *ThigrowReading) GetIntensitasCahaya() float64 {
+	if x != nil && x.IntensitasCahaya != nil {
+		return *x.IntensitasCahaya
+	}
+	return 0
+}
+
+func (x *ThigrowReading) GetBattery() float64 {
+	if x != nil && x.Battery != nil {
+		return *x.Battery
+	}
+	return 0
+}
+
+func (x *ThigrowReading) GetTemperature() float64 {
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
+	}
+	return 0
+}
+
+func (x *ThigrowReading) GetKadarGaram() float64 {
+	if x != nil && x.KadarGaram != nil {
+		return *x.KadarGaram
+	}
+	return 0
+}
+
+func (x *ThigrowReading) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ThmReading struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceToken     string    `protobuf:"bytes,1,opt,name=device_token,json=deviceToken,proto3" json:"device_token,omitempty"`
+	Temperature     *float64  `protobuf:"fixed64,2,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+	KelembabanUdara *float64  `protobuf:"fixed64,3,opt,name=kelembaban_udara,json=kelembabanUdara,proto3,oneof" json:"kelembaban_udara,omitempty"`
+	Battery         *float64  `protobuf:"fixed64,4,opt,name=battery,proto3,oneof" json:"battery,omitempty"`
+	Metadata        *Metadata `protobuf:"bytes,15,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (x *ThmReading) Reset() {
+	*x = ThmReading{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThmReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThmReading) ProtoMessage() {}
+
+func (x *ThmReading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThmReading.ProtoReflect.Descriptor instead.
+func (*ThmReading) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ThmReading) GetDeviceToken() string {
+	if x != nil {
+		return x.DeviceToken
+	}
+	return ""
+}
+
+func (x *ThmReading) GetTemperature() float64 {
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
+	}
+	return 0
+}
+
+func (x *ThmReading) GetKelembabanUdara() float64 {
+	if x != nil && x.KelembabanUdara != nil {
+		return *x.KelembabanUdara
+	}
+	return 0
+}
+
+func (x *ThmReading) GetBattery() float64 {
+	if x != nil && x.Battery != nil {
+		return *x.Battery
+	}
+	return 0
+}
+
+func (x *ThmReading) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type CreateReadingResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code    string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *CreateReadingResponse) Reset() {
+	*x = CreateReadingResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateReadingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReadingResponse) ProtoMessage() {}
+
+func (x *CreateReadingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReadingResponse.ProtoReflect.Descriptor instead.
+func (*CreateReadingResponse) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateReadingResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CreateReadingResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// RejectedReading adalah data pada stream yang ditolak beserta alasannya per
+// field. Index dimulai dari 0 sesuai urutan pesan pada stream.
+type RejectedReading struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index   int32             `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Reasons map[string]string `protobuf:"bytes,2,rep,name=reasons,proto3" json:"reasons,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *RejectedReading) Reset() {
+	*x = RejectedReading{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RejectedReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectedReading) ProtoMessage() {}
+
+func (x *RejectedReading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectedReading.ProtoReflect.Descriptor instead.
+func (*RejectedReading) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RejectedReading) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *RejectedReading) GetReasons() map[string]string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+type StreamReadingsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message          string             `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Total            int32              `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Inserted         int32              `protobuf:"varint,3,opt,name=inserted,proto3" json:"inserted,omitempty"`
+	Rejected         int32              `protobuf:"varint,4,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	RejectedReadings []*RejectedReading `protobuf:"bytes,5,rep,name=rejected_readings,json=rejectedReadings,proto3" json:"rejected_readings,omitempty"`
+}
+
+func (x *StreamReadingsResponse) Reset() {
+	*x = StreamReadingsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamReadingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamReadingsResponse) ProtoMessage() {}
+
+func (x *StreamReadingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamReadingsResponse.ProtoReflect.Descriptor instead.
+func (*StreamReadingsResponse) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StreamReadingsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *StreamReadingsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *StreamReadingsResponse) GetInserted() int32 {
+	if x != nil {
+		return x.Inserted
+	}
+	return 0
+}
+
+func (x *StreamReadingsResponse) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *StreamReadingsResponse) GetRejectedReadings() []*RejectedReading {
+	if x != nil {
+		return x.RejectedReadings
+	}
+	return nil
+}
+
+// SubscribeRequest menyaring data yang dikirim. Daftar kosong berarti semua
+// jenis sensor atau semua perangkat.
+type SubscribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sensors      []string `protobuf:"bytes,1,rep,name=sensors,proto3" json:"sensors,omitempty"`
+	DeviceTokens []string `protobuf:"bytes,2,rep,name=device_tokens,json=deviceTokens,proto3" json:"device_tokens,omitempty"`
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SubscribeRequest) GetSensors() []string {
+	if x != nil {
+		return x.Sensors
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetDeviceTokens() []string {
+	if x != nil {
+		return x.DeviceTokens
+	}
+	return nil
+}
+
+type ReadingEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sensor  string   `protobuf:"bytes,1,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	Reading *Reading `protobuf:"bytes,2,opt,name=reading,proto3" json:"reading,omitempty"`
+}
+
+func (x *ReadingEvent) Reset() {
+	*x = ReadingEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_iot_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadingEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadingEvent) ProtoMessage() {}
+
+func (x *ReadingEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadingEvent.ProtoReflect.Descriptor instead.
+func (*ReadingEvent) Descriptor() ([]byte, []int) {
+	return file_iot_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ReadingEvent) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *ReadingEvent) GetReading() *Reading {
+	if x != nil {
+		return x.Reading
+	}
+	return nil
+}
+
+var File_iot_proto protoreflect.FileDescriptor
+
+var file_iot_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x69, 0x6f, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x69, 0x6f, 0x74,
+	0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9e, 0x02, 0x0a, 0x07, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x12, 0x32, 0x0a, 0x07, 0x62, 0x65, 0x69, 0x74, 0x69, 0x61, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x65, 0x69, 0x74, 0x69,
+	0x61, 0x6e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x48, 0x00, 0x52, 0x07, 0x62, 0x65, 0x69,
+	0x74, 0x69, 0x61, 0x6e, 0x12, 0x26, 0x0a, 0x03, 0x62, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6d, 0x70, 0x52, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x48, 0x00, 0x52, 0x03, 0x62, 0x6d, 0x70, 0x12, 0x26, 0x0a, 0x03,
+	0x69, 0x6e, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x69, 0x6f, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x49, 0x6e, 0x61, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x48, 0x00, 0x52,
+	0x03, 0x69, 0x6e, 0x61, 0x12, 0x29, 0x0a, 0x04, 0x70, 0x7a, 0x65, 0x6d, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x7a, 0x65, 0x6d,
+	0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x48, 0x00, 0x52, 0x04, 0x70, 0x7a, 0x65, 0x6d, 0x12,
+	0x32, 0x0a, 0x07, 0x74, 0x68, 0x69, 0x67, 0x72, 0x6f, 0x77, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x69, 0x67, 0x72, 0x6f,
+	0x77, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x48, 0x00, 0x52, 0x07, 0x74, 0x68, 0x69, 0x67,
+	0x72, 0x6f, 0x77, 0x12, 0x26, 0x0a, 0x03, 0x74, 0x68, 0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x6d, 0x52, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x48, 0x00, 0x52, 0x03, 0x74, 0x68, 0x6d, 0x42, 0x08, 0x0a, 0x06, 0x73,
+	0x65, 0x6e, 0x73, 0x6f, 0x72, 0x22, 0x84, 0x01, 0x0a, 0x08, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x23, 0x0a, 0x0d,
+	0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0c, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x53, 0x63, 0x6f, 0x72,
+	0x65, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xeb, 0x01, 0x0a,
+	0x0e, 0x42, 0x65, 0x69, 0x74, 0x69, 0x61, 0x6e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x12,
+	0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x12, 0x1f, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74,
+	0x75, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x79, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x42, 0x0a,
+	0x0a, 0x08, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x22, 0x89, 0x02, 0x0a, 0x0a, 0x42,
+	0x6d, 0x70, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x28, 0x0a, 0x0d,
+	0x74, 0x65, 0x6b, 0x61, 0x6e, 0x61, 0x6e, 0x5f, 0x75, 0x64, 0x61, 0x72, 0x61, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x0c, 0x74, 0x65, 0x6b, 0x61, 0x6e, 0x61, 0x6e, 0x55, 0x64,
+	0x61, 0x72, 0x61, 0x88, 0x01, 0x01, 0x12, 0x2e, 0x0a, 0x10, 0x74, 0x69, 0x6e, 0x67, 0x67, 0x69,
+	0x5f, 0x70, 0x65, 0x72, 0x6d, 0x75, 0x6b, 0x61, 0x61, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x01, 0x52, 0x0f, 0x74, 0x69, 0x6e, 0x67, 0x67, 0x69, 0x50, 0x65, 0x72, 0x6d, 0x75, 0x6b,
+	0x61, 0x61, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x79, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x74, 0x65, 0x6b, 0x61, 0x6e, 0x61, 0x6e, 0x5f,
+	0x75, 0x64, 0x61, 0x72, 0x61, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x74, 0x69, 0x6e, 0x67, 0x67, 0x69,
+	0x5f, 0x70, 0x65, 0x72, 0x6d, 0x75, 0x6b, 0x61, 0x61, 0x6e, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x62,
+	0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x22, 0xcf, 0x01, 0x0a, 0x0a, 0x49, 0x6e, 0x61, 0x52, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1f, 0x0a, 0x08, 0x74, 0x65, 0x67, 0x61,
+	0x6e, 0x67, 0x61, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x08, 0x74, 0x65,
+	0x67, 0x61, 0x6e, 0x67, 0x61, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x61, 0x72, 0x75,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x04, 0x61, 0x72, 0x75, 0x73, 0x88,
+	0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x64, 0x61, 0x79, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x02, 0x52, 0x04, 0x64, 0x61, 0x79, 0x61, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x08, 0x6d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x74, 0x65,
+	0x67, 0x61, 0x6e, 0x67, 0x61, 0x6e, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x61, 0x72, 0x75, 0x73, 0x42,
+	0x07, 0x0a, 0x05, 0x5f, 0x64, 0x61, 0x79, 0x61, 0x22, 0xd0, 0x01, 0x0a, 0x0b, 0x50, 0x7a, 0x65,
+	0x6d, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1f, 0x0a, 0x08, 0x74,
+	0x65, 0x67, 0x61, 0x6e, 0x67, 0x61, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52,
+	0x08, 0x74, 0x65, 0x67, 0x61, 0x6e, 0x67, 0x61, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04,
+	0x61, 0x72, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x04, 0x61, 0x72,
+	0x75, 0x73, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x64, 0x61, 0x79, 0x61, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x04, 0x64, 0x61, 0x79, 0x61, 0x88, 0x01, 0x01, 0x12, 0x2c,
+	0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x42, 0x0b, 0x0a, 0x09,
+	0x5f, 0x74, 0x65, 0x67, 0x61, 0x6e, 0x67, 0x61, 0x6e, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x61, 0x72,
+	0x75, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x64, 0x61, 0x79, 0x61, 0x22, 0xcc, 0x03, 0x0a, 0x0e,
+	0x54, 0x68, 0x69, 0x67, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x21,
+	0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x2e, 0x0a, 0x13, 0x6b, 0x65, 0x6c, 0x65, 0x6d, 0x62, 0x61, 0x62, 0x61, 0x6e, 0x5f,
+	0x74, 0x61, 0x6e, 0x61, 0x68, 0x5f, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11,
+	0x6b, 0x65, 0x6c, 0x65, 0x6d, 0x62, 0x61, 0x62, 0x61, 0x6e, 0x54, 0x61, 0x6e, 0x61, 0x68, 0x54,
+	0x68, 0x12, 0x2e, 0x0a, 0x13, 0x6b, 0x65, 0x6c, 0x65, 0x6d, 0x62, 0x61, 0x62, 0x61, 0x6e, 0x5f,
+	0x74, 0x61, 0x6e, 0x61, 0x68, 0x5f, 0x73, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11,
+	0x6b, 0x65, 0x6c, 0x65, 0x6d, 0x62, 0x61, 0x62, 0x61, 0x6e, 0x54, 0x61, 0x6e, 0x61, 0x68, 0x53,
+	0x6d, 0x12, 0x29, 0x0a, 0x10, 0x6b, 0x65, 0x6c, 0x65, 0x6d, 0x62, 0x61, 0x62, 0x61, 0x6e, 0x5f,
+	0x75, 0x64, 0x61, 0x72, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x6b, 0x65, 0x6c,
+	0x65, 0x6d, 0x62, 0x61, 0x62, 0x61, 0x6e, 0x55, 0x64, 0x61, 0x72, 0x61, 0x12, 0x30, 0x0a, 0x11,
+	0x69, 0x6e, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x61, 0x73, 0x5f, 0x63, 0x61, 0x68, 0x61, 0x79,
+	0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x10, 0x69, 0x6e, 0x74, 0x65, 0x6e,
+	0x73, 0x69, 0x74, 0x61, 0x73, 0x43, 0x61, 0x68, 0x61, 0x79, 0x61, 0x88, 0x01, 0x01, 0x12, 0x1d,
+	0x0a, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x01, 0x52, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a,
+	0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x01, 0x48, 0x02, 0x52, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0b, 0x6b, 0x61, 0x64, 0x61, 0x72, 0x5f, 0x67, 0x61,
+	0x72, 0x61, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x0a, 0x6b, 0x61, 0x64,
+	0x61, 0x72, 0x47, 0x61, 0x72, 0x61, 0x6d, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x08, 0x6d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x69,
+	0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x69, 0x6e, 0x74,
+	0x65, 0x6e, 0x73, 0x69, 0x74, 0x61, 0x73, 0x5f, 0x63, 0x61, 0x68, 0x61, 0x79, 0x61, 0x42, 0x0a,
+	0x0a, 0x08, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x74,
+	0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6b,
+	0x61, 0x64, 0x61, 0x72, 0x5f, 0x67, 0x61, 0x72, 0x61, 0x6d, 0x22, 0x84, 0x02, 0x0a, 0x0a, 0x54,
+	0x68, 0x6d, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x25, 0x0a, 0x0b,
+	0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x00, 0x52, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x2e, 0x0a, 0x10, 0x6b, 0x65, 0x6c, 0x65, 0x6d, 0x62, 0x61, 0x62, 0x61,
+	0x6e, 0x5f, 0x75, 0x64, 0x61, 0x72, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52,
+	0x0f, 0x6b, 0x65, 0x6c, 0x65, 0x6d, 0x62, 0x61, 0x62, 0x61, 0x6e, 0x55, 0x64, 0x61, 0x72, 0x61,
+	0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x88,
+	0x01, 0x01, 0x12, 0x2c, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x42, 0x13, 0x0a, 0x11, 0x5f, 0x6b, 0x65, 0x6c, 0x65, 0x6d, 0x62, 0x61, 0x62, 0x61, 0x6e, 0x5f,
+	0x75, 0x64, 0x61, 0x72, 0x61, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x79, 0x22, 0x45, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xa3, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x6a,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x3e, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6a,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc6,
+	0x01, 0x0a, 0x16, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x73,
+	0x65, 0x72, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x69, 0x6e, 0x73,
+	0x65, 0x72, 0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x12, 0x44, 0x0a, 0x11, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x72, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x69,
+	0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x52, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x10, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x52,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x22, 0x51, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63,
+	0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x65, 0x6e, 0x73, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x22, 0x51, 0x0a, 0x0c, 0x52, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x73,
+	0x6f, 0x72, 0x12, 0x29, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x52, 0x07, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x32, 0xdc, 0x01,
+	0x0a, 0x0d, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x3f, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x12, 0x0f, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x1a, 0x1d, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x43, 0x0a, 0x0e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x73, 0x12, 0x0f, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x61, 0x64,
+	0x69, 0x6e, 0x67, 0x1a, 0x1e, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x45, 0x0a, 0x11, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x18, 0x2e, 0x69, 0x6f, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x69, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x1f, 0x5a, 0x1d,
+	0x69, 0x6f, 0x74, 0x2d, 0x67, 0x6f, 0x6c, 0x61, 0x6e, 0x67, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x6e, 0x61, 0x6c, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x62, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_iot_proto_rawDescOnce sync.Once
+	file_iot_proto_rawDescData = file_iot_proto_rawDesc
+)
+
+func file_iot_proto_rawDescGZIP() []byte {
+	file_iot_proto_rawDescOnce.Do(func() {
+		file_iot_proto_rawDescData = protoimpl.X.CompressGZIP(file_iot_proto_rawDescData)
+	})
+	return file_iot_proto_rawDescData
+}
+
+var file_iot_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_iot_proto_goTypes = []interface{}{
+	(*Reading)(nil),                // 0: iot.v1.Reading
+	(*Metadata)(nil),               // 1: iot.v1.Metadata
+	(*BeitianReading)(nil),         // 2: iot.v1.BeitianReading
+	(*BmpReading)(nil),             // 3: iot.v1.BmpReading
+	(*InaReading)(nil),             // 4: iot.v1.InaReading
+	(*PzemReading)(nil),            // 5: iot.v1.PzemReading
+	(*ThigrowReading)(nil),         // 6: iot.v1.ThigrowReading
+	(*ThmReading)(nil),             // 7: iot.v1.ThmReading
+	(*CreateReadingResponse)(nil),  // 8: iot.v1.CreateReadingResponse
+	(*RejectedReading)(nil),        // 9: iot.v1.RejectedReading
+	(*StreamReadingsResponse)(nil), // 10: iot.v1.StreamReadingsResponse
+	(*SubscribeRequest)(nil),       // 11: iot.v1.SubscribeRequest
+	(*ReadingEvent)(nil),           // 12: iot.v1.ReadingEvent
+	nil,                            // 13: iot.v1.RejectedReading.ReasonsEntry
+	(*timestamppb.Timestamp)(nil),  // 14: google.protobuf.Timestamp
+}
+var file_iot_proto_depIdxs = []int32{
+	2,  // 0: iot.v1.Reading.beitian:type_name -> iot.v1.BeitianReading
+	3,  // 1: iot.v1.Reading.bmp:type_name -> iot.v1.BmpReading
+	4,  // 2: iot.v1.Reading.ina:type_name -> iot.v1.InaReading
+	5,  // 3: iot.v1.Reading.pzem:type_name -> iot.v1.PzemReading
+	6,  // 4: iot.v1.Reading.thigrow:type_name -> iot.v1.ThigrowReading
+	7,  // 5: iot.v1.Reading.thm:type_name -> iot.v1.ThmReading
+	14, // 6: iot.v1.Metadata.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 7: iot.v1.BeitianReading.metadata:type_name -> iot.v1.Metadata
+	1,  // 8: iot.v1.BmpReading.metadata:type_name -> iot.v1.Metadata
+	1,  // 9: iot.v1.InaReading.metadata:type_name -> iot.v1.Metadata
+	1,  // 10: iot.v1.PzemReading.metadata:type_name -> iot.v1.Metadata
+	1,  // 11: iot.v1.ThigrowReading.metadata:type_name -> iot.v1.Metadata
+	1,  // 12: iot.v1.ThmReading.metadata:type_name -> iot.v1.Metadata
+	13, // 13: iot.v1.RejectedReading.reasons:type_name -> iot.v1.RejectedReading.ReasonsEntry
+	9,  // 14: iot.v1.StreamReadingsResponse.rejected_readings:type_name -> iot.v1.RejectedReading
+	0,  // 15: iot.v1.ReadingEvent.reading:type_name -> iot.v1.Reading
+	0,  // 16: iot.v1.SensorService.CreateReading:input_type -> iot.v1.Reading
+	0,  // 17: iot.v1.SensorService.StreamReadings:input_type -> iot.v1.Reading
+	11, // 18: iot.v1.SensorService.SubscribeReadings:input_type -> iot.v1.SubscribeRequest
+	8,  // 19: iot.v1.SensorService.CreateReading:output_type -> iot.v1.CreateReadingResponse
+	10, // 20: iot.v1.SensorService.StreamReadings:output_type -> iot.v1.StreamReadingsResponse
+	12, // 21: iot.v1.SensorService.SubscribeReadings:output_type -> iot.v1.ReadingEvent
+	19, // [19:22] is the sub-list for method output_type
+	16, // [16:19] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_iot_proto_init() }
+func file_iot_proto_init() {
+	if File_iot_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_iot_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Reading); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Metadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BeitianReading); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BmpReading); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InaReading); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PzemReading); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThigrowReading); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThmReading); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateReadingResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RejectedReading); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamReadingsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_iot_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadingEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_iot_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*Reading_Beitian)(nil),
+		(*Reading_Bmp)(nil),
+		(*Reading_Ina)(nil),
+		(*Reading_Pzem)(nil),
+		(*Reading_Thigrow)(nil),
+		(*Reading_Thm)(nil),
+	}
+	file_iot_proto_msgTypes[2].OneofWrappers = []interface{}{}
+	file_iot_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_iot_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_iot_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_iot_proto_msgTypes[6].OneofWrappers = []interface{}{}
+	file_iot_proto_msgTypes[7].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_iot_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_iot_proto_goTypes,
+		DependencyIndexes: file_iot_proto_depIdxs,
+		MessageInfos:      file_iot_proto_msgTypes,
+	}.Build()
+	File_iot_proto = out.File
+	file_iot_proto_rawDesc = nil
+	file_iot_proto_goTypes = nil
+	file_iot_proto_depIdxs = nil
+}
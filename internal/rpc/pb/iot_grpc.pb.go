@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: iot.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SensorService_CreateReading_FullMethodName     = "/iot.v1.SensorService/CreateReading"
+	SensorService_StreamReadings_FullMethodName    = "/iot.v1.SensorService/StreamReadings"
+	SensorService_SubscribeReadings_FullMethodName = "/iot.v1.SensorService/SubscribeReadings"
+)
+
+// SensorServiceClient is the client API for SensorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SensorServiceClient interface {
+	// CreateReading menyimpan satu data sensor.
+	CreateReading(ctx context.Context, in *Reading, opts ...grpc.CallOption) (*CreateReadingResponse, error)
+	// StreamReadings menerima banyak data sensor dalam satu stream dan
+	// mengembalikan ringkasan setelah client menutup stream.
+	StreamReadings(ctx context.Context, opts ...grpc.CallOption) (SensorService_StreamReadingsClient, error)
+	// SubscribeReadings mengirim setiap data sensor yang baru disimpan.
+	SubscribeReadings(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (SensorService_SubscribeReadingsClient, error)
+}
+
+type sensorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSensorServiceClient(cc grpc.ClientConnInterface) SensorServiceClient {
+	return &sensorServiceClient{cc}
+}
+
+func (c *sensorServiceClient) CreateReading(ctx context.Context, in *Reading, opts ...grpc.CallOption) (*CreateReadingResponse, error) {
+	out := new(CreateReadingResponse)
+	err := c.cc.Invoke(ctx, SensorService_CreateReading_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) StreamReadings(ctx context.Context, opts ...grpc.CallOption) (SensorService_StreamReadingsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SensorService_ServiceDesc.Streams[0], SensorService_StreamReadings_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sensorServiceStreamReadingsClient{stream}
+	return x, nil
+}
+
+type SensorService_StreamReadingsClient interface {
+	Send(*Reading) error
+	CloseAndRecv() (*StreamReadingsResponse, error)
+	grpc.ClientStream
+}
+
+type sensorServiceStreamReadingsClient struct {
+	grpc.ClientStream
+}
+
+func (x *sensorServiceStreamReadingsClient) Send(m *Reading) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *sensorServiceStreamReadingsClient) CloseAndRecv() (*StreamReadingsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(StreamReadingsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sensorServiceClient) SubscribeReadings(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (SensorService_SubscribeReadingsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SensorService_ServiceDesc.Streams[1], SensorService_SubscribeReadings_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sensorServiceSubscribeReadingsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SensorService_SubscribeReadingsClient interface {
+	Recv() (*ReadingEvent, error)
+	grpc.ClientStream
+}
+
+type sensorServiceSubscribeReadingsClient struct {
+	grpc.ClientStream
+}
+
+func (x *sensorServiceSubscribeReadingsClient) Recv() (*ReadingEvent, error) {
+	m := new(ReadingEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SensorServiceServer is the server API for SensorService service.
+// All implementations must embed UnimplementedSensorServiceServer
+// for forward compatibility
+type SensorServiceServer interface {
+	// CreateReading menyimpan satu data sensor.
+	CreateReading(context.Context, *Reading) (*CreateReadingResponse, error)
+	// StreamReadings menerima banyak data sensor dalam satu stream dan
+	// mengembalikan ringkasan setelah client menutup stream.
+	StreamReadings(SensorService_StreamReadingsServer) error
+	// SubscribeReadings mengirim setiap data sensor yang baru disimpan.
+	SubscribeReadings(*SubscribeRequest, SensorService_SubscribeReadingsServer) error
+	mustEmbedUnimplementedSensorServiceServer()
+}
+
+// UnimplementedSensorServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSensorServiceServer struct {
+}
+
+func (UnimplementedSensorServiceServer) CreateReading(context.Context, *Reading) (*CreateReadingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReading not implemented")
+}
+func (UnimplementedSensorServiceServer) StreamReadings(SensorService_StreamReadingsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamReadings not implemented")
+}
+func (UnimplementedSensorServiceServer) SubscribeReadings(*SubscribeRequest, SensorService_SubscribeReadingsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeReadings not implemented")
+}
+func (UnimplementedSensorServiceServer) mustEmbedUnimplementedSensorServiceServer() {}
+
+// UnsafeSensorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SensorServiceServer will
+// result in compilation errors.
+type UnsafeSensorServiceServer interface {
+	mustEmbedUnimplementedSensorServiceServer()
+}
+
+func RegisterSensorServiceServer(s grpc.ServiceRegistrar, srv SensorServiceServer) {
+	s.RegisterService(&SensorService_ServiceDesc, srv)
+}
+
+func _SensorService_CreateReading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Reading)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).CreateReading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_CreateReading_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).CreateReading(ctx, req.(*Reading))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_StreamReadings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SensorServiceServer).StreamReadings(&sensorServiceStreamReadingsServer{stream})
+}
+
+type SensorService_StreamReadingsServer interface {
+	SendAndClose(*StreamReadingsResponse) error
+	Recv() (*Reading, error)
+	grpc.ServerStream
+}
+
+type sensorServiceStreamReadingsServer struct {
+	grpc.ServerStream
+}
+
+func (x *sensorServiceStreamReadingsServer) SendAndClose(m *StreamReadingsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *sensorServiceStreamReadingsServer) Recv() (*Reading, error) {
+	m := new(Reading)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _SensorService_SubscribeReadings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SensorServiceServer).SubscribeReadings(m, &sensorServiceSubscribeReadingsServer{stream})
+}
+
+type SensorService_SubscribeReadingsServer interface {
+	Send(*ReadingEvent) error
+	grpc.ServerStream
+}
+
+type sensorServiceSubscribeReadingsServer struct {
+	grpc.ServerStream
+}
+
+func (x *sensorServiceSubscribeReadingsServer) Send(m *ReadingEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// SensorService_ServiceDesc is the grpc.ServiceDesc for SensorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SensorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "iot.v1.SensorService",
+	HandlerType: (*SensorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReading",
+			Handler:    _SensorService_CreateReading_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamReadings",
+			Handler:       _SensorService_StreamReadings_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SubscribeReadings",
+			Handler:       _SensorService_SubscribeReadings_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "iot.proto",
+}
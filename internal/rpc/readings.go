@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	beitianModels "iot-golang/internal/beitian/models"
+	bmpModels "iot-golang/internal/bmp/models"
+	"iot-golang/internal/broadcast"
+	inaModels "iot-golang/internal/ina/models"
+	pzemModels "iot-golang/internal/pzem/models"
+	"iot-golang/internal/rpc/pb"
+	thigrowModels "iot-golang/internal/thigrow/models"
+	thmModels "iot-golang/internal/thm/models"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// readingPayload mengubah pesan Reading ke CreatePayload sensor beserta nama
+// sensornya. Payload bernilai nil bila pesan tidak berisi jenis sensor.
+func readingPayload(reading *pb.Reading) (string, interface{}) {
+	switch value := reading.GetSensor().(type) {
+	case *pb.Reading_Beitian:
+		return "beitian", &beitianModels.CreatePayload{
+			DeviceToken: value.Beitian.GetDeviceToken(),
+			Latitude:    decimal(value.Beitian.Latitude),
+			Longitude:   decimal(value.Beitian.Longitude),
+			Battery:     decimal(value.Beitian.Battery),
+		}
+	case *pb.Reading_Bmp:
+		return "bmp", &bmpModels.CreatePayload{
+			DeviceToken:     value.Bmp.GetDeviceToken(),
+			TekananUdara:    decimal(value.Bmp.TekananUdara),
+			TinggiPermukaan: decimal(value.Bmp.TinggiPermukaan),
+			Battery:         decimal(value.Bmp.Battery),
+		}
+	case *pb.Reading_Ina:
+		return "ina", &inaModels.CreatePayload{
+			DeviceToken: value.Ina.GetDeviceToken(),
+			Tegangan:    decimal(value.Ina.Tegangan),
+			Arus:        decimal(value.Ina.Arus),
+			Daya:        decimal(value.Ina.Daya),
+		}
+	case *pb.Reading_Pzem:
+		return "pzem", &pzemModels.CreatePayload{
+			DeviceToken: value.Pzem.GetDeviceToken(),
+			Tegangan:    decimal(value.Pzem.Tegangan),
+			Arus:        decimal(value.Pzem.Arus),
+			Daya:        decimal(value.Pzem.Daya),
+		}
+	case *pb.Reading_Thigrow:
+		return "thigrow", &thigrowModels.CreatePayload{
+			DeviceToken:       value.Thigrow.GetDeviceToken(),
+			KelembabanTanahTh: value.Thigrow.GetKelembabanTanahTh(),
+			KelembabanTanahSm: value.Thigrow.GetKelembabanTanahSm(),
+			KelembabanUdara:   value.Thigrow.GetKelembabanUdara(),
+			IntensitasCahaya:  decimal(value.Thigrow.IntensitasCahaya),
+			Battery:           decimal(value.Thigrow.Battery),
+			Temperature:       decimal(value.Thigrow.Temperature),
+			KadarGaram:        decimal(value.Thigrow.KadarGaram),
+		}
+	case *pb.Reading_Thm:
+		return "thm", &thmModels.CreatePayload{
+			DeviceToken:     value.Thm.GetDeviceToken(),
+			Temperature:     decimal(value.Thm.Temperature),
+			KelembabanUdara: decimal(value.Thm.KelembabanUdara),
+			Battery:         decimal(value.Thm.Battery),
+		}
+	}
+
+	return "", nil
+}
+
+// decimal menulis nilai field protobuf sebagai string angka seperti payload
+// JSON. Field yang tidak diisi menjadi string kosong agar ditolak validasi
+// required.
+func decimal(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// number membaca nilai yang disimpan sebagai string, nilai yang tidak bisa
+// dibaca dikirim sebagai field kosong.
+func number(value string) *float64 {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+// readingMetadata menyusun kualitas, skor anomali dan waktu data. Data yang
+// dibuat satu per satu memakai waktu event karena created_at diisi database.
+func readingMetadata(quality string, anomalyScore float64, createdAt time.Time, event broadcast.Event) *pb.Metadata {
+	if createdAt.IsZero() {
+		createdAt = event.Time
+	}
+	return &pb.Metadata{Quality: quality, AnomalyScore: anomalyScore, CreatedAt: timestamppb.New(createdAt)}
+}
+
+// readingEvent mengubah event broadcast menjadi pesan ReadingEvent. ok bernilai
+// false untuk data dengan tipe yang tidak dikenali.
+func readingEvent(event broadcast.Event) (*pb.ReadingEvent, bool) {
+	reading := &pb.Reading{}
+	switch data := event.Reading.(type) {
+	case beitianModels.Beitian:
+		reading.Sensor = &pb.Reading_Beitian{Beitian: &pb.BeitianReading{
+			DeviceToken: data.DeviceToken,
+			Latitude:    number(data.Latitude),
+			Longitude:   number(data.Longitude),
+			Battery:     number(data.Battery),
+			Metadata:    readingMetadata(data.Quality, data.AnomalyScore, data.CreatedAt, event),
+		}}
+	case bmpModels.Bmp:
+		reading.Sensor = &pb.Reading_Bmp{Bmp: &pb.BmpReading{
+			DeviceToken:     data.DeviceToken,
+			TekananUdara:    number(data.TekananUdara),
+			TinggiPermukaan: number(data.TinggiPermukaan),
+			Battery:         number(data.Battery),
+			Metadata:        readingMetadata(data.Quality, data.AnomalyScore, data.CreatedAt, event),
+		}}
+	case inaModels.Ina:
+		reading.Sensor = &pb.Reading_Ina{Ina: &pb.InaReading{
+			DeviceToken: data.DeviceToken,
+			Tegangan:    number(data.Tegangan),
+			Arus:        number(data.Arus),
+			Daya:        number(data.Daya),
+			Metadata:    readingMetadata(data.Quality, data.AnomalyScore, data.CreatedAt, event),
+		}}
+	case pzemModels.Pzem:
+		reading.Sensor = &pb.Reading_Pzem{Pzem: &pb.PzemReading{
+			DeviceToken: data.DeviceToken,
+			Tegangan:    number(data.Tegangan),
+			Arus:        number(data.Arus),
+			Daya:        number(data.Daya),
+			Metadata:    readingMetadata(data.Quality, data.AnomalyScore, data.CreatedAt, event),
+		}}
+	case thigrowModels.Thigrow:
+		reading.Sensor = &pb.Reading_Thigrow{Thigrow: &pb.ThigrowReading{
+			DeviceToken:       data.DeviceToken,
+			KelembabanTanahTh: data.KelembabanTanahTh,
+			KelembabanTanahSm: data.KelembabanTanahSm,
+			KelembabanUdara:   data.KelembabanUdara,
+			IntensitasCahaya:  number(data.IntensitasCahaya),
+			Battery:           number(data.Battery),
+			Temperature:       number(data.Temperature),
+			KadarGaram:        number(data.KadarGaram),
+			Metadata:          readingMetadata(data.Quality, data.AnomalyScore, data.CreatedAt, event),
+		}}
+	case thmModels.Thm:
+		reading.Sensor = &pb.Reading_Thm{Thm: &pb.ThmReading{
+			DeviceToken:     data.DeviceToken,
+			Temperature:     number(data.Temperature),
+			KelembabanUdara: number(data.KelembabanUdara),
+			Battery:         number(data.Battery),
+			Metadata:        readingMetadata(data.Quality, data.AnomalyScore, data.CreatedAt, event),
+		}}
+	default:
+		return nil, false
+	}
+
+	return &pb.ReadingEvent{Sensor: event.Sensor, Reading: reading}, true
+}
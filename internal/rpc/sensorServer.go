@@ -0,0 +1,143 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iot-golang/internal/broadcast"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	ingestionServices "iot-golang/internal/ingestion/services"
+	"iot-golang/internal/rpc/pb"
+	"iot-golang/internal/sensors"
+	"iot-golang/internal/tracing"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// SensorServer mengimplementasikan pb.SensorServiceServer di atas service
+// ingestion yang memakai service sensor yang sama dengan route REST.
+type SensorServer struct {
+	pb.UnimplementedSensorServiceServer
+
+	logger           *slog.Logger
+	ingestionService ingestionServices.IngestionService
+	done             <-chan struct{}
+}
+
+// CreateReading implements pb.SensorServiceServer.
+func (server *SensorServer) CreateReading(ctx context.Context, reading *pb.Reading) (*pb.CreateReadingResponse, error) {
+	ctx, span := tracing.Start(ctx, "SensorServer.CreateReading")
+	defer span.End()
+
+	result, err := server.ingest(ctx, reading)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	return &pb.CreateReadingResponse{Code: result.Code, Message: result.Messages}, nil
+}
+
+// StreamReadings implements pb.SensorServiceServer.
+// Data yang ditolak tidak menghentikan stream, alasannya dikirim pada
+// ringkasan setelah client menutup stream.
+func (server *SensorServer) StreamReadings(stream pb.SensorService_StreamReadingsServer) error {
+	ctx, span := tracing.Start(stream.Context(), "SensorServer.StreamReadings")
+	defer span.End()
+
+	response := &pb.StreamReadingsResponse{RejectedReadings: []*pb.RejectedReading{}}
+	for index := int32(0); ; index++ {
+		reading, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		response.Total++
+		if _, err := server.ingest(ctx, reading); err != nil {
+			response.Rejected++
+			response.RejectedReadings = append(response.RejectedReadings, &pb.RejectedReading{Index: index, Reasons: rejectionReasons(ctx, err)})
+			continue
+		}
+		response.Inserted++
+	}
+
+	server.logger.InfoContext(ctx, "Stream data sensor selesai", "total", response.Total, "inserted", response.Inserted, "rejected", response.Rejected)
+	response.Message = i18n.T(ctx, i18n.GRPCStreamFinished, response.Inserted, response.Rejected)
+	return stream.SendAndClose(response)
+}
+
+// SubscribeReadings implements pb.SensorServiceServer.
+// Stream berakhir ketika client membatalkan request atau server berhenti.
+func (server *SensorServer) SubscribeReadings(request *pb.SubscribeRequest, stream pb.SensorService_SubscribeReadingsServer) error {
+	ctx := stream.Context()
+
+	for _, name := range request.GetSensors() {
+		if _, ok := sensors.Find(name); !ok {
+			return statusError(ctx, helpers.Validation(i18n.Msg(i18n.SensorUnknown, name), nil))
+		}
+	}
+
+	subscription := broadcast.Subscribe(broadcast.Filter{Sensors: request.GetSensors(), DeviceTokens: request.GetDeviceTokens()})
+	defer subscription.Close()
+
+	server.logger.InfoContext(ctx, "Subscriber data sensor terhubung", "sensors", request.GetSensors(), "device_tokens", request.GetDeviceTokens())
+	for {
+		select {
+		case <-ctx.Done():
+			server.logger.InfoContext(ctx, "Subscriber data sensor terputus")
+			return nil
+		case <-server.done:
+			return status.Error(codes.Unavailable, i18n.T(ctx, i18n.GRPCServerStopping))
+		case event := <-subscription.C:
+			if dropped := subscription.Dropped(); dropped > 0 {
+				server.logger.WarnContext(ctx, "Subscriber terlalu lambat, data sensor dibuang", "dropped", dropped)
+			}
+
+			message, ok := readingEvent(event)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ingest menyimpan satu data sensor lewat IngestionService.
+func (server *SensorServer) ingest(ctx context.Context, reading *pb.Reading) (helpers.Response, error) {
+	sensor, payload := readingPayload(reading)
+	if payload == nil {
+		return helpers.Response{}, helpers.Validation(i18n.Msg(i18n.GRPCReadingEmpty), nil)
+	}
+
+	return server.ingestionService.Create(ctx, sensor, payload)
+}
+
+// rejectionReasons menyusun alasan penolakan per field untuk ringkasan stream.
+// Error selain validasi dicatat pada key database seperti laporan import.
+func rejectionReasons(ctx context.Context, err error) map[string]string {
+	var appError *helpers.Error
+	if errors.As(err, &appError) && appError.Kind == helpers.KindValidation {
+		if errorList, ok := appError.Details.(map[string]string); ok && len(errorList) > 0 {
+			return errorList
+		}
+		return map[string]string{"reading": helpers.ErrorMessage(ctx, err)}
+	}
+
+	return map[string]string{"database": helpers.ErrorMessage(ctx, err)}
+}
+
+func NewSensorServer(db *gorm.DB, done <-chan struct{}, logger *slog.Logger) *SensorServer {
+	return &SensorServer{
+		logger:           logger,
+		ingestionService: ingestionServices.NewIngestionService(db, logger),
+		done:             done,
+	}
+}
@@ -0,0 +1,208 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/middleware"
+	"iot-golang/internal/rpc/pb"
+	"log/slog"
+	"net"
+	"sort"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const (
+	requestIDKey       = "x-request-id"
+	acceptLanguageKey  = "accept-language"
+	contentLanguageKey = "content-language"
+)
+
+// Server menjalankan server gRPC sebagai worker lifecycle sehingga ikut
+// berhenti bersama server HTTP.
+type Server struct {
+	logger  *slog.Logger
+	address string
+	server  *grpc.Server
+	done    chan struct{}
+}
+
+func (server *Server) Name() string {
+	return "grpc"
+}
+
+// Run membuka listener dan melayani request sampai ctx dibatalkan. Stream
+// subscription diakhiri lebih dulu agar GracefulStop tidak menunggu client
+// yang masih berlangganan.
+func (server *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", server.address)
+	if err != nil {
+		return fmt.Errorf("gagal membuka listener gRPC %s: %w", server.address, err)
+	}
+	server.logger.Info("Server gRPC berjalan", "address", listener.Addr().String())
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		close(server.done)
+		server.server.GracefulStop()
+		return nil
+	case err := <-serveErr:
+		return err
+	}
+}
+
+func NewServer(db *gorm.DB, address string, logger *slog.Logger) *Server {
+	logger = logger.With("component", "grpc")
+	server := &Server{
+		logger:  logger,
+		address: address,
+		done:    make(chan struct{}),
+	}
+
+	server.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryInterceptor(logger)),
+		grpc.ChainStreamInterceptor(streamInterceptor(logger)),
+	)
+	pb.RegisterSensorServiceServer(server.server, NewSensorServer(db, server.done, logger))
+	reflection.Register(server.server)
+
+	return server
+}
+
+// requestContext menyimpan request ID dan bahasa dari metadata gRPC ke context,
+// sama seperti middleware RequestID dan Language pada route HTTP.
+func requestContext(ctx context.Context) (context.Context, metadata.MD) {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := first(incoming.Get(requestIDKey))
+	if requestID == "" || len(requestID) > 128 {
+		requestID = middleware.NewRequestID()
+	}
+	lang := i18n.Match("", first(incoming.Get(acceptLanguageKey)))
+
+	ctx = logging.WithRequestID(ctx, requestID)
+	ctx = i18n.WithLanguage(ctx, lang)
+	return ctx, metadata.Pairs(requestIDKey, requestID, contentLanguageKey, lang)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func unaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		started := time.Now()
+		ctx, header := requestContext(ctx)
+		_ = grpc.SetHeader(ctx, header)
+
+		response, err := handler(ctx, request)
+		accessLog(ctx, logger, info.FullMethod, err, started)
+		return response, err
+	}
+}
+
+// contextStream mengganti context stream dengan context yang sudah berisi
+// request ID dan bahasa.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream contextStream) Context() context.Context {
+	return stream.ctx
+}
+
+func streamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		started := time.Now()
+		ctx, header := requestContext(stream.Context())
+		_ = stream.SetHeader(header)
+
+		err := handler(srv, contextStream{ServerStream: stream, ctx: ctx})
+		accessLog(ctx, logger, info.FullMethod, err, started)
+		return err
+	}
+}
+
+// accessLog mencatat setiap request gRPC beserta kode status dan durasinya.
+func accessLog(ctx context.Context, logger *slog.Logger, method string, err error, started time.Time) {
+	code := status.Code(err)
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.Unavailable:
+		level = slog.LevelWarn
+	default:
+		level = slog.LevelError
+	}
+
+	logger.Log(ctx, level, "Request gRPC", "method", method, "code", code.String(), logging.Duration(time.Since(started)))
+}
+
+// statusError memetakan error service ke status gRPC. Pesan diterjemahkan
+// sesuai bahasa request dan error per field dikirim sebagai BadRequest.
+func statusError(ctx context.Context, err error) error {
+	var appError *helpers.Error
+	if !errors.As(err, &appError) {
+		return status.Error(codes.Internal, i18n.T(ctx, i18n.HTTPInternalError))
+	}
+
+	code := codes.Internal
+	switch appError.Kind {
+	case helpers.KindValidation:
+		code = codes.InvalidArgument
+	case helpers.KindNotFound:
+		code = codes.NotFound
+	case helpers.KindConflict:
+		code = codes.AlreadyExists
+	default:
+		switch helpers.ErrorStatus(appError.Err) {
+		case 504:
+			code = codes.DeadlineExceeded
+		case 503:
+			code = codes.Canceled
+		}
+	}
+
+	result := status.New(code, appError.Message.Translate(i18n.Language(ctx)))
+	errorList, ok := appError.Details.(map[string]string)
+	if !ok || len(errorList) == 0 {
+		return result.Err()
+	}
+
+	fields := make([]string, 0, len(errorList))
+	for field := range errorList {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: field, Description: errorList[field]})
+	}
+	if detailed, err := result.WithDetails(badRequest); err == nil {
+		result = detailed
+	}
+
+	return result.Err()
+}
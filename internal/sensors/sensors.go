@@ -0,0 +1,47 @@
+package sensors
+
+import (
+	beitianModels "iot-golang/internal/beitian/models"
+	bmpModels "iot-golang/internal/bmp/models"
+	"iot-golang/internal/helpers"
+	inaModels "iot-golang/internal/ina/models"
+	pzemModels "iot-golang/internal/pzem/models"
+	thigrowModels "iot-golang/internal/thigrow/models"
+	thmModels "iot-golang/internal/thm/models"
+)
+
+// Sensor berisi informasi tabel dan field untuk satu jenis sensor.
+type Sensor struct {
+	Name   string
+	Table  string
+	Fields []helpers.Field
+}
+
+// All berisi seluruh jenis sensor dengan urutan yang sama seperti route di main.go.
+var All = []Sensor{
+	{Name: "beitian", Table: beitianModels.Beitian{}.TableName(), Fields: beitianModels.Fields},
+	{Name: "bmp", Table: bmpModels.Bmp{}.TableName(), Fields: bmpModels.Fields},
+	{Name: "ina", Table: inaModels.Ina{}.TableName(), Fields: inaModels.Fields},
+	{Name: "pzem", Table: pzemModels.Pzem{}.TableName(), Fields: pzemModels.Fields},
+	{Name: "thigrow", Table: thigrowModels.Thigrow{}.TableName(), Fields: thigrowModels.Fields},
+	{Name: "thm", Table: thmModels.Thm{}.TableName(), Fields: thmModels.Fields},
+}
+
+// Names mengembalikan nama seluruh jenis sensor.
+func Names() []string {
+	names := make([]string, len(All))
+	for i, sensor := range All {
+		names[i] = sensor.Name
+	}
+	return names
+}
+
+// Find mencari jenis sensor berdasarkan nama.
+func Find(name string) (Sensor, bool) {
+	for _, sensor := range All {
+		if sensor.Name == name {
+			return sensor, true
+		}
+	}
+	return Sensor{}, false
+}
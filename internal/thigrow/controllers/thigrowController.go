@@ -1,12 +1,14 @@
 package controllers
 
 import (
-	"fmt"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/thigrow/models"
 	"iot-golang/internal/thigrow/services"
+	"log/slog"
 	"net/http"
-	"reflect"
 	"strconv"
 
 	v1 "github.com/go-playground/validator/v10"
@@ -15,188 +17,190 @@ import (
 )
 
 type ThigrowController struct {
+	logger         *slog.Logger
 	thigrowService services.ThigrowService
 	validate       v1.Validate
 }
 
-var httpStatus int
-
 func (controller ThigrowController) Create(c echo.Context) error {
-	type payload struct {
-		DeviceToken       string `json:"device_token" validate:"required"`
-		KelembabanTanahTh int32  `json:"kelembaban_tanah_th" validate:"required"`
-		KelembabanTanahSm int32  `json:"kelembaban_tanah_sm" validate:"required"`
-		KelembabanUdara   int32  `json:"kelembaban_udara" validate:"required"`
-		IntensitasCahaya  string `json:"intensitas_cahaya" validate:"required"`
-		Battery           string `json:"battery" validate:"required"`
-		Temperature       string `json:"temperature" validate:"required"`
-		KadarGaram        string `json:"kadar_garam" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.CreatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		if err := c.Bind(payloadValidator); err != nil {
-			return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-				Status:   400,
-				Errors:   err,
-				Messages: "Invalid request body",
-			})
-		}
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
 	}
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
-
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+		metrics.RecordValidationRejections("thigrow", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
-	result := controller.thigrowService.Create(models.Thigrow{DeviceToken: payloadValidator.DeviceToken, KelembabanTanahTh: payloadValidator.KelembabanTanahTh, KelembabanTanahSm: payloadValidator.KelembabanTanahSm, KelembabanUdara: payloadValidator.KelembabanUdara, IntensitasCahaya: payloadValidator.IntensitasCahaya, Battery: payloadValidator.Battery, Temperature: payloadValidator.Temperature, KadarGaram: payloadValidator.KadarGaram})
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("thigrow", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-	if result.Status == 201 {
-		httpStatus = http.StatusCreated
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	reading := payloadValidator.ToModel()
+	reading.Quality = report.Quality
+	result, err := controller.thigrowService.Create(c.Request().Context(), reading)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusCreated)
 }
 
 func (controller ThigrowController) Update(c echo.Context) error {
-	type payload struct {
-		KelembabanTanahTh int32  `json:"kelembaban_tanah_th" validate:"required"`
-		KelembabanTanahSm int32  `json:"kelembaban_tanah_sm" validate:"required"`
-		KelembabanUdara   int32  `json:"kelembaban_udara" validate:"required"`
-		IntensitasCahaya  string `json:"intensitas_cahaya" validate:"required"`
-		Battery           string `json:"battery" validate:"required"`
-		Temperature       string `json:"temperature" validate:"required"`
-		KadarGaram        string `json:"kadar_garam" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.UpdatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		if err := c.Bind(payloadValidator); err != nil {
-			return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-				Status:   400,
-				Errors:   err,
-				Messages: "Invalid request body",
-			})
-		}
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
 	}
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
+
+		metrics.RecordValidationRejections("thigrow", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("thigrow", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
 	idThigrow, _ := strconv.Atoi(c.Param("id"))
-	result := controller.thigrowService.Update(int64(idThigrow), models.Thigrow{KelembabanTanahTh: payloadValidator.KelembabanTanahTh, KelembabanTanahSm: payloadValidator.KelembabanTanahSm, KelembabanUdara: payloadValidator.KelembabanUdara, IntensitasCahaya: payloadValidator.IntensitasCahaya, Battery: payloadValidator.Battery, Temperature: payloadValidator.Temperature, KadarGaram: payloadValidator.KadarGaram})
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.thigrowService.Update(c.Request().Context(), int64(idThigrow), models.Thigrow{KelembabanTanahTh: payloadValidator.KelembabanTanahTh, KelembabanTanahSm: payloadValidator.KelembabanTanahSm, KelembabanUdara: payloadValidator.KelembabanUdara, IntensitasCahaya: payloadValidator.IntensitasCahaya, Battery: payloadValidator.Battery, Temperature: payloadValidator.Temperature, KadarGaram: payloadValidator.KadarGaram, Quality: report.Quality})
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller ThigrowController) Delete(c echo.Context) error {
 	idThigrow, _ := strconv.Atoi(c.Param("id"))
-	result := controller.thigrowService.Delete(int64(idThigrow))
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.thigrowService.Delete(c.Request().Context(), int64(idThigrow))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller ThigrowController) GetAll(c echo.Context) error {
-	result := controller.thigrowService.GetAll()
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
 	}
-	return c.JSON(httpStatus, result)
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	result, err := controller.thigrowService.GetAll(c.Request().Context(), quality)
+	if err != nil {
+		return err
+	}
+
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller ThigrowController) GetById(c echo.Context) error {
-	idThigrow, _ := strconv.Atoi(c.QueryParam("id"))
-	result := controller.thigrowService.GetById(int64(idThigrow))
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	idThigrow, _ := strconv.Atoi(c.QueryParam("id"))
+	result, err := controller.thigrowService.GetById(c.Request().Context(), int64(idThigrow))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller ThigrowController) GetByToken(c echo.Context) error {
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
 	idTokenThigrow := c.QueryParam("device_token")
-	result := controller.thigrowService.GetByToken(idTokenThigrow)
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.thigrowService.GetByToken(c.Request().Context(), idTokenThigrow, quality)
+	if err != nil {
+		return err
+	}
+
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller ThigrowController) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = helpers.ExportCSV
 	}
 
-	return c.JSON(httpStatus, result)
+	contentType, err := helpers.ExportContentType(format)
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"format": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	response := helpers.NewExportResponse(c.Response(), contentType, helpers.ExportFilename("thigrow", start, end, format))
+	err = controller.thigrowService.Export(c.Request().Context(), c.QueryParam("device_token"), start, end, quality, units, format, response)
+	if err != nil && c.Response().Committed {
+		// Status 200 sudah terkirim, koneksi diputus agar client tidak
+		// menganggap file yang terpotong sebagai file lengkap.
+		controller.logger.ErrorContext(c.Request().Context(), "Ekspor terhenti setelah response dikirim", logging.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+	return err
 }
 
-func NewThigrowController(db *gorm.DB) ThigrowController {
-	service := services.NewThigrowService(db)
+func NewThigrowController(db *gorm.DB, logger *slog.Logger) ThigrowController {
+	service := services.NewThigrowService(db, logger)
 	controller := ThigrowController{
+		logger:         logger.With("sensor", "thigrow"),
 		thigrowService: service,
 		validate:       *v1.New(),
 	}
@@ -1,17 +1,38 @@
 package models
 
+import (
+	"iot-golang/internal/helpers"
+	"time"
+)
+
 type Thigrow struct {
-	Id                int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	DeviceToken       string `json:"device_token" gorm:"column:device_token"`
-	KelembabanTanahTh int32  `json:"kelembaban_tanah_th" gorm:"column:kelembaban_tanah_th"`
-	KelembabanTanahSm int32  `json:"kelembaban_tanah_sm" gorm:"column:kelembaban_tanah_sm"`
-	KelembabanUdara   int32  `json:"kelembaban_udara" gorm:"column:kelembaban_udara"`
-	IntensitasCahaya  string `json:"intensitas_cahaya" gorm:"column:i_cahaya"`
-	Battery           string `json:"battery" gorm:"column:battery"`
-	Temperature       string `json:"temperature" gorm:"column:temperature"`
-	KadarGaram        string `json:"kadar_garam" gorm:"column:kadar_garam"`
+	Id                int64             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken       string            `json:"device_token" gorm:"column:device_token"`
+	KelembabanTanahTh int32             `json:"kelembaban_tanah_th" gorm:"column:kelembaban_tanah_th"`
+	KelembabanTanahSm int32             `json:"kelembaban_tanah_sm" gorm:"column:kelembaban_tanah_sm"`
+	KelembabanUdara   int32             `json:"kelembaban_udara" gorm:"column:kelembaban_udara"`
+	IntensitasCahaya  string            `json:"intensitas_cahaya" gorm:"column:i_cahaya"`
+	Battery           string            `json:"battery" gorm:"column:battery"`
+	Temperature       string            `json:"temperature" gorm:"column:temperature"`
+	KadarGaram        string            `json:"kadar_garam" gorm:"column:kadar_garam"`
+	Quality           string            `json:"quality" gorm:"column:quality;default:good"`
+	AnomalyScore      float64           `json:"anomaly_score" gorm:"column:anomaly_score;default:0"`
+	RawValues         map[string]string `json:"raw_values,omitempty" gorm:"column:raw_values;serializer:json"`
+	CreatedAt         time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 }
 
 func (Thigrow) TableName() string {
 	return "db_sensor_thigrow"
 }
+
+// Fields berisi metadata field sensor thigrow beserta satuan, rentang nilai dan
+// batas perubahan per menitnya.
+var Fields = []helpers.Field{
+	{Name: "kelembaban_tanah_th", Column: "kelembaban_tanah_th", Unit: "%", Range: helpers.Between(0, 100), MaxRate: 20, Integer: true},
+	{Name: "kelembaban_tanah_sm", Column: "kelembaban_tanah_sm", Unit: "%", Range: helpers.Between(0, 100), MaxRate: 20, Integer: true},
+	{Name: "kelembaban_udara", Column: "kelembaban_udara", Unit: "%", Range: helpers.Between(0, 100), MaxRate: 20, Integer: true},
+	{Name: "intensitas_cahaya", Column: "i_cahaya", Unit: "lx", Range: helpers.Between(0, 120000)},
+	{Name: "battery", Column: "battery", Unit: "V", Range: helpers.Between(0, 12).Suspect(3, 4.5), MaxRate: 0.5},
+	{Name: "temperature", Column: "temperature", Unit: "°C", Range: helpers.Between(-40, 85).Suspect(-10, 60), MaxRate: 5},
+	{Name: "kadar_garam", Column: "kadar_garam", Unit: "µS/cm", Range: helpers.Between(0, 20000)},
+}
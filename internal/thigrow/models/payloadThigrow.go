@@ -0,0 +1,38 @@
+package models
+
+// CreatePayload adalah payload untuk membuat data sensor thigrow baru.
+// Aturan validasi yang sama dipakai oleh endpoint create dan import CSV.
+type CreatePayload struct {
+	DeviceToken       string `json:"device_token" validate:"required"`
+	KelembabanTanahTh int32  `json:"kelembaban_tanah_th" validate:"required"`
+	KelembabanTanahSm int32  `json:"kelembaban_tanah_sm" validate:"required"`
+	KelembabanUdara   int32  `json:"kelembaban_udara" validate:"required"`
+	IntensitasCahaya  string `json:"intensitas_cahaya" validate:"required,numeric"`
+	Battery           string `json:"battery" validate:"required,numeric"`
+	Temperature       string `json:"temperature" validate:"required,numeric"`
+	KadarGaram        string `json:"kadar_garam" validate:"required,numeric"`
+}
+
+func (payload CreatePayload) ToModel() Thigrow {
+	return Thigrow{
+		DeviceToken:       payload.DeviceToken,
+		KelembabanTanahTh: payload.KelembabanTanahTh,
+		KelembabanTanahSm: payload.KelembabanTanahSm,
+		KelembabanUdara:   payload.KelembabanUdara,
+		IntensitasCahaya:  payload.IntensitasCahaya,
+		Battery:           payload.Battery,
+		Temperature:       payload.Temperature,
+		KadarGaram:        payload.KadarGaram,
+	}
+}
+
+// UpdatePayload adalah payload untuk mengubah data sensor thigrow.
+type UpdatePayload struct {
+	KelembabanTanahTh int32  `json:"kelembaban_tanah_th" validate:"required"`
+	KelembabanTanahSm int32  `json:"kelembaban_tanah_sm" validate:"required"`
+	KelembabanUdara   int32  `json:"kelembaban_udara" validate:"required"`
+	IntensitasCahaya  string `json:"intensitas_cahaya" validate:"required,numeric"`
+	Battery           string `json:"battery" validate:"required,numeric"`
+	Temperature       string `json:"temperature" validate:"required,numeric"`
+	KadarGaram        string `json:"kadar_garam" validate:"required,numeric"`
+}
@@ -1,7 +1,13 @@
 package repositories
 
 import (
+	"context"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/thigrow/models"
+	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,50 +17,118 @@ type dbThigrow struct {
 }
 
 // GetByToken implements ThigrowRepository.
-func (db *dbThigrow) GetByToken(DeviceToken string) ([]models.Thigrow, error) {
+func (db *dbThigrow) GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Thigrow, error) {
 	var data []models.Thigrow
-	result := db.Conn.Where("device_token", DeviceToken).Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "GetByToken")).Where("device_token", DeviceToken).Scopes(helpers.QualityScope(Quality)).Find(&data)
 	return data, result.Error
 }
 
 // Create implements ThigrowRepository.
-func (db *dbThigrow) Create(thigrow models.Thigrow) error {
-	return db.Conn.Create(&thigrow).Error
+func (db *dbThigrow) Create(ctx context.Context, thigrow models.Thigrow) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "Create")).Create(&thigrow).Error
+}
+
+// CreateBatch implements ThigrowRepository.
+func (db *dbThigrow) CreateBatch(ctx context.Context, thigrow []models.Thigrow, BatchSize int) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "CreateBatch")).CreateInBatches(&thigrow, BatchSize).Error
 }
 
 // Delete implements ThigrowRepository.
-func (db *dbThigrow) Delete(Id int64) error {
-	return db.Conn.Delete(&models.Thigrow{Id: Id}).Error
+func (db *dbThigrow) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "Delete")).Delete(&models.Thigrow{Id: Id}).Error
 }
 
 // GetAll implements ThigrowRepository.
-func (db *dbThigrow) GetAll() ([]models.Thigrow, error) {
+func (db *dbThigrow) GetAll(ctx context.Context, Quality []string) ([]models.Thigrow, error) {
+	var data []models.Thigrow
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "GetAll")).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// Query implements ThigrowRepository.
+func (db *dbThigrow) Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Thigrow, error) {
 	var data []models.Thigrow
-	result := db.Conn.Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "Query")).Scopes(Query.Scope).Find(&data)
 	return data, result.Error
 }
 
 // GetById implements ThigrowRepository.
-func (db *dbThigrow) GetById(Id int64) (models.Thigrow, error) {
+func (db *dbThigrow) GetById(ctx context.Context, Id int64) (models.Thigrow, error) {
 	var data models.Thigrow
-	result := db.Conn.Where("id", Id).First(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "GetById")).Where("id", Id).First(&data)
+	return data, result.Error
+}
+
+// GetNewByToken implements ThigrowRepository.
+func (db *dbThigrow) GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Thigrow, error) {
+	var data []models.Thigrow
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "GetNewByToken")).Where("device_token = ?", DeviceToken).Order("id desc").Limit(1).Find(&data)
 	return data, result.Error
 }
 
+// GetNewByTokens implements ThigrowRepository.
+// Data terbaru setiap perangkat diambil dalam satu query dengan subquery id
+// terbesar per device_token.
+func (db *dbThigrow) GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Thigrow, error) {
+	var data []models.Thigrow
+	latest := db.Conn.Model(&models.Thigrow{}).Select("MAX(id)").Where("device_token IN ?", DeviceTokens).Group("device_token")
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "GetNewByTokens")).Where("id IN (?)", latest).Find(&data)
+	return data, result.Error
+}
+
+// Export implements ThigrowRepository.
+// Data dibaca memakai cursor Rows sehingga tidak dimuat seluruhnya ke memori.
+func (db *dbThigrow) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(thigrow models.Thigrow) error) error {
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "Export")).Model(&models.Thigrow{}).Where("created_at >= ? AND created_at < ?", Start, End).Scopes(helpers.QualityScope(Quality))
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+
+	rows, err := query.Order("created_at asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data models.Thigrow
+		if err := db.Conn.ScanRows(rows, &data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 // Update implements ThigrowRepository.
-func (db *dbThigrow) Update(Id int64, thigrow models.Thigrow) error {
-	return db.Conn.Where("id", Id).Updates(thigrow).Error
+// Kolom nilai, quality dan raw_values selalu ditulis, termasuk raw_values yang
+// kosong, agar nilai mentah lama tidak tertinggal setelah data diubah.
+func (db *dbThigrow) Update(ctx context.Context, Id int64, thigrow models.Thigrow) error {
+	columns := []string{"quality", "raw_values"}
+	for _, field := range models.Fields {
+		columns = append(columns, field.Column)
+	}
+
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "thigrow", "Update")).Model(&models.Thigrow{}).Where("id", Id).Select(columns).Updates(thigrow).Error
 }
 
 type ThigrowRepository interface {
-	Create(thigrow models.Thigrow) error
-	Update(Id int64, thigrow models.Thigrow) error
-	Delete(Id int64) error
-	GetById(Id int64) (models.Thigrow, error)
-	GetByToken(DeviceToken string) ([]models.Thigrow, error)
-	GetAll() ([]models.Thigrow, error)
+	Create(ctx context.Context, thigrow models.Thigrow) error
+	CreateBatch(ctx context.Context, thigrow []models.Thigrow, BatchSize int) error
+	Update(ctx context.Context, Id int64, thigrow models.Thigrow) error
+	Delete(ctx context.Context, Id int64) error
+	GetById(ctx context.Context, Id int64) (models.Thigrow, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Thigrow, error)
+	GetAll(ctx context.Context, Quality []string) ([]models.Thigrow, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Thigrow, error)
+	GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Thigrow, error)
+	GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Thigrow, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(thigrow models.Thigrow) error) error
 }
 
-func NewThigrowRepository(Conn *gorm.DB) ThigrowRepository {
-	return &dbThigrow{Conn: Conn}
+func NewThigrowRepository(Conn *gorm.DB, logger *slog.Logger) ThigrowRepository {
+	return &dbThigrow{Conn: logging.Session(Conn, logger.With("repository", "thigrow"))}
 }
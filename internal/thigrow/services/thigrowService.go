@@ -1,30 +1,46 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	anomalyModels "iot-golang/internal/anomaly/models"
+	anomalyServices "iot-golang/internal/anomaly/services"
+	"iot-golang/internal/broadcast"
+	calibrationServices "iot-golang/internal/calibration/services"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/thigrow/models"
 	"iot-golang/internal/thigrow/repositories"
-	"log"
+	"iot-golang/internal/tracing"
+	"log/slog"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type thigrowService struct {
-	thigrowRepo repositories.ThigrowRepository
+	logger             *slog.Logger
+	thigrowRepo        repositories.ThigrowRepository
+	anomalyService     anomalyServices.AnomalyService
+	calibrationService calibrationServices.CalibrationService
 }
 
 // GetByToken implements ThigrowService.
-func (service *thigrowService) GetByToken(DeviceToken string) helpers.Response {
+func (service *thigrowService) GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThigrowService.GetByToken")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.thigrowRepo.GetByToken(DeviceToken)
+	data, err := service.thigrowRepo.GetByToken(ctx, DeviceToken, Quality)
 
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil data sensor dengan token : %s, error: %v", DeviceToken, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan token : %s", DeviceToken)
-		return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByTokenFail, DeviceToken), err)
 	}
 
 	var foundData []models.Thigrow
@@ -44,147 +60,297 @@ func (service *thigrowService) GetByToken(DeviceToken string) helpers.Response {
 	}
 
 	if len(foundData) == 0 {
-		log.Printf("INFO: Tidak menemukan data sensor dengan token : %s", DeviceToken)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan token : %s", DeviceToken)
-	} else {
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Data = foundData
+		service.logger.InfoContext(ctx, "Tidak menemukan data sensor", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByToken, DeviceToken))
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorFoundByToken, DeviceToken)
+	response.Data = foundData
+
+	return response, nil
 }
 
 // Create implements ThigrowService.
-func (service *thigrowService) Create(thigrow models.Thigrow) helpers.Response {
+func (service *thigrowService) Create(ctx context.Context, thigrow models.Thigrow) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThigrowService.Create")
+	defer span.End()
+
 	var response helpers.Response
-	if err := service.thigrowRepo.Create(thigrow); err != nil {
-		log.Println("ERROR: Gagal membuat data sensor baru" + err.Error())
-		response.Status = 500
-		response.Messages = "Gagal membuat data sensor baru"
-	} else {
-		response.Status = 201
-		response.Messages = "Berhasil membuat data sensor baru"
-	}
-	return response
+	raw, err := service.calibrationService.Calibrator(ctx, "thigrow").Apply(thigrow.DeviceToken, time.Now(), &thigrow)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", thigrow.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+	if raw != nil {
+		thigrow.RawValues = raw
+		thigrow.Quality = helpers.AssessQuality(models.Fields, &thigrow).Quality
+	}
+
+	inspection := service.anomalyService.Inspect(ctx, "thigrow", thigrow.DeviceToken, thigrow.Quality, helpers.FieldValues(models.Fields, thigrow))
+	thigrow.AnomalyScore = inspection.Score
+
+	if err := service.thigrowRepo.Create(ctx, thigrow); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "device_token", thigrow.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspection)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "device_token", thigrow.DeviceToken)
+	metrics.RecordIngested("thigrow", thigrow.DeviceToken, 1)
+	broadcast.Publish("thigrow", thigrow.DeviceToken, thigrow)
+	response.SetMessage(ctx, i18n.SensorCreated)
+	return response, nil
+}
+
+// CreateBatch implements ThigrowService.
+func (service *thigrowService) CreateBatch(ctx context.Context, thigrow []models.Thigrow) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThigrowService.CreateBatch")
+	defer span.End()
+
+	var response helpers.Response
+	calibrator := service.calibrationService.Calibrator(ctx, "thigrow")
+	now := time.Now()
+	tokens := make([]string, len(thigrow))
+	readings := make([]anomalyModels.Reading, len(thigrow))
+	for i := range thigrow {
+		tokens[i] = thigrow[i].DeviceToken
+		// Data tanpa created_at akan diberi waktu sekarang oleh database
+		at := thigrow[i].CreatedAt
+		if at.IsZero() {
+			at = now
+		}
+		raw, err := calibrator.Apply(thigrow[i].DeviceToken, at, &thigrow[i])
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", thigrow[i].DeviceToken, logging.Error(err))
+			return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+		}
+		if raw != nil {
+			thigrow[i].RawValues = raw
+			thigrow[i].Quality = helpers.AssessQuality(models.Fields, &thigrow[i]).Quality
+		}
+		readings[i] = anomalyModels.Reading{DeviceToken: thigrow[i].DeviceToken, Quality: thigrow[i].Quality, Values: helpers.FieldValues(models.Fields, thigrow[i]), At: thigrow[i].CreatedAt}
+	}
+
+	inspections := service.anomalyService.InspectBatch(ctx, "thigrow", readings)
+	for i := range inspections {
+		thigrow[i].AnomalyScore = inspections[i].Score
+	}
+
+	if err := service.thigrowRepo.CreateBatch(ctx, thigrow, len(thigrow)); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "count", len(thigrow), "device_tokens", helpers.DistinctTokens(tokens), logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspections...)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "count", len(thigrow), "device_tokens", helpers.DistinctTokens(tokens))
+	for _, reading := range thigrow {
+		metrics.RecordIngested("thigrow", reading.DeviceToken, 1)
+		broadcast.Publish("thigrow", reading.DeviceToken, reading)
+	}
+	response.SetMessage(ctx, i18n.SensorCreated)
+
+	return response, nil
 }
 
 // Delete implements ThigrowService.
-func (service *thigrowService) Delete(Id int64) helpers.Response {
+func (service *thigrowService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThigrowService.Delete")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum dihapus
-	_, findErr := service.thigrowRepo.GetById(Id)
+	_, findErr := service.thigrowRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
 	}
 
 	// Data ditemukan, lakukan penghapusan
-	err := service.thigrowRepo.Delete(Id)
+	err := service.thigrowRepo.Delete(ctx, Id)
 	if err != nil {
-		log.Printf("ERROR: Gagal menghapus data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal menghapus data sensor dengan id : %d", Id)
-	} else {
-		// Jika penghapusan berhasil
-		response.Status = 200
-		response.Messages = "Data sensor berhasil dihapus"
+		service.logger.ErrorContext(ctx, "Gagal menghapus data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorDeleteFailed, Id), err)
 	}
 
-	return response
+	// Jika penghapusan berhasil
+	response.SetMessage(ctx, i18n.SensorDeleted)
+
+	return response, nil
 }
 
 // GetAll implements ThigrowService.
-func (service *thigrowService) GetAll() helpers.Response {
+func (service *thigrowService) GetAll(ctx context.Context, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThigrowService.GetAll")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.thigrowRepo.GetAll()
+	data, err := service.thigrowRepo.GetAll(ctx, Quality)
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil seluruh data sensor : error %v", err)
-		response.Status = 500
-		response.Messages = "Gagal mengambil seluruh data sensor"
-	} else {
-		response.Status = 200
-		response.Messages = "Berhasil mengambil semua data sensor"
-		response.Data = data
-	}
-	return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data sensor", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
+}
+
+// Query implements ThigrowService.
+// Data yang kosong tetap dikembalikan sebagai daftar kosong.
+func (service *thigrowService) Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThigrowService.Query")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.thigrowRepo.Query(ctx, Query)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", Query.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+	if data == nil {
+		data = []models.Thigrow{}
+	}
+
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
 }
 
 // GetById implements ThigrowService.
-func (service *thigrowService) GetById(Id int64) helpers.Response {
+func (service *thigrowService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThigrowService.GetById")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.thigrowRepo.GetById(Id)
+	data, err := service.thigrowRepo.GetById(ctx, Id)
 
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			log.Printf("INFO: Tidak menemukan data sensor dengan id : %d", Id)
-			response.Status = 404
-			response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		} else {
-			log.Printf("ERROR: Gagal mengambil data sensor dengan id : %d, error: %v", Id, err)
-			response.Status = 500
-			response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan id : %d", Id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.InfoContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
 		}
-	} else {
-		// Konversi string ke tipe data float64
-		intenCahaya, _ := strconv.ParseFloat(data.IntensitasCahaya, 64)
-		battery, _ := strconv.ParseFloat(data.Battery, 64)
-		temperature, _ := strconv.ParseFloat(data.Temperature, 64)
 
-		data.IntensitasCahaya = fmt.Sprintf("%.2f", intenCahaya)
-		data.Battery = fmt.Sprintf("%.2f", battery)
-		data.Temperature = fmt.Sprintf("%.2f", temperature)
-
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Data = data
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
 	}
 
-	return response
+	// Konversi string ke tipe data float64
+	intenCahaya, _ := strconv.ParseFloat(data.IntensitasCahaya, 64)
+	battery, _ := strconv.ParseFloat(data.Battery, 64)
+	temperature, _ := strconv.ParseFloat(data.Temperature, 64)
+
+	data.IntensitasCahaya = fmt.Sprintf("%.2f", intenCahaya)
+	data.Battery = fmt.Sprintf("%.2f", battery)
+	data.Temperature = fmt.Sprintf("%.2f", temperature)
+
+	response.SetMessage(ctx, i18n.SensorFoundByID, Id)
+	response.Data = data
+
+	return response, nil
 }
 
 // Update implements ThigrowService.
-func (service *thigrowService) Update(Id int64, thigrow models.Thigrow) helpers.Response {
+func (service *thigrowService) Update(ctx context.Context, Id int64, thigrow models.Thigrow) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThigrowService.Update")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum di update
-	_, findErr := service.thigrowRepo.GetById(Id)
+	current, findErr := service.thigrowRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
+	}
+
+	// Nilai baru dikalibrasi dengan kalibrasi yang berlaku pada waktu data
+	// tersebut dibuat, sama seperti saat data disimpan pertama kali
+	raw, err := service.calibrationService.Calibrator(ctx, "thigrow").Apply(current.DeviceToken, current.CreatedAt, &thigrow)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", current.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+	thigrow.RawValues = raw
+	if raw != nil {
+		thigrow.Quality = helpers.AssessQuality(models.Fields, &thigrow).Quality
 	}
 
 	// Data ditemukan, lakukan update
-	err := service.thigrowRepo.Update(Id, thigrow)
+	err = service.thigrowRepo.Update(ctx, Id, thigrow)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengubah data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+
+	// Jika update data berhasil
+	response.SetMessage(ctx, i18n.SensorUpdated)
+
+	return response, nil
+}
+
+// Export implements ThigrowService.
+func (service *thigrowService) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "ThigrowService.Export")
+	defer span.End()
+
+	// Writer baru dibuat setelah baris pertama terbaca sehingga kegagalan
+	// query tidak membuat header response terkirim lebih dulu.
+	var writer helpers.ExportWriter
+	open := func() (err error) {
+		if writer != nil {
+			return nil
+		}
+		if writer, err = helpers.NewExportWriter(Format, w, "thigrow"); err != nil {
+			return err
+		}
+		return writer.WriteRow(helpers.ExportHeaders(Units.Fields()))
+	}
+
+	count := 0
+	err := service.thigrowRepo.Export(ctx, DeviceToken, Start, End, Quality, func(data models.Thigrow) error {
+		if err := open(); err != nil {
+			return err
+		}
+		count++
+		Units.Apply(&data)
+		return writer.WriteRow([]string{strconv.FormatInt(data.Id, 10), data.DeviceToken, data.CreatedAt.Format(time.RFC3339), strconv.Itoa(int(data.KelembabanTanahTh)), strconv.Itoa(int(data.KelembabanTanahSm)), strconv.Itoa(int(data.KelembabanUdara)), data.IntensitasCahaya, data.Battery, data.Temperature, data.KadarGaram, data.Quality})
+	})
 	if err != nil {
-		log.Printf("ERROR: Gagal mengubah data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengubah data sensor dengan id : %d", Id)
-	} else {
-		// Jika update data berhasil
-		response.Status = 200
-		response.Messages = "Berhasil mengubah data sensor"
+		service.logger.ErrorContext(ctx, "Gagal mengekspor data sensor", "device_token", DeviceToken, logging.Error(err))
+		return err
+	}
+	if err := open(); err != nil {
+		return err
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengekspor data sensor", "count", count, "device_token", DeviceToken)
+	return writer.Close()
 }
 
 type ThigrowService interface {
-	Create(thigrow models.Thigrow) helpers.Response
-	Update(Id int64, thigrow models.Thigrow) helpers.Response
-	Delete(Id int64) helpers.Response
-	GetById(Id int64) helpers.Response
-	GetByToken(DeviceToken string) helpers.Response
-	GetAll() helpers.Response
+	Create(ctx context.Context, thigrow models.Thigrow) (helpers.Response, error)
+	CreateBatch(ctx context.Context, thigrow []models.Thigrow) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, thigrow models.Thigrow) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error)
+	GetAll(ctx context.Context, Quality []string) (helpers.Response, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error
 }
 
-func NewThigrowService(db *gorm.DB) ThigrowService {
-	return &thigrowService{thigrowRepo: repositories.NewThigrowRepository(db)}
+func NewThigrowService(db *gorm.DB, logger *slog.Logger) ThigrowService {
+	return &thigrowService{logger: logger.With("sensor", "thigrow"), thigrowRepo: repositories.NewThigrowRepository(db, logger), anomalyService: anomalyServices.NewAnomalyService(db, logger), calibrationService: calibrationServices.NewCalibrationService(db, logger)}
 }
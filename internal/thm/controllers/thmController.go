@@ -1,12 +1,14 @@
 package controllers
 
 import (
-	"fmt"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/thm/models"
 	"iot-golang/internal/thm/services"
+	"log/slog"
 	"net/http"
-	"reflect"
 	"strconv"
 
 	v1 "github.com/go-playground/validator/v10"
@@ -15,175 +17,190 @@ import (
 )
 
 type ThmController struct {
+	logger     *slog.Logger
 	thmService services.ThmService
 	validate   v1.Validate
 }
 
-var httpStatus int
-
 func (controller ThmController) Create(c echo.Context) error {
-	type payload struct {
-		DeviceToken     string `json:"device_token" validate:"required"`
-		Temperature     string `json:"temperature" validate:"required"`
-		KelembabanUdara string `json:"kelembaban_udara" validate:"required"`
-		Battery         string `json:"battery" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.CreatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		if err := c.Bind(payloadValidator); err != nil {
-			return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-				Status:   400,
-				Errors:   err,
-				Messages: "Invalid request body",
-			})
-		}
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
 	}
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
-
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+		metrics.RecordValidationRejections("thm", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
-	result := controller.thmService.Create(models.Thm{DeviceToken: payloadValidator.DeviceToken, Temperature: payloadValidator.Temperature, KelembabanUdara: payloadValidator.KelembabanUdara, Battery: payloadValidator.Battery})
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("thm", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-	if result.Status == 201 {
-		httpStatus = http.StatusCreated
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
+
+	reading := payloadValidator.ToModel()
+	reading.Quality = report.Quality
+	result, err := controller.thmService.Create(c.Request().Context(), reading)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusCreated)
 }
 
 func (controller ThmController) Update(c echo.Context) error {
-	type payload struct {
-		Temperature     string `json:"temperature" validate:"required"`
-		KelembabanUdara string `json:"kelembaban_udara" validate:"required"`
-		Battery         string `json:"battery" validate:"required"`
-	}
-
-	payloadValidator := new(payload)
+	payloadValidator := new(models.UpdatePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		if err := c.Bind(payloadValidator); err != nil {
-			return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-				Status:   400,
-				Errors:   err,
-				Messages: "Invalid request body",
-			})
-		}
+		return helpers.Validation(i18n.Msg(i18n.RequestInvalidBody), err.Error())
 	}
 
 	err := controller.validate.Struct(payloadValidator)
 	if err != nil {
-		errors := err.(v1.ValidationErrors)
-		errorList := make(map[string]string)
+		errorList := helpers.ValidationErrorList(c.Request().Context(), err, payloadValidator)
+
+		metrics.RecordValidationRejections("thm", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Request di tolak", "status", http.StatusBadRequest, "errors", errorList)
 
-		for _, e := range errors {
-			var errMsg string
-			field, _ := reflect.TypeOf(*payloadValidator).FieldByName(e.StructField())
-			fieldName := field.Tag.Get("json")
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
+	}
 
-			if e.Tag() == "required" {
-				errMsg = fmt.Sprintf("Field %s tidak boleh kosong", fieldName)
-			}
-			errorList[fieldName] = errMsg
-		}
+	report := helpers.AssessQuality(models.Fields, payloadValidator)
+	if report.Rejected() {
+		errorList := report.ErrorList(c.Request().Context())
+		metrics.RecordValidationRejections("thm", errorList)
+		controller.logger.WarnContext(c.Request().Context(), "Data di luar rentang fisik", "status", http.StatusBadRequest, "errors", errorList)
 
-		return c.JSON(http.StatusBadRequest, helpers.ValidationResponse{
-			Status:   400,
-			Errors:   errorList,
-			Messages: "Request di tolak",
-		})
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), errorList)
 	}
 
 	idThm, _ := strconv.Atoi(c.Param("id"))
-	result := controller.thmService.Update(int64(idThm), models.Thm{Temperature: payloadValidator.Temperature, KelembabanUdara: payloadValidator.KelembabanUdara, Battery: payloadValidator.Battery})
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
+	result, err := controller.thmService.Update(c.Request().Context(), int64(idThm), models.Thm{Temperature: payloadValidator.Temperature, KelembabanUdara: payloadValidator.KelembabanUdara, Battery: payloadValidator.Battery, Quality: report.Quality})
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller ThmController) Delete(c echo.Context) error {
 	idThm, _ := strconv.Atoi(c.Param("id"))
-	result := controller.thmService.Delete(int64(idThm))
-
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
+	result, err := controller.thmService.Delete(c.Request().Context(), int64(idThm))
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller ThmController) GetAll(c echo.Context) error {
-	result := controller.thmService.GetAll()
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	result, err := controller.thmService.GetAll(c.Request().Context(), quality)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(httpStatus, result)
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller ThmController) GetById(c echo.Context) error {
-	idThm, _ := strconv.Atoi(c.QueryParam("id"))
-	result := controller.thmService.GetById(int64(idThm))
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
-	} else if result.Status == 500 {
-		httpStatus = http.StatusInternalServerError
+	idThm, _ := strconv.Atoi(c.QueryParam("id"))
+	result, err := controller.thmService.GetById(c.Request().Context(), int64(idThm))
+	if err != nil {
+		return err
 	}
-	return c.JSON(httpStatus, result)
+
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
 }
 
 func (controller ThmController) GetByToken(c echo.Context) error {
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
 	idTokenBmp := c.QueryParam("device_token")
-	result := controller.thmService.GetByToken(idTokenBmp)
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
 
-	if result.Status == 200 {
-		httpStatus = http.StatusOK
-	} else if result.Status == 404 {
-		httpStatus = http.StatusNotFound
+	result, err := controller.thmService.GetByToken(c.Request().Context(), idTokenBmp, quality)
+	if err != nil {
+		return err
+	}
+
+	units.Convert(&result)
+	return result.JSON(c, http.StatusOK)
+}
+
+func (controller ThmController) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = helpers.ExportCSV
 	}
 
-	return c.JSON(httpStatus, result)
+	contentType, err := helpers.ExportContentType(format)
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"format": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	start, end, err := helpers.ParseTimeRange(c.QueryParam("start"), c.QueryParam("end"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), helpers.TimeRangeErrorList(c.Request().Context(), err))
+	}
+
+	quality, err := helpers.ParseQuality(c.QueryParam("quality"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"quality": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	units, err := helpers.ParseUnits(models.Fields, c.QueryParam("units"))
+	if err != nil {
+		return helpers.Validation(i18n.Msg(i18n.RequestRejected), map[string]string{"units": helpers.ErrorMessage(c.Request().Context(), err)})
+	}
+
+	response := helpers.NewExportResponse(c.Response(), contentType, helpers.ExportFilename("thm", start, end, format))
+	err = controller.thmService.Export(c.Request().Context(), c.QueryParam("device_token"), start, end, quality, units, format, response)
+	if err != nil && c.Response().Committed {
+		// Status 200 sudah terkirim, koneksi diputus agar client tidak
+		// menganggap file yang terpotong sebagai file lengkap.
+		controller.logger.ErrorContext(c.Request().Context(), "Ekspor terhenti setelah response dikirim", logging.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+	return err
 }
 
-func NewThmController(db *gorm.DB) ThmController {
-	service := services.NewThmService(db)
+func NewThmController(db *gorm.DB, logger *slog.Logger) ThmController {
+	service := services.NewThmService(db, logger)
 	controller := ThmController{
+		logger:     logger.With("sensor", "thm"),
 		thmService: service,
 		validate:   *v1.New(),
 	}
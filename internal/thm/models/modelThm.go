@@ -1,13 +1,30 @@
 package models
 
+import (
+	"iot-golang/internal/helpers"
+	"time"
+)
+
 type Thm struct {
-	Id              int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	DeviceToken     string `json:"device_token" gorm:"column:device_token"`
-	Temperature     string `json:"temperature" gorm:"column:temperature"`
-	KelembabanUdara string `json:"kelembaban_udara" gorm:"column:kelembaban_udara"`
-	Battery         string `json:"battery" gorm:"column:battery"`
+	Id              int64             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DeviceToken     string            `json:"device_token" gorm:"column:device_token"`
+	Temperature     string            `json:"temperature" gorm:"column:temperature"`
+	KelembabanUdara string            `json:"kelembaban_udara" gorm:"column:kelembaban_udara"`
+	Battery         string            `json:"battery" gorm:"column:battery"`
+	Quality         string            `json:"quality" gorm:"column:quality;default:good"`
+	AnomalyScore    float64           `json:"anomaly_score" gorm:"column:anomaly_score;default:0"`
+	RawValues       map[string]string `json:"raw_values,omitempty" gorm:"column:raw_values;serializer:json"`
+	CreatedAt       time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 }
 
 func (Thm) TableName() string {
 	return "db_sensor_thm30d"
 }
+
+// Fields berisi metadata field sensor thm beserta satuan, rentang nilai dan
+// batas perubahan per menitnya.
+var Fields = []helpers.Field{
+	{Name: "temperature", Column: "temperature", Unit: "°C", Range: helpers.Between(-40, 85).Suspect(-10, 60), MaxRate: 5},
+	{Name: "kelembaban_udara", Column: "kelembaban_udara", Unit: "%", Range: helpers.Between(0, 100), MaxRate: 20},
+	{Name: "battery", Column: "battery", Unit: "V", Range: helpers.Between(0, 12).Suspect(3, 4.5), MaxRate: 0.5},
+}
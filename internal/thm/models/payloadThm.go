@@ -0,0 +1,26 @@
+package models
+
+// CreatePayload adalah payload untuk membuat data sensor thm baru.
+// Aturan validasi yang sama dipakai oleh endpoint create dan import CSV.
+type CreatePayload struct {
+	DeviceToken     string `json:"device_token" validate:"required"`
+	Temperature     string `json:"temperature" validate:"required,numeric"`
+	KelembabanUdara string `json:"kelembaban_udara" validate:"required,numeric"`
+	Battery         string `json:"battery" validate:"required,numeric"`
+}
+
+func (payload CreatePayload) ToModel() Thm {
+	return Thm{
+		DeviceToken:     payload.DeviceToken,
+		Temperature:     payload.Temperature,
+		KelembabanUdara: payload.KelembabanUdara,
+		Battery:         payload.Battery,
+	}
+}
+
+// UpdatePayload adalah payload untuk mengubah data sensor thm.
+type UpdatePayload struct {
+	Temperature     string `json:"temperature" validate:"required,numeric"`
+	KelembabanUdara string `json:"kelembaban_udara" validate:"required,numeric"`
+	Battery         string `json:"battery" validate:"required,numeric"`
+}
@@ -1,7 +1,13 @@
 package repositories
 
 import (
+	"context"
+	"iot-golang/internal/helpers"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/thm/models"
+	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,50 +17,118 @@ type dbThm struct {
 }
 
 // Create implements ThmRepository.
-func (db *dbThm) Create(thm models.Thm) error {
-	return db.Conn.Create(&thm).Error
+func (db *dbThm) Create(ctx context.Context, thm models.Thm) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "Create")).Create(&thm).Error
+}
+
+// CreateBatch implements ThmRepository.
+func (db *dbThm) CreateBatch(ctx context.Context, thm []models.Thm, BatchSize int) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "CreateBatch")).CreateInBatches(&thm, BatchSize).Error
 }
 
 // Delete implements ThmRepository.
-func (db *dbThm) Delete(Id int64) error {
-	return db.Conn.Delete(&models.Thm{Id: Id}).Error
+func (db *dbThm) Delete(ctx context.Context, Id int64) error {
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "Delete")).Delete(&models.Thm{Id: Id}).Error
 }
 
 // GetAll implements ThmRepository.
-func (db *dbThm) GetAll() ([]models.Thm, error) {
+func (db *dbThm) GetAll(ctx context.Context, Quality []string) ([]models.Thm, error) {
+	var data []models.Thm
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "GetAll")).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// Query implements ThmRepository.
+func (db *dbThm) Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Thm, error) {
 	var data []models.Thm
-	result := db.Conn.Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "Query")).Scopes(Query.Scope).Find(&data)
 	return data, result.Error
 }
 
 // GetById implements ThmRepository.
-func (db *dbThm) GetById(Id int64) (models.Thm, error) {
+func (db *dbThm) GetById(ctx context.Context, Id int64) (models.Thm, error) {
 	var data models.Thm
-	result := db.Conn.Where("id", Id).First(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "GetById")).Where("id", Id).First(&data)
 	return data, result.Error
 }
 
 // GetByToken implements ThmRepository.
-func (db *dbThm) GetByToken(DeviceToken string) ([]models.Thm, error) {
+func (db *dbThm) GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Thm, error) {
+	var data []models.Thm
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "GetByToken")).Where("device_token", DeviceToken).Scopes(helpers.QualityScope(Quality)).Find(&data)
+	return data, result.Error
+}
+
+// GetNewByToken implements ThmRepository.
+func (db *dbThm) GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Thm, error) {
 	var data []models.Thm
-	result := db.Conn.Where("device_token", DeviceToken).Find(&data)
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "GetNewByToken")).Where("device_token = ?", DeviceToken).Order("id desc").Limit(1).Find(&data)
 	return data, result.Error
 }
 
+// GetNewByTokens implements ThmRepository.
+// Data terbaru setiap perangkat diambil dalam satu query dengan subquery id
+// terbesar per device_token.
+func (db *dbThm) GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Thm, error) {
+	var data []models.Thm
+	latest := db.Conn.Model(&models.Thm{}).Select("MAX(id)").Where("device_token IN ?", DeviceTokens).Group("device_token")
+	result := db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "GetNewByTokens")).Where("id IN (?)", latest).Find(&data)
+	return data, result.Error
+}
+
+// Export implements ThmRepository.
+// Data dibaca memakai cursor Rows sehingga tidak dimuat seluruhnya ke memori.
+func (db *dbThm) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(thm models.Thm) error) error {
+	query := db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "Export")).Model(&models.Thm{}).Where("created_at >= ? AND created_at < ?", Start, End).Scopes(helpers.QualityScope(Quality))
+	if DeviceToken != "" {
+		query = query.Where("device_token = ?", DeviceToken)
+	}
+
+	rows, err := query.Order("created_at asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data models.Thm
+		if err := db.Conn.ScanRows(rows, &data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 // Update implements ThmRepository.
-func (db *dbThm) Update(Id int64, thm models.Thm) error {
-	return db.Conn.Where("id", Id).Updates(thm).Error
+// Kolom nilai, quality dan raw_values selalu ditulis, termasuk raw_values yang
+// kosong, agar nilai mentah lama tidak tertinggal setelah data diubah.
+func (db *dbThm) Update(ctx context.Context, Id int64, thm models.Thm) error {
+	columns := []string{"quality", "raw_values"}
+	for _, field := range models.Fields {
+		columns = append(columns, field.Column)
+	}
+
+	return db.Conn.WithContext(metrics.WithQuery(ctx, "thm", "Update")).Model(&models.Thm{}).Where("id", Id).Select(columns).Updates(thm).Error
 }
 
 type ThmRepository interface {
-	Create(thm models.Thm) error
-	Update(Id int64, thm models.Thm) error
-	Delete(Id int64) error
-	GetById(Id int64) (models.Thm, error)
-	GetByToken(DeviceToken string) ([]models.Thm, error)
-	GetAll() ([]models.Thm, error)
+	Create(ctx context.Context, thm models.Thm) error
+	CreateBatch(ctx context.Context, thm []models.Thm, BatchSize int) error
+	Update(ctx context.Context, Id int64, thm models.Thm) error
+	Delete(ctx context.Context, Id int64) error
+	GetById(ctx context.Context, Id int64) (models.Thm, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) ([]models.Thm, error)
+	GetAll(ctx context.Context, Quality []string) ([]models.Thm, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) ([]models.Thm, error)
+	GetNewByToken(ctx context.Context, DeviceToken string) ([]models.Thm, error)
+	GetNewByTokens(ctx context.Context, DeviceTokens []string) ([]models.Thm, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, fn func(thm models.Thm) error) error
 }
 
-func NewThmRepository(Conn *gorm.DB) ThmRepository {
-	return &dbThm{Conn: Conn}
+func NewThmRepository(Conn *gorm.DB, logger *slog.Logger) ThmRepository {
+	return &dbThm{Conn: logging.Session(Conn, logger.With("repository", "thm"))}
 }
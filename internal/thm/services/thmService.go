@@ -1,125 +1,234 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	anomalyModels "iot-golang/internal/anomaly/models"
+	anomalyServices "iot-golang/internal/anomaly/services"
+	"iot-golang/internal/broadcast"
+	calibrationServices "iot-golang/internal/calibration/services"
 	"iot-golang/internal/helpers"
+	"iot-golang/internal/i18n"
+	"iot-golang/internal/logging"
+	"iot-golang/internal/metrics"
 	"iot-golang/internal/thm/models"
 	"iot-golang/internal/thm/repositories"
-	"log"
+	"iot-golang/internal/tracing"
+	"log/slog"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type thmService struct {
-	thmRepo repositories.ThmRepository
+	logger             *slog.Logger
+	thmRepo            repositories.ThmRepository
+	anomalyService     anomalyServices.AnomalyService
+	calibrationService calibrationServices.CalibrationService
 }
 
 // Create implements ThmService.
-func (service *thmService) Create(thm models.Thm) helpers.Response {
+func (service *thmService) Create(ctx context.Context, thm models.Thm) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThmService.Create")
+	defer span.End()
+
 	var response helpers.Response
-	if err := service.thmRepo.Create(thm); err != nil {
-		log.Println("ERROR: Gagal membuat data sensor baru" + err.Error())
-		response.Status = 500
-		response.Messages = "Gagal membuat data sensor baru"
-	} else {
-		log.Println("SUCCESS: Berhasil membuat data sensor baru")
-		response.Status = 201
-		response.Messages = "Berhasil membuat data sensor baru"
-	}
-	return response
+	raw, err := service.calibrationService.Calibrator(ctx, "thm").Apply(thm.DeviceToken, time.Now(), &thm)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", thm.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+	if raw != nil {
+		thm.RawValues = raw
+		thm.Quality = helpers.AssessQuality(models.Fields, &thm).Quality
+	}
+
+	inspection := service.anomalyService.Inspect(ctx, "thm", thm.DeviceToken, thm.Quality, helpers.FieldValues(models.Fields, thm))
+	thm.AnomalyScore = inspection.Score
+
+	if err := service.thmRepo.Create(ctx, thm); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "device_token", thm.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspection)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "device_token", thm.DeviceToken)
+	metrics.RecordIngested("thm", thm.DeviceToken, 1)
+	broadcast.Publish("thm", thm.DeviceToken, thm)
+	response.SetMessage(ctx, i18n.SensorCreated)
+	return response, nil
+}
+
+// CreateBatch implements ThmService.
+func (service *thmService) CreateBatch(ctx context.Context, thm []models.Thm) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThmService.CreateBatch")
+	defer span.End()
+
+	var response helpers.Response
+	calibrator := service.calibrationService.Calibrator(ctx, "thm")
+	now := time.Now()
+	tokens := make([]string, len(thm))
+	readings := make([]anomalyModels.Reading, len(thm))
+	for i := range thm {
+		tokens[i] = thm[i].DeviceToken
+		// Data tanpa created_at akan diberi waktu sekarang oleh database
+		at := thm[i].CreatedAt
+		if at.IsZero() {
+			at = now
+		}
+		raw, err := calibrator.Apply(thm[i].DeviceToken, at, &thm[i])
+		if err != nil {
+			service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", thm[i].DeviceToken, logging.Error(err))
+			return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+		}
+		if raw != nil {
+			thm[i].RawValues = raw
+			thm[i].Quality = helpers.AssessQuality(models.Fields, &thm[i]).Quality
+		}
+		readings[i] = anomalyModels.Reading{DeviceToken: thm[i].DeviceToken, Quality: thm[i].Quality, Values: helpers.FieldValues(models.Fields, thm[i]), At: thm[i].CreatedAt}
+	}
+
+	inspections := service.anomalyService.InspectBatch(ctx, "thm", readings)
+	for i := range inspections {
+		thm[i].AnomalyScore = inspections[i].Score
+	}
+
+	if err := service.thmRepo.CreateBatch(ctx, thm, len(thm)); err != nil {
+		service.logger.ErrorContext(ctx, "Gagal membuat data sensor baru", "count", len(thm), "device_tokens", helpers.DistinctTokens(tokens), logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorCreateFailed), err)
+	}
+
+	service.anomalyService.Record(ctx, inspections...)
+	service.logger.InfoContext(ctx, "Berhasil membuat data sensor baru", "count", len(thm), "device_tokens", helpers.DistinctTokens(tokens))
+	for _, reading := range thm {
+		metrics.RecordIngested("thm", reading.DeviceToken, 1)
+		broadcast.Publish("thm", reading.DeviceToken, reading)
+	}
+	response.SetMessage(ctx, i18n.SensorCreated)
+
+	return response, nil
 }
 
 // Delete implements ThmService.
-func (service *thmService) Delete(Id int64) helpers.Response {
+func (service *thmService) Delete(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThmService.Delete")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum dihapus
-	_, findErr := service.thmRepo.GetById(Id)
+	_, findErr := service.thmRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
 	}
 
 	// Data ditemukan, lakukan penghapusan
-	err := service.thmRepo.Delete(Id)
+	err := service.thmRepo.Delete(ctx, Id)
 	if err != nil {
-		log.Printf("ERROR: Gagal menghapus data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal menghapus data sensor dengan id : %d", Id)
-	} else {
-		// Jika penghapusan berhasil
-		log.Println("SUCCESS: Data sensor berhasil dihapus")
-		response.Status = 200
-		response.Messages = "Data sensor berhasil dihapus"
+		service.logger.ErrorContext(ctx, "Gagal menghapus data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorDeleteFailed, Id), err)
 	}
 
-	return response
+	// Jika penghapusan berhasil
+	service.logger.InfoContext(ctx, "Data sensor berhasil dihapus")
+	response.SetMessage(ctx, i18n.SensorDeleted)
+
+	return response, nil
 }
 
 // GetAll implements ThmService.
-func (service *thmService) GetAll() helpers.Response {
+func (service *thmService) GetAll(ctx context.Context, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThmService.GetAll")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.thmRepo.GetAll()
+	data, err := service.thmRepo.GetAll(ctx, Quality)
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil seluruh data sensor : error %v", err)
-		response.Status = 500
-		response.Messages = "Gagal mengambil seluruh data sensor"
-	} else {
-		log.Println("SUCCESS: Berhasil mengambil semua data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengambil semua data sensor"
-		response.Data = data
-	}
-	return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil seluruh data sensor", logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil semua data sensor")
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
+}
+
+// Query implements ThmService.
+// Data yang kosong tetap dikembalikan sebagai daftar kosong.
+func (service *thmService) Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThmService.Query")
+	defer span.End()
+
+	var response helpers.Response
+	data, err := service.thmRepo.Query(ctx, Query)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", Query.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorListFailed), err)
+	}
+	if data == nil {
+		data = []models.Thm{}
+	}
+
+	response.SetMessage(ctx, i18n.SensorListed)
+	response.Data = data
+	return response, nil
 }
 
 // GetById implements ThmService
-func (service *thmService) GetById(Id int64) helpers.Response {
+func (service *thmService) GetById(ctx context.Context, Id int64) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThmService.GetById")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.thmRepo.GetById(Id)
+	data, err := service.thmRepo.GetById(ctx, Id)
 
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d", Id)
-			response.Status = 404
-			response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		} else {
-			log.Printf("ERROR: Gagal mengambil data sensor dengan id : %d, error: %v", Id, err)
-			response.Status = 500
-			response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan id : %d", Id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
 		}
-	} else {
-		// Konversi string ke tipe data float64
-		temperature, _ := strconv.ParseFloat(data.Temperature, 64)
-		kelembabanUdara, _ := strconv.ParseFloat(data.KelembabanUdara, 64)
-		battery, _ := strconv.ParseFloat(data.Battery, 64)
 
-		data.Temperature = fmt.Sprintf("%.2f", temperature)
-		data.KelembabanUdara = fmt.Sprintf("%.2f", kelembabanUdara)
-		data.Battery = fmt.Sprintf("%.2f", battery)
-
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan id : %d", Id)
-		response.Data = data
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), err)
 	}
 
-	return response
+	// Konversi string ke tipe data float64
+	temperature, _ := strconv.ParseFloat(data.Temperature, 64)
+	kelembabanUdara, _ := strconv.ParseFloat(data.KelembabanUdara, 64)
+	battery, _ := strconv.ParseFloat(data.Battery, 64)
+
+	data.Temperature = fmt.Sprintf("%.2f", temperature)
+	data.KelembabanUdara = fmt.Sprintf("%.2f", kelembabanUdara)
+	data.Battery = fmt.Sprintf("%.2f", battery)
+
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "id", Id)
+	response.SetMessage(ctx, i18n.SensorFoundByID, Id)
+	response.Data = data
+
+	return response, nil
 }
 
 // GetByToken implements ThmService.
-func (service *thmService) GetByToken(DeviceToken string) helpers.Response {
+func (service *thmService) GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThmService.GetByToken")
+	defer span.End()
+
 	var response helpers.Response
-	data, err := service.thmRepo.GetByToken(DeviceToken)
+	data, err := service.thmRepo.GetByToken(ctx, DeviceToken, Quality)
 
 	if err != nil {
-		log.Printf("ERROR: Gagal mengambil data sensor dengan token : %s, error: %v", DeviceToken, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengambil data sensor dengan token : %s", DeviceToken)
-		return response
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "device_token", DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByTokenFail, DeviceToken), err)
 	}
 
 	var foundData []models.Thm
@@ -139,57 +248,113 @@ func (service *thmService) GetByToken(DeviceToken string) helpers.Response {
 	}
 
 	if len(foundData) == 0 {
-		log.Printf("INFO: Tidak menemukan data sensor dengan token : %s", DeviceToken)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan token : %s", DeviceToken)
-	} else {
-		log.Printf("SUCCESS: Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Status = 200
-		response.Messages = fmt.Sprintf("Berhasil mengambil data sensor dengan token : %s", DeviceToken)
-		response.Data = foundData
+		service.logger.InfoContext(ctx, "Tidak menemukan data sensor", "device_token", DeviceToken)
+		return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByToken, DeviceToken))
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengambil data sensor", "device_token", DeviceToken)
+	response.SetMessage(ctx, i18n.SensorFoundByToken, DeviceToken)
+	response.Data = foundData
+
+	return response, nil
 }
 
 // Update implements ThmService.
-func (service *thmService) Update(Id int64, thm models.Thm) helpers.Response {
+func (service *thmService) Update(ctx context.Context, Id int64, thm models.Thm) (helpers.Response, error) {
+	ctx, span := tracing.Start(ctx, "ThmService.Update")
+	defer span.End()
+
 	var response helpers.Response
 
 	// Cek apakah data ada sebelum di update
-	_, findErr := service.thmRepo.GetById(Id)
+	current, findErr := service.thmRepo.GetById(ctx, Id)
 	if findErr != nil {
-		log.Printf("ERROR: Tidak menemukan data sensor dengan id : %d, error: %v", Id, findErr)
-		response.Status = 404
-		response.Messages = fmt.Sprintf("Tidak menemukan data sensor dengan id : %d", Id)
-		return response
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			service.logger.WarnContext(ctx, "Tidak menemukan data sensor", "id", Id)
+			return response, helpers.NotFound(i18n.Msg(i18n.SensorNotFoundByID, Id))
+		}
+
+		service.logger.ErrorContext(ctx, "Gagal mengambil data sensor", "id", Id, logging.Error(findErr))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorGetByIDFailed, Id), findErr)
+	}
+
+	// Nilai baru dikalibrasi dengan kalibrasi yang berlaku pada waktu data
+	// tersebut dibuat, sama seperti saat data disimpan pertama kali
+	raw, err := service.calibrationService.Calibrator(ctx, "thm").Apply(current.DeviceToken, current.CreatedAt, &thm)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengambil kalibrasi perangkat", "device_token", current.DeviceToken, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+	thm.RawValues = raw
+	if raw != nil {
+		thm.Quality = helpers.AssessQuality(models.Fields, &thm).Quality
 	}
 
 	// Data ditemukan, lakukan update
-	err := service.thmRepo.Update(Id, thm)
+	err = service.thmRepo.Update(ctx, Id, thm)
+	if err != nil {
+		service.logger.ErrorContext(ctx, "Gagal mengubah data sensor", "id", Id, logging.Error(err))
+		return response, helpers.Internal(i18n.Msg(i18n.SensorUpdateFailed, Id), err)
+	}
+
+	// Jika update data berhasil
+	service.logger.InfoContext(ctx, "Berhasil mengubah data sensor")
+	response.SetMessage(ctx, i18n.SensorUpdated)
+
+	return response, nil
+}
+
+// Export implements ThmService.
+func (service *thmService) Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "ThmService.Export")
+	defer span.End()
+
+	// Writer baru dibuat setelah baris pertama terbaca sehingga kegagalan
+	// query tidak membuat header response terkirim lebih dulu.
+	var writer helpers.ExportWriter
+	open := func() (err error) {
+		if writer != nil {
+			return nil
+		}
+		if writer, err = helpers.NewExportWriter(Format, w, "thm"); err != nil {
+			return err
+		}
+		return writer.WriteRow(helpers.ExportHeaders(Units.Fields()))
+	}
+
+	count := 0
+	err := service.thmRepo.Export(ctx, DeviceToken, Start, End, Quality, func(data models.Thm) error {
+		if err := open(); err != nil {
+			return err
+		}
+		count++
+		Units.Apply(&data)
+		return writer.WriteRow([]string{strconv.FormatInt(data.Id, 10), data.DeviceToken, data.CreatedAt.Format(time.RFC3339), data.Temperature, data.KelembabanUdara, data.Battery, data.Quality})
+	})
 	if err != nil {
-		log.Printf("ERROR: Gagal mengubah data sensor dengan id : %d, error: %v", Id, err)
-		response.Status = 500
-		response.Messages = fmt.Sprintf("Gagal mengubah data sensor dengan id : %d", Id)
-	} else {
-		// Jika update data berhasil
-		log.Println("SUCCESS: Berhasil mengubah data sensor")
-		response.Status = 200
-		response.Messages = "Berhasil mengubah data sensor"
+		service.logger.ErrorContext(ctx, "Gagal mengekspor data sensor", "device_token", DeviceToken, logging.Error(err))
+		return err
+	}
+	if err := open(); err != nil {
+		return err
 	}
 
-	return response
+	service.logger.InfoContext(ctx, "Berhasil mengekspor data sensor", "count", count, "device_token", DeviceToken)
+	return writer.Close()
 }
 
 type ThmService interface {
-	Create(thm models.Thm) helpers.Response
-	Update(Id int64, thm models.Thm) helpers.Response
-	Delete(Id int64) helpers.Response
-	GetById(Id int64) helpers.Response
-	GetByToken(DeviceToken string) helpers.Response
-	GetAll() helpers.Response
+	Create(ctx context.Context, thm models.Thm) (helpers.Response, error)
+	CreateBatch(ctx context.Context, thm []models.Thm) (helpers.Response, error)
+	Update(ctx context.Context, Id int64, thm models.Thm) (helpers.Response, error)
+	Delete(ctx context.Context, Id int64) (helpers.Response, error)
+	GetById(ctx context.Context, Id int64) (helpers.Response, error)
+	GetByToken(ctx context.Context, DeviceToken string, Quality []string) (helpers.Response, error)
+	GetAll(ctx context.Context, Quality []string) (helpers.Response, error)
+	Query(ctx context.Context, Query helpers.ReadingQuery) (helpers.Response, error)
+	Export(ctx context.Context, DeviceToken string, Start time.Time, End time.Time, Quality []string, Units helpers.Units, Format string, w io.Writer) error
 }
 
-func NewThmService(db *gorm.DB) ThmService {
-	return &thmService{thmRepo: repositories.NewThmRepository(db)}
+func NewThmService(db *gorm.DB, logger *slog.Logger) ThmService {
+	return &thmService{logger: logger.With("sensor", "thm"), thmRepo: repositories.NewThmRepository(db, logger), anomalyService: anomalyServices.NewAnomalyService(db, logger), calibrationService: calibrationServices.NewCalibrationService(db, logger)}
 }
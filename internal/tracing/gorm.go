@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// queryPlugin membuat span untuk setiap query GORM sebagai turunan span
+// request yang dibawa oleh context repository.
+type queryPlugin struct{}
+
+// NewPlugin membuat plugin GORM untuk tracing query.
+func NewPlugin() gorm.Plugin {
+	return queryPlugin{}
+}
+
+func (queryPlugin) Name() string {
+	return "tracing"
+}
+
+func (plugin queryPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registers := []error{
+		callback.Create().Before("gorm:create").Register("tracing:before_create", plugin.before("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", plugin.after),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", plugin.before("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", plugin.after),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", plugin.before("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", plugin.after),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", plugin.before("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", plugin.after),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", plugin.before("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", plugin.after),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", plugin.before("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", plugin.after),
+	}
+
+	for _, err := range registers {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (queryPlugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := Tracer().Start(db.Statement.Context, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient))
+		span.SetAttributes(attribute.String("db.system", db.Dialector.Name()))
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (queryPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}